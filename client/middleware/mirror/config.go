@@ -0,0 +1,60 @@
+package mirror
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultPercent is the default percentage of requests to mirror.
+	DefaultPercent = 0.0
+
+	// DefaultTimeout is the default timeout of a shadow request.
+	DefaultTimeout = 5 * time.Second
+
+	// DefaultCompare enables comparing the shadow responses by default.
+	DefaultCompare = true
+
+	// DefaultMaxInFlight is the default maximum of concurrent shadow requests,
+	// requests over it don't get mirrored.
+	DefaultMaxInFlight = 100
+)
+
+// Config is the mirror middleware config.
+type Config struct {
+	// Percent of the unary requests to mirror, from 0 to 100.
+	Percent float64 `json:"percent" yaml:"percent"`
+
+	// Endpoints to mirror, it may contain path.Match patterns.
+	// Empty mirrors all endpoints.
+	Endpoints []string `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+
+	// Service is the shadow service, defaults to the service of the request.
+	// Service, Version or both must be set to mirror.
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+
+	// Version is a semver constraint for the shadow service, e.g. "~1.5".
+	// It gets sent with the "x-orb-version" metadata.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+
+	// Timeout of a shadow request.
+	Timeout config.Duration `json:"timeout" yaml:"timeout"`
+
+	// Compare the shadow response with the primary response.
+	Compare bool `json:"compare" yaml:"compare"`
+
+	// MaxInFlight is the maximum of concurrent shadow requests.
+	MaxInFlight int `json:"maxInFlight" yaml:"maxInFlight"`
+}
+
+// NewConfig returns a new config object.
+func NewConfig() Config {
+	return Config{
+		Percent:     DefaultPercent,
+		Timeout:     config.Duration(DefaultTimeout),
+		Compare:     DefaultCompare,
+		MaxInFlight: DefaultMaxInFlight,
+	}
+}
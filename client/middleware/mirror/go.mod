@@ -0,0 +1,15 @@
+module github.com/go-orb/plugins/client/middleware/mirror
+
+go 1.23.6
+
+require (
+	github.com/go-orb/go-orb v0.4.1
+	github.com/go-orb/plugins/client/orb v0.3.0
+	github.com/stretchr/testify v1.10.0
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	dario.cat/mergo v1.0.1 // indirect
+	github.com/cornelk/hashmap v1.0.8 // indirect
+)
@@ -0,0 +1,337 @@
+// Package mirror provides a traffic mirroring middleware for client.
+//
+// It sends a copy of a percentage of the unary requests to a shadow service
+// or version, discards the shadow response and records errors and diffs.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"math/rand/v2"
+	"path"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+
+	"github.com/go-orb/plugins/client/orb"
+)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Name is the middlewares name.
+const Name = "mirror"
+
+// MetadataMirror is set on shadow requests, so the shadow service can tell them apart.
+const MetadataMirror = "x-orb-mirror"
+
+var _ client.Middleware = (*Middleware)(nil)
+
+// ErrNoShadowTarget is returned when mirroring is enabled without a shadow service or version,
+// the shadow requests would hit the production service again.
+var ErrNoShadowTarget = errors.New("mirror: a shadow service or version is required")
+
+// shadowKey marks the context of a shadow request, so it doesn't get mirrored again.
+type shadowKey struct{}
+
+// Stats contains the counters of the mirror middleware.
+type Stats struct {
+	// Mirrored is the number of shadow requests sent.
+	Mirrored uint64
+	// Dropped is the number of requests not mirrored because of MaxInFlight.
+	Dropped uint64
+	// Errors is the number of shadow requests which failed while the primary succeeded.
+	Errors uint64
+	// Diffs is the number of shadow responses which differ from the primary response.
+	Diffs uint64
+}
+
+// Middleware is the mirror Middleware for client.
+type Middleware struct {
+	config Config
+	logger log.Logger
+	client client.Type
+
+	wg       sync.WaitGroup
+	inFlight atomic.Int64
+
+	mirrored atomic.Uint64
+	dropped  atomic.Uint64
+	errors   atomic.Uint64
+	diffs    atomic.Uint64
+}
+
+// Start the component. E.g. connect to the broker.
+func (m *Middleware) Start(_ context.Context) error { return nil }
+
+// Stop waits for the shadow requests in flight.
+// The context will contain a timeout, and cancelation should be respected.
+func (m *Middleware) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Type returns the component type, e.g. broker.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Stats returns the counters of this middleware.
+func (m *Middleware) Stats() Stats {
+	return Stats{
+		Mirrored: m.mirrored.Load(),
+		Dropped:  m.dropped.Load(),
+		Errors:   m.errors.Load(),
+		Diffs:    m.diffs.Load(),
+	}
+}
+
+// Request wraps the original Request method or other middlewares,
+// without a percentage to mirror it returns next as it is.
+func (m *Middleware) Request(
+	next client.MiddlewareRequestHandler,
+) client.MiddlewareRequestHandler {
+	if m.config.Percent <= 0 {
+		return next
+	}
+
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		if !m.sample(ctx, endpoint) {
+			return next(ctx, service, endpoint, req, result, opts)
+		}
+
+		if m.inFlight.Add(1) > int64(m.config.MaxInFlight) {
+			m.inFlight.Add(-1)
+			m.dropped.Add(1)
+
+			return next(ctx, service, endpoint, req, result, opts)
+		}
+
+		// Copy the request before the caller gets the chance to reuse it.
+		shadowReq := clone(req)
+
+		err := next(ctx, service, endpoint, req, result, opts)
+
+		var primary any
+		if m.config.Compare && err == nil {
+			primary = snapshot(result)
+		}
+
+		m.wg.Add(1)
+
+		go func() {
+			defer m.wg.Done()
+			defer m.inFlight.Add(-1)
+
+			m.shadow(ctx, service, endpoint, shadowReq, result, primary, err, opts)
+		}()
+
+		return err
+	}
+}
+
+// sample decides whether to mirror a request.
+func (m *Middleware) sample(ctx context.Context, endpoint string) bool {
+	if ctx.Value(shadowKey{}) != nil {
+		return false
+	}
+
+	if len(m.config.Endpoints) > 0 {
+		matched := false
+
+		for _, pattern := range m.config.Endpoints {
+			if ok, err := path.Match(pattern, endpoint); err == nil && ok {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return rand.Float64()*100 < m.config.Percent //nolint:gosec
+}
+
+// shadow sends the shadow request and records the outcome.
+func (m *Middleware) shadow(
+	ctx context.Context,
+	service, endpoint string,
+	req, result, primary any,
+	primaryErr error,
+	opts *client.CallOptions,
+) {
+	shadowService := m.config.Service
+	if shadowService == "" {
+		shadowService = service
+	}
+
+	md := maps.Clone(opts.Metadata)
+	if md == nil {
+		md = make(map[string]string)
+	}
+
+	md[MetadataMirror] = "1"
+
+	if m.config.Version != "" {
+		md[orb.MetadataVersion] = shadowService + "=" + m.config.Version
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.WithValue(context.WithoutCancel(ctx), shadowKey{}, true),
+		time.Duration(m.config.Timeout),
+	)
+	defer cancel()
+
+	shadowResult := newResult(result)
+
+	m.mirrored.Add(1)
+
+	start := time.Now()
+	err := m.client.Request(
+		ctx,
+		shadowService,
+		endpoint,
+		req,
+		shadowResult,
+		client.WithContentType(opts.ContentType),
+		client.WithMetadata(md),
+		client.WithRequestTimeout(time.Duration(m.config.Timeout)),
+	)
+
+	switch {
+	case err != nil && primaryErr == nil:
+		m.errors.Add(1)
+		m.logger.Warn(
+			"shadow request failed",
+			"service", shadowService,
+			"endpoint", endpoint,
+			"duration", time.Since(start),
+			"error", err,
+		)
+	case err == nil && primaryErr != nil:
+		m.logger.Debug(
+			"shadow request succeeded where the primary failed",
+			"service", shadowService,
+			"endpoint", endpoint,
+			"primaryError", primaryErr,
+		)
+	case err == nil && primary != nil && !equal(primary, shadowResult):
+		m.diffs.Add(1)
+		m.logger.Warn(
+			"shadow response differs",
+			"service", shadowService,
+			"endpoint", endpoint,
+			"duration", time.Since(start),
+		)
+	}
+}
+
+// clone copies proto messages, other values are passed as they are.
+func clone(v any) any {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Clone(msg)
+	}
+
+	return v
+}
+
+// snapshot returns a copy of the result to compare with.
+func snapshot(v any) any {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Clone(msg)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+// equal compares a snapshot with a shadow result.
+func equal(snap any, v any) bool {
+	if msg, ok := snap.(proto.Message); ok {
+		other, ok := v.(proto.Message)
+		return ok && proto.Equal(msg, other)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+
+	snapBytes, ok := snap.([]byte)
+
+	return ok && bytes.Equal(snapBytes, b)
+}
+
+// newResult creates a new value of the same type as the result to decode the shadow response into.
+func newResult(result any) any {
+	t := reflect.TypeOf(result)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return new(any)
+	}
+
+	return reflect.New(t.Elem()).Interface()
+}
+
+// New creates a new mirror middleware, it rejects configs which mirror without a shadow target.
+func New(cfg Config, cli client.Type, logger log.Logger) (*Middleware, error) {
+	if cfg.Percent > 0 && cfg.Service == "" && cfg.Version == "" {
+		return nil, ErrNoShadowTarget
+	}
+
+	return &Middleware{
+		config: cfg,
+		logger: logger,
+		client: cli,
+	}, nil
+}
+
+// Provide will be registered to client.Middlewares, it's a factory for this.
+func Provide(configSection map[string]any, cli client.Type, logger log.Logger) (client.Middleware, error) {
+	// Configure the logger.
+	logger, err := logger.WithConfig([]string{}, configSection)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := NewConfig()
+
+	err = config.Parse(nil, "", configSection, &cfg)
+	if err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg, cli, logger.With("middleware", Name))
+}
@@ -0,0 +1,227 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/go-orb/plugins/client/orb"
+)
+
+// shadowCall is a request the fake client received.
+type shadowCall struct {
+	ctx     context.Context //nolint:containedctx
+	service string
+	opts    *client.CallOptions
+}
+
+// fakeClient receives the shadow requests.
+type fakeClient struct {
+	client.Client
+
+	calls chan shadowCall
+	err   error
+	block bool
+}
+
+func (c *fakeClient) Request(ctx context.Context, service string, _ string, _ any, result any, opts ...client.CallOption) error {
+	callOpts := &client.CallOptions{}
+	for _, o := range opts {
+		o(callOpts)
+	}
+
+	c.calls <- shadowCall{ctx: ctx, service: service, opts: callOpts}
+
+	if c.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if c.err != nil {
+		return c.err
+	}
+
+	if msg, ok := result.(*wrapperspb.StringValue); ok {
+		msg.Value = "shadow"
+	}
+
+	return nil
+}
+
+func newMiddleware(cfg Config, cli *fakeClient) *Middleware {
+	return &Middleware{
+		config: cfg,
+		logger: log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+		client: client.Type{Client: cli},
+	}
+}
+
+func primary(_ context.Context, _ string, _ string, _ any, result any, _ *client.CallOptions) error {
+	if msg, ok := result.(*wrapperspb.StringValue); ok {
+		msg.Value = "primary"
+	}
+
+	return nil
+}
+
+func call(t *testing.T, h client.MiddlewareRequestHandler, ctx context.Context) error {
+	t.Helper()
+
+	return h(ctx, "svc", "/svc/Call", wrapperspb.String("req"), &wrapperspb.StringValue{}, &client.CallOptions{})
+}
+
+func TestMirrorSampling(t *testing.T) {
+	tests := []struct {
+		name     string
+		percent  float64
+		min, max uint64
+	}{
+		{name: "disabled", percent: 0, min: 0, max: 0},
+		{name: "all", percent: 100, min: 1000, max: 1000},
+		{name: "half", percent: 50, min: 400, max: 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.Percent = tt.percent
+			cfg.MaxInFlight = 1000
+
+			cli := &fakeClient{calls: make(chan shadowCall, 1000)}
+			m := newMiddleware(cfg, cli)
+			h := m.Request(primary)
+
+			for range 1000 {
+				require.NoError(t, call(t, h, context.Background()))
+			}
+
+			require.NoError(t, m.Stop(context.Background()))
+
+			mirrored := m.Stats().Mirrored
+			require.GreaterOrEqual(t, mirrored, tt.min)
+			require.LessOrEqual(t, mirrored, tt.max)
+		})
+	}
+}
+
+func TestMirrorEndpointsAndMetadata(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Percent = 100
+	cfg.Endpoints = []string{"/svc/*"}
+	cfg.Service = "svc-v2"
+	cfg.Version = "~1.5"
+
+	cli := &fakeClient{calls: make(chan shadowCall, 2)}
+	m := newMiddleware(cfg, cli)
+	h := m.Request(primary)
+
+	require.NoError(t, h(context.Background(), "other", "/other/Call", nil, &wrapperspb.StringValue{}, &client.CallOptions{}))
+	require.NoError(t, call(t, h, context.Background()))
+	require.NoError(t, m.Stop(context.Background()))
+
+	require.Len(t, cli.calls, 1)
+
+	shadow := <-cli.calls
+	require.Equal(t, "svc-v2", shadow.service)
+	require.Equal(t, "1", shadow.opts.Metadata[MetadataMirror])
+	require.Equal(t, "svc-v2=~1.5", shadow.opts.Metadata[orb.MetadataVersion])
+}
+
+func TestMirrorIsolation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Percent = 100
+
+	cli := &fakeClient{calls: make(chan shadowCall, 1), err: errors.New("shadow failed")}
+	m := newMiddleware(cfg, cli)
+
+	result := &wrapperspb.StringValue{}
+	err := m.Request(primary)(context.Background(), "svc", "/svc/Call", wrapperspb.String("req"), result, &client.CallOptions{})
+
+	// The caller only sees the primary response.
+	require.NoError(t, err)
+	require.Equal(t, "primary", result.GetValue())
+
+	require.NoError(t, m.Stop(context.Background()))
+	require.Equal(t, Stats{Mirrored: 1, Errors: 1}, m.Stats())
+}
+
+func TestMirrorDiffs(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Percent = 100
+
+	cli := &fakeClient{calls: make(chan shadowCall, 1)}
+	m := newMiddleware(cfg, cli)
+
+	require.NoError(t, call(t, m.Request(primary), context.Background()))
+	require.NoError(t, m.Stop(context.Background()))
+	require.Equal(t, Stats{Mirrored: 1, Diffs: 1}, m.Stats())
+}
+
+func TestMirrorParentDone(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Percent = 100
+	cfg.Timeout = config.Duration(50 * time.Millisecond)
+
+	cli := &fakeClient{calls: make(chan shadowCall, 1), block: true}
+	m := newMiddleware(cfg, cli)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, call(t, m.Request(primary), ctx))
+	cancel()
+
+	shadow := <-cli.calls
+
+	// The shadow request isn't canceled with the caller's context, it ends with its timeout.
+	select {
+	case <-shadow.ctx.Done():
+		t.Fatal("the shadow request got canceled with the parent context")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	require.NoError(t, m.Stop(context.Background()))
+	require.ErrorIs(t, shadow.ctx.Err(), context.DeadlineExceeded)
+	require.Equal(t, Stats{Mirrored: 1, Errors: 1}, m.Stats())
+}
+
+func TestMirrorMaxInFlight(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Percent = 100
+	cfg.MaxInFlight = 1
+	cfg.Timeout = config.Duration(50 * time.Millisecond)
+
+	cli := &fakeClient{calls: make(chan shadowCall, 2), block: true}
+	m := newMiddleware(cfg, cli)
+	h := m.Request(primary)
+
+	require.NoError(t, call(t, h, context.Background()))
+	<-cli.calls
+	require.NoError(t, call(t, h, context.Background()))
+
+	require.NoError(t, m.Stop(context.Background()))
+	require.Equal(t, uint64(1), m.Stats().Dropped)
+}
+
+func TestNewShadowTarget(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Percent = 10
+
+	_, err := New(cfg, client.Type{}, log.Logger{})
+	require.ErrorIs(t, err, ErrNoShadowTarget)
+
+	cfg.Version = "~1.5"
+	_, err = New(cfg, client.Type{}, log.Logger{})
+	require.NoError(t, err)
+
+	// Disabled mirroring needs no target.
+	_, err = New(NewConfig(), client.Type{}, log.Logger{})
+	require.NoError(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -31,7 +32,13 @@ type Client struct {
 	logger   log.Logger
 	registry registry.Registry
 
-	middlewares []client.Middleware
+	middlewares    []client.Middleware
+	requestHandler client.MiddlewareRequestHandler
+
+	health *nodeHealth
+
+	// cancelWatch stops the warm-up registry watchers.
+	cancelWatch context.CancelFunc
 
 	transportLock sync.Mutex
 	transports    *container.Map[string, Transport]
@@ -42,15 +49,50 @@ func (c *Client) Logger() log.Logger {
 	return c.logger
 }
 
-// Start starts the client.
-func (c *Client) Start(_ context.Context) error {
+// Start starts the client and its middlewares and pre-warms connections.
+func (c *Client) Start(ctx context.Context) error {
+	for _, m := range c.middlewares {
+		if err := m.Start(ctx); err != nil {
+			return fmt.Errorf("while starting the client middleware '%s': %w", m.String(), err)
+		}
+	}
+
+	if len(c.config.Warmup.Services) == 0 {
+		return nil
+	}
+
+	c.warmupServices(ctx)
+
+	if c.config.Warmup.Watch && c.cancelWatch == nil {
+		var watchCtx context.Context
+
+		watchCtx, c.cancelWatch = context.WithCancel(context.Background())
+
+		for _, service := range c.config.Warmup.Services {
+			go c.watchWarmup(watchCtx, service)
+		}
+	}
+
 	return nil
 }
 
-// Stop stops the client.
+// Stop stops the middlewares and the transports of the client.
 func (c *Client) Stop(ctx context.Context) error {
 	hasError := false
 
+	if c.cancelWatch != nil {
+		c.cancelWatch()
+		c.cancelWatch = nil
+	}
+
+	for _, m := range c.middlewares {
+		if err := m.Stop(ctx); err != nil {
+			c.logger.Error("failed to stop a middleware", "middleware", m.String(), "error", err)
+
+			hasError = true
+		}
+	}
+
 	c.transports.Range(func(_ string, t Transport) bool {
 		if err := t.Stop(ctx); err != nil {
 			c.logger.Error("failed to stop a transport", "error", err)
@@ -95,7 +137,7 @@ func (c *Client) With(opts ...client.Option) error {
 
 // SelectService selects a service node.
 func (c *Client) SelectService(ctx context.Context, service string, opts ...client.CallOption) (string, string, error) {
-	options := c.makeOptions(opts...)
+	options := c.makeOptions(service, "", opts...)
 
 	return c.selectNode(ctx, service, options)
 }
@@ -118,6 +160,13 @@ func (c *Client) selectNode(ctx context.Context, service string, opts *client.Ca
 		return "", "", err
 	}
 
+	// Apply version pins, canary splits and the version override.
+	nodes, err = c.filterVersions(ctx, service, nodes, opts)
+	if err != nil {
+		c.Logger().Error("Failed to resolve service", "error", err, "service", service)
+		return "", "", err
+	}
+
 	// Run the configured Selector to get a node from the resolved nodes.
 	node, err := opts.Selector(ctx, service, nodes)
 	if err != nil {
@@ -128,6 +177,37 @@ func (c *Client) selectNode(ctx context.Context, service string, opts *client.Ca
 	return node.Address, node.Scheme, nil
 }
 
+// localityRegion returns the region the locality selector prefers.
+func (c *Client) localityRegion() string {
+	if c.config.Locality.Region != "" {
+		return c.config.Locality.Region
+	}
+
+	return c.config.Config.Region
+}
+
+// memoryNodeInfo is implemented by memory servers which advertise their locality.
+type memoryNodeInfo interface {
+	Region() string
+	Metadata() map[string]string
+}
+
+// memoryNode returns the node of an in-process memory server.
+func memoryNode(service string, srv client.MemoryServer) registry.ServiceNode {
+	node := registry.ServiceNode{
+		Name:    service,
+		Scheme:  "memory",
+		Address: "",
+	}
+
+	if info, ok := srv.(memoryNodeInfo); ok {
+		node.Region = info.Region()
+		node.Metadata = info.Metadata()
+	}
+
+	return node
+}
+
 // resolveService resolves a servicename to a Node with the help of the registry.
 func (c *Client) resolveService(
 	ctx context.Context,
@@ -138,6 +218,12 @@ func (c *Client) resolveService(
 		return nil, client.ErrServiceArgumentEmpty
 	}
 
+	// With locality enabled, other regions are candidates as well.
+	region := opts.Region
+	if c.config.Locality.Enabled {
+		region = ""
+	}
+
 	// Try to resolve the service with retries
 	var (
 		services []registry.ServiceNode
@@ -150,21 +236,14 @@ func (c *Client) resolveService(
 			return nil, ctx.Err()
 		}
 
-		if _, err := client.ResolveMemoryServer(service); err == nil {
-			rNodes := make([]registry.ServiceNode, 0)
-			rNodes = append(rNodes, registry.ServiceNode{
-				Name:    service,
-				Scheme:  "memory",
-				Address: "",
-			})
-
-			return rNodes, nil
+		if srv, err := client.ResolveMemoryServer(service); err == nil {
+			return []registry.ServiceNode{memoryNode(service, srv)}, nil
 		}
 
 		if opts.AnyTransport {
-			services, err = c.registry.GetService(ctx, opts.Namespace, opts.Region, service, nil)
+			services, err = c.registry.GetService(ctx, opts.Namespace, region, service, nil)
 		} else {
-			services, err = c.registry.GetService(ctx, opts.Namespace, opts.Region, service, opts.PreferredTransports)
+			services, err = c.registry.GetService(ctx, opts.Namespace, region, service, opts.PreferredTransports)
 		}
 
 		if err == nil && len(services) > 0 {
@@ -174,7 +253,7 @@ func (c *Client) resolveService(
 		c.logger.Debug(
 			"service resolution failed, retrying",
 			"namespace", opts.Namespace,
-			"region", opts.Region,
+			"region", region,
 			"service", service,
 			"attempt", retries+1,
 			"error", err,
@@ -202,7 +281,7 @@ func (c *Client) resolveService(
 	return services, nil
 }
 
-func (c *Client) makeOptions(opts ...client.CallOption) *client.CallOptions {
+func (c *Client) makeOptions(service, endpoint string, opts ...client.CallOption) *client.CallOptions {
 	// Construct CallOptions, use the client's config as base.
 	callOpts := &client.CallOptions{
 		ContentType:         c.config.Config.ContentType,
@@ -228,11 +307,23 @@ func (c *Client) makeOptions(opts ...client.CallOption) *client.CallOptions {
 		Region:    c.config.Config.Region,
 	}
 
+	// Apply per endpoint overrides from the config.
+	for i := range c.config.Endpoints {
+		if c.config.Endpoints[i].matches(service, endpoint) {
+			c.config.Endpoints[i].apply(callOpts)
+		}
+	}
+
 	// Apply options.
 	for _, o := range opts {
 		o(callOpts)
 	}
 
+	// Locality filters the nodes for the effective selector, including custom ones.
+	if c.config.Locality.Enabled {
+		callOpts.Selector = localitySelector(c.config.Locality, c.localityRegion(), c.health, callOpts.Selector)
+	}
+
 	return callOpts
 }
 
@@ -288,18 +379,13 @@ func (c *Client) Request(
 	result any,
 	opts ...client.CallOption,
 ) error {
-	options := c.makeOptions(opts...)
+	options := c.makeOptions(service, endpoint, opts...)
 
 	address, transport, err := c.selectNode(ctx, service, options)
 	if err != nil {
 		return err
 	}
 
-	t, err := c.transport(transport)
-	if err != nil {
-		return err
-	}
-
 	infos := client.RequestInfos{
 		Service:   service,
 		Endpoint:  endpoint,
@@ -310,7 +396,9 @@ func (c *Client) Request(
 	// Add request infos to context
 	ctx = context.WithValue(ctx, client.RequestInfosKey{}, &infos)
 
-	err = t.Request(ctx, infos, req, result, options)
+	err = c.requestHandler(ctx, service, endpoint, req, result, options)
+	c.reportNode(address, err)
+
 	if err != nil {
 		return err
 	}
@@ -318,6 +406,40 @@ func (c *Client) Request(
 	return nil
 }
 
+// request hands the request to the transport from the request infos,
+// it's the innermost handler of the middleware chain.
+func (c *Client) request(
+	ctx context.Context,
+	_ string,
+	_ string,
+	req any,
+	result any,
+	opts *client.CallOptions,
+) error {
+	infos, ok := ctx.Value(client.RequestInfosKey{}).(*client.RequestInfos)
+	if !ok {
+		return orberrors.ErrInternalServerError.WrapNew("no request infos in the context")
+	}
+
+	t, err := c.transport(infos.Transport)
+	if err != nil {
+		return err
+	}
+
+	return t.Request(ctx, *infos, req, result, opts)
+}
+
+// setMiddlewares sets the middlewares and chains them around the transport request,
+// the first middleware is the outermost one.
+func (c *Client) setMiddlewares(middlewares []client.Middleware) {
+	c.middlewares = middlewares
+
+	c.requestHandler = c.request
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		c.requestHandler = middlewares[i].Request(c.requestHandler)
+	}
+}
+
 // Stream opens a bidirectional stream to the service endpoint.
 func (c *Client) Stream(
 	ctx context.Context,
@@ -325,7 +447,7 @@ func (c *Client) Stream(
 	endpoint string,
 	opts ...client.CallOption,
 ) (client.StreamIface[any, any], error) {
-	options := c.makeOptions(opts...)
+	options := c.makeOptions(service, endpoint, opts...)
 
 	address, transport, err := c.selectNode(ctx, service, options)
 	if err != nil {
@@ -348,6 +470,8 @@ func (c *Client) Stream(
 	ctx = context.WithValue(ctx, client.RequestInfosKey{}, &infos)
 
 	stream, err := t.Stream(ctx, infos, options)
+	c.reportNode(address, err)
+
 	if err != nil {
 		// Don't cancel here - the context is owned by the caller
 		c.logger.Error("stream failed", "error", err, "address", address, "transport", transport)
@@ -362,13 +486,7 @@ func (c *Client) Stream(
 // To create a new client use ProvideClientOrb.
 func New(cfg Config, log log.Logger, registry registry.Type) *Client {
 	// Filter out unknown preferred transports from config.
-	nPTransports := []string{}
-
-	for _, pt := range cfg.Config.PreferredTransports {
-		if _, ok := Transports.Get(pt); ok {
-			nPTransports = append(nPTransports, pt)
-		}
-	}
+	nPTransports := registeredTransports(cfg.Config.PreferredTransports)
 
 	// To keep the client working when no transports match,
 	// we use all transports in any order as preferred ones.
@@ -381,12 +499,37 @@ func New(cfg Config, log log.Logger, registry registry.Type) *Client {
 
 	cfg.Config.PreferredTransports = nPTransports
 
-	return &Client{
+	// Same for the endpoint overrides, an override without known transports keeps the client's.
+	cfg.Endpoints = slices.Clone(cfg.Endpoints)
+	for i := range cfg.Endpoints {
+		if len(cfg.Endpoints[i].PreferredTransports) > 0 {
+			cfg.Endpoints[i].PreferredTransports = registeredTransports(cfg.Endpoints[i].PreferredTransports)
+		}
+	}
+
+	c := &Client{
 		config:     cfg,
 		logger:     log,
 		registry:   registry,
+		health:     newNodeHealth(),
 		transports: container.NewMap[string, Transport](),
 	}
+	c.setMiddlewares(nil)
+
+	return c
+}
+
+// registeredTransports returns the transports which are registered.
+func registeredTransports(transports []string) []string {
+	result := []string{}
+
+	for _, pt := range transports {
+		if _, ok := Transports.Get(pt); ok {
+			result = append(result, pt)
+		}
+	}
+
+	return result
 }
 
 // Provide is the wire provider for client.
@@ -462,7 +605,7 @@ func Provide(
 
 		// Apply them to the client.
 		if len(middlewares) > 0 {
-			newClient.middlewares = middlewares
+			newClient.setMiddlewares(middlewares)
 		}
 	}
 
@@ -1,12 +1,23 @@
 package orb
 
 import (
+	"slices"
+
 	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
 )
 
 // Name contains the plugins name.
 const Name = "orb"
 
+// DefaultPreferredTransports are go-orb's preferred transports plus the h2c
+// transports of this client.
+var DefaultPreferredTransports = append( //nolint:gochecknoglobals
+	slices.Clone(client.DefaultPreferredTransports),
+	"unix+h2c",
+	"h2c",
+)
+
 func init() {
 	client.Register(Name, Provide)
 }
@@ -14,6 +25,18 @@ func init() {
 // Config is the config for the orb client.
 type Config struct {
 	client.Config `yaml:",inline"`
+
+	// Locality configures locality-aware routing.
+	Locality LocalityConfig `json:"locality" yaml:"locality"`
+
+	// Versions pins versions of services and splits traffic between them.
+	Versions []VersionRule `json:"versions,omitempty" yaml:"versions,omitempty"`
+
+	// Endpoints overrides call options per service endpoint.
+	Endpoints []EndpointConfig `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+
+	// Warmup configures connection pre-warming.
+	Warmup WarmupConfig `json:"warmup" yaml:"warmup"`
 }
 
 // NewConfig creates a new config object.
@@ -22,7 +45,17 @@ func NewConfig(
 ) Config {
 	cfg := Config{
 		Config: client.NewConfig(),
+		Locality: LocalityConfig{
+			MinHealthyNodes: DefaultLocalityMinHealthyNodes,
+			Cooldown:        config.Duration(DefaultLocalityCooldown),
+		},
+		Warmup: WarmupConfig{
+			Connections: DefaultWarmupConnections,
+			Timeout:     config.Duration(DefaultWarmupTimeout),
+			Watch:       true,
+		},
 	}
+	cfg.Config.PreferredTransports = slices.Clone(DefaultPreferredTransports)
 
 	// Apply options.
 	for _, o := range opts {
@@ -0,0 +1,23 @@
+package orb
+
+import (
+	"testing"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfigPreferredTransports(t *testing.T) {
+	cfg := NewConfig()
+	require.Equal(t, DefaultPreferredTransports, cfg.PreferredTransports)
+	require.Equal(t, client.DefaultPreferredTransports, cfg.PreferredTransports[:len(client.DefaultPreferredTransports)])
+	require.Contains(t, cfg.PreferredTransports, "h2c")
+	require.Contains(t, cfg.PreferredTransports, "unix+h2c")
+
+	// The defaults aren't shared with the config.
+	cfg.PreferredTransports[0] = "grpc"
+	require.Equal(t, "memory", DefaultPreferredTransports[0])
+
+	cfg = NewConfig(client.WithClientPreferredTransports("grpc"))
+	require.Equal(t, []string{"grpc"}, cfg.PreferredTransports)
+}
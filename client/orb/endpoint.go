@@ -0,0 +1,101 @@
+package orb
+
+import (
+	"maps"
+	"path"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+)
+
+// EndpointConfig overrides call options for all calls matching Service and Endpoint.
+//
+// Zero values don't override anything, overrides get applied in config
+// order before the call options of the caller.
+type EndpointConfig struct {
+	// Service is the service name, it may contain path.Match patterns. Empty matches all.
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+
+	// Endpoint is the endpoint, e.g. "/echo.Echo/Call", it may contain path.Match patterns.
+	// Empty matches all.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+
+	// RequestTimeout overrides the request timeout.
+	RequestTimeout config.Duration `json:"requestTimeout,omitempty" yaml:"requestTimeout,omitempty"`
+
+	// StreamTimeout overrides the stream timeout.
+	StreamTimeout config.Duration `json:"streamTimeout,omitempty" yaml:"streamTimeout,omitempty"`
+
+	// Retries overrides the number of retries, a negative value disables retries.
+	Retries int `json:"retries,omitempty" yaml:"retries,omitempty"`
+
+	// ContentType overrides the Content-Type.
+	ContentType string `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+
+	// PreferredTransports overrides the preferred transports.
+	PreferredTransports []string `json:"preferredTransports,omitempty" yaml:"preferredTransports,omitempty"`
+
+	// Metadata gets added to the metadata of the call.
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// WithEndpointConfig adds call option overrides for matching service endpoints.
+func WithEndpointConfig(endpoints ...EndpointConfig) client.Option {
+	return func(c client.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Endpoints = append(cfg.Endpoints, endpoints...)
+		}
+	}
+}
+
+// matchPattern reports whether name matches the path.Match pattern, an empty pattern matches all.
+func matchPattern(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	ok, err := path.Match(pattern, name)
+
+	return err == nil && ok
+}
+
+// matches reports whether the overrides apply to the service endpoint.
+// Without an endpoint, e.g. on SelectService, only overrides without an endpoint match.
+func (e *EndpointConfig) matches(service, endpoint string) bool {
+	if !matchPattern(e.Service, service) {
+		return false
+	}
+
+	if endpoint == "" {
+		return e.Endpoint == ""
+	}
+
+	return matchPattern(e.Endpoint, endpoint)
+}
+
+// apply applies the overrides to the call options.
+func (e *EndpointConfig) apply(opts *client.CallOptions) {
+	if e.RequestTimeout > 0 {
+		opts.RequestTimeout = time.Duration(e.RequestTimeout)
+	}
+
+	if e.StreamTimeout > 0 {
+		opts.StreamTimeout = time.Duration(e.StreamTimeout)
+	}
+
+	if e.Retries != 0 {
+		opts.Retries = e.Retries
+	}
+
+	if e.ContentType != "" {
+		opts.ContentType = e.ContentType
+	}
+
+	if len(e.PreferredTransports) > 0 {
+		opts.PreferredTransports = e.PreferredTransports
+	}
+
+	maps.Copy(opts.Metadata, e.Metadata)
+}
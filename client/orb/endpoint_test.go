@@ -0,0 +1,83 @@
+package orb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeOptionsEndpointConfig(t *testing.T) {
+	c := &Client{config: NewConfig(WithEndpointConfig(
+		EndpointConfig{
+			Service:        "users",
+			RequestTimeout: config.Duration(time.Second),
+			Metadata:       map[string]string{"tenant": "a"},
+		},
+		EndpointConfig{
+			Service:             "users",
+			Endpoint:            "/users.Users/*",
+			Retries:             -1,
+			ContentType:         "application/json",
+			PreferredTransports: []string{"grpc"},
+			Metadata:            map[string]string{"tenant": "b"},
+		},
+	))}
+
+	opts := c.makeOptions("users", "/users.Users/Get")
+	require.Equal(t, time.Second, opts.RequestTimeout)
+	require.Equal(t, -1, opts.Retries)
+	require.Equal(t, "application/json", opts.ContentType)
+	require.Equal(t, []string{"grpc"}, opts.PreferredTransports)
+	require.Equal(t, "b", opts.Metadata["tenant"])
+
+	// Explicit call options win over the config.
+	opts = c.makeOptions("users", "/users.Users/Get", client.WithRequestTimeout(time.Minute), client.WithMetadata(map[string]string{"tenant": "c"}))
+	require.Equal(t, time.Minute, opts.RequestTimeout)
+	require.Equal(t, "c", opts.Metadata["tenant"])
+
+	opts = c.makeOptions("orders", "/orders.Orders/Get")
+	require.Equal(t, client.DefaultRequestTimeout, opts.RequestTimeout)
+	require.Equal(t, client.DefaultContentType, opts.ContentType)
+	require.Empty(t, opts.Metadata)
+}
+
+func TestEndpointConfigMatches(t *testing.T) {
+	service := EndpointConfig{Service: "users"}
+	endpoint := EndpointConfig{Service: "users", Endpoint: "/users.Users/*"}
+
+	require.True(t, service.matches("users", ""))
+	require.True(t, service.matches("users", "/users.Users/Get"))
+
+	// Without an endpoint only service wide overrides apply.
+	require.False(t, endpoint.matches("users", ""))
+	require.True(t, endpoint.matches("users", "/users.Users/Get"))
+	require.False(t, endpoint.matches("users", "/users.Admin/Get"))
+}
+
+func TestNewFiltersEndpointTransports(t *testing.T) {
+	Transports.Set("endpoint-test", func(log.Logger, *Config) (TransportType, error) {
+		return TransportType{}, nil
+	})
+	defer Transports.Del("endpoint-test")
+
+	cfg := NewConfig(WithEndpointConfig(
+		EndpointConfig{Service: "users", PreferredTransports: []string{"unknown", "endpoint-test"}},
+		EndpointConfig{Service: "orders", PreferredTransports: []string{"unknown"}},
+	))
+
+	c := New(cfg, log.Logger{}, registry.Type{})
+	require.Equal(t, []string{"endpoint-test"}, c.config.Endpoints[0].PreferredTransports)
+	require.Empty(t, c.config.Endpoints[1].PreferredTransports)
+
+	// Overrides without known transports keep the client's.
+	opts := c.makeOptions("orders", "/orders.Orders/Get")
+	require.Equal(t, c.config.Config.PreferredTransports, opts.PreferredTransports)
+
+	// The caller's config is left alone.
+	require.Equal(t, []string{"unknown", "endpoint-test"}, cfg.Endpoints[0].PreferredTransports)
+}
@@ -0,0 +1,201 @@
+package orb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// MetadataZone is the registry node metadata key servers publish their zone under.
+const MetadataZone = "zone"
+
+const (
+	// DefaultLocalityMinHealthyNodes is the number of healthy nodes a locality tier
+	// needs before the client spills over to the next tier.
+	DefaultLocalityMinHealthyNodes = 1
+
+	// DefaultLocalityCooldown is the time a failed node is considered unhealthy.
+	DefaultLocalityCooldown = 30 * time.Second
+)
+
+// LocalityConfig configures locality-aware routing.
+//
+// With locality enabled the client prefers nodes in its own zone, spills
+// over to nodes in its own region and finally to nodes in other regions
+// when a tier has less than MinHealthyNodes healthy nodes.
+type LocalityConfig struct {
+	// Enabled turns on locality-aware routing.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Region of this client, defaults to the client's Region.
+	//
+	// With locality enabled the registry gets queried for all regions,
+	// this region is only used to order the nodes.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Zone of this client, nodes advertise their zone with the "zone" metadata.
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+
+	// MinHealthyNodes is the threshold of healthy nodes in a tier,
+	// below it the next tier gets added to the candidates.
+	MinHealthyNodes int `json:"minHealthyNodes" yaml:"minHealthyNodes"`
+
+	// Cooldown is the time a node is considered unhealthy after a failed request.
+	Cooldown config.Duration `json:"cooldown" yaml:"cooldown"`
+}
+
+// WithLocality enables locality-aware routing for the given region and zone.
+func WithLocality(region, zone string) client.Option {
+	return func(c client.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Locality.Enabled = true
+			cfg.Locality.Region = region
+			cfg.Locality.Zone = zone
+		}
+	}
+}
+
+// WithLocalityMinHealthyNodes sets the number of healthy nodes per tier before spilling over.
+func WithLocalityMinHealthyNodes(n int) client.Option {
+	return func(c client.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Locality.MinHealthyNodes = n
+		}
+	}
+}
+
+// WithLocalityCooldown sets the time a failed node is considered unhealthy.
+func WithLocalityCooldown(d time.Duration) client.Option {
+	return func(c client.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Locality.Cooldown = config.Duration(d)
+		}
+	}
+}
+
+// nodeHealth tracks nodes which failed recently.
+type nodeHealth struct {
+	mu     sync.Mutex
+	failed map[string]time.Time
+}
+
+func newNodeHealth() *nodeHealth {
+	return &nodeHealth{failed: make(map[string]time.Time)}
+}
+
+// markFailed marks the node as unhealthy for the given cooldown.
+func (h *nodeHealth) markFailed(address string, cooldown time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.failed[address] = time.Now().Add(cooldown)
+}
+
+// markHealthy removes the node from the failed nodes.
+func (h *nodeHealth) markHealthy(address string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.failed, address)
+}
+
+// healthy reports whether the node has no failure within its cooldown.
+func (h *nodeHealth) healthy(address string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	until, ok := h.failed[address]
+	if !ok {
+		return true
+	}
+
+	if time.Now().After(until) {
+		delete(h.failed, address)
+		return true
+	}
+
+	return false
+}
+
+// reportNode records the result of a request or stream open to the node for
+// locality-aware routing.
+func (c *Client) reportNode(address string, err error) {
+	if !c.config.Locality.Enabled {
+		return
+	}
+
+	if isNodeFailure(err) {
+		c.health.markFailed(address, time.Duration(c.config.Locality.Cooldown))
+	} else if err == nil {
+		c.health.markHealthy(address)
+	}
+}
+
+// isNodeFailure reports whether the error indicates an unhealthy node.
+func isNodeFailure(err error) bool {
+	orbe, ok := orberrors.As(err)
+	if !ok {
+		return false
+	}
+
+	switch orbe.Code {
+	case 502, 503, 504:
+		return true
+	default:
+		return false
+	}
+}
+
+// localitySelector wraps next and only passes nodes of the closest tier
+// with enough healthy nodes.
+func localitySelector(cfg LocalityConfig, region string, health *nodeHealth, next client.SelectorFunc) client.SelectorFunc {
+	return func(ctx context.Context, service string, nodes []registry.ServiceNode) (registry.ServiceNode, error) {
+		if len(nodes) == 0 {
+			return registry.ServiceNode{}, client.ErrNoNodeFound
+		}
+
+		sameZone := make([]registry.ServiceNode, 0, len(nodes))
+		sameRegion := make([]registry.ServiceNode, 0, len(nodes))
+		all := make([]registry.ServiceNode, 0, len(nodes))
+
+		for _, node := range nodes {
+			if !health.healthy(node.Address) {
+				continue
+			}
+
+			all = append(all, node)
+
+			if node.Region != region {
+				continue
+			}
+
+			sameRegion = append(sameRegion, node)
+
+			if cfg.Zone != "" && node.Metadata[MetadataZone] == cfg.Zone {
+				sameZone = append(sameZone, node)
+			}
+		}
+
+		minHealthy := max(cfg.MinHealthyNodes, 1)
+
+		switch {
+		case len(sameZone) >= minHealthy:
+			return next(ctx, service, sameZone)
+		case len(sameRegion) >= minHealthy:
+			return next(ctx, service, sameRegion)
+		case len(all) > 0:
+			return next(ctx, service, all)
+		default:
+			// All nodes are unhealthy, let the caller try any of them.
+			return next(ctx, service, nodes)
+		}
+	}
+}
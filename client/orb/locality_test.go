@@ -0,0 +1,130 @@
+package orb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/stretchr/testify/require"
+)
+
+func firstNode(_ context.Context, _ string, nodes []registry.ServiceNode) (registry.ServiceNode, error) {
+	return nodes[0], nil
+}
+
+func localityNodes() []registry.ServiceNode {
+	return []registry.ServiceNode{
+		{Address: "us-1", Region: "us", Metadata: map[string]string{MetadataZone: "us-east-1a"}},
+		{Address: "eu-b", Region: "eu", Metadata: map[string]string{MetadataZone: "eu-west-1b"}},
+		{Address: "eu-a", Region: "eu", Metadata: map[string]string{MetadataZone: "eu-west-1a"}},
+	}
+}
+
+func TestLocalitySelector(t *testing.T) {
+	cfg := LocalityConfig{Enabled: true, Zone: "eu-west-1a", MinHealthyNodes: 1}
+
+	tests := []struct {
+		name    string
+		failed  []string
+		minNode int
+		want    string
+	}{
+		{name: "same zone", want: "eu-a"},
+		{name: "spill to region", failed: []string{"eu-a"}, want: "eu-b"},
+		{name: "spill to other regions", failed: []string{"eu-a", "eu-b"}, want: "us-1"},
+		{name: "threshold spills to region", minNode: 2, want: "eu-b"},
+		{name: "all unhealthy", failed: []string{"eu-a", "eu-b", "us-1"}, want: "us-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := newNodeHealth()
+			for _, addr := range tt.failed {
+				health.markFailed(addr, time.Minute)
+			}
+
+			c := cfg
+			if tt.minNode > 0 {
+				c.MinHealthyNodes = tt.minNode
+			}
+
+			node, err := localitySelector(c, "eu", health, firstNode)(context.Background(), "svc", localityNodes())
+			require.NoError(t, err)
+			require.Equal(t, tt.want, node.Address)
+		})
+	}
+}
+
+func TestNodeHealthCooldown(t *testing.T) {
+	health := newNodeHealth()
+
+	health.markFailed("a", -time.Second)
+	require.True(t, health.healthy("a"))
+
+	health.markFailed("a", time.Minute)
+	require.False(t, health.healthy("a"))
+
+	health.markHealthy("a")
+	require.True(t, health.healthy("a"))
+}
+
+func TestReportNode(t *testing.T) {
+	c := &Client{health: newNodeHealth()}
+	c.config.Locality = LocalityConfig{Enabled: true, Cooldown: config.Duration(time.Minute)}
+
+	c.reportNode("a", orberrors.ErrUnavailable)
+	require.False(t, c.health.healthy("a"))
+
+	// Errors of the request itself don't mark the node.
+	c.reportNode("b", orberrors.ErrBadRequest)
+	require.True(t, c.health.healthy("b"))
+
+	c.reportNode("a", nil)
+	require.True(t, c.health.healthy("a"))
+}
+
+func TestLocalityCustomSelector(t *testing.T) {
+	c := &Client{health: newNodeHealth()}
+	c.config.Locality = LocalityConfig{Enabled: true, Region: "eu", Zone: "eu-west-1a", MinHealthyNodes: 1}
+
+	lastNode := func(_ context.Context, _ string, nodes []registry.ServiceNode) (registry.ServiceNode, error) {
+		return nodes[len(nodes)-1], nil
+	}
+
+	// The custom selector only gets the nodes of the closest tier.
+	opts := c.makeOptions("svc", "/svc/Call", client.WithSelector(lastNode))
+
+	node, err := opts.Selector(context.Background(), "svc", []registry.ServiceNode{
+		{Address: "eu-a", Region: "eu", Metadata: map[string]string{MetadataZone: "eu-west-1a"}},
+		{Address: "us-1", Region: "us", Metadata: map[string]string{MetadataZone: "us-east-1a"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "eu-a", node.Address)
+}
+
+// zonedMemoryServer is a memory server which advertises its locality.
+type zonedMemoryServer struct {
+	client.MemoryServer
+}
+
+func (zonedMemoryServer) Region() string { return "eu" }
+
+func (zonedMemoryServer) Metadata() map[string]string {
+	return map[string]string{MetadataZone: "eu-west-1a"}
+}
+
+func TestMemoryNodeLocality(t *testing.T) {
+	node := memoryNode("users", zonedMemoryServer{})
+	require.Equal(t, "memory", node.Scheme)
+	require.Equal(t, "eu", node.Region)
+	require.Equal(t, "eu-west-1a", node.Metadata[MetadataZone])
+
+	// Memory servers without locality have none.
+	node = memoryNode("users", struct{ client.MemoryServer }{})
+	require.Empty(t, node.Region)
+	require.Empty(t, node.Metadata)
+}
@@ -22,6 +22,13 @@ type Transport interface {
 	Stream(ctx context.Context, infos client.RequestInfos, opts *client.CallOptions) (client.StreamIface[any, any], error)
 }
 
+// Warmer is implemented by transports which are able to open connections
+// ahead of the first request.
+type Warmer interface {
+	// Warmup opens up to n connections to the address.
+	Warmup(ctx context.Context, address string, n int) error
+}
+
 // TransportType is the type returned by NewTransportFunc.
 type TransportType struct {
 	Transport
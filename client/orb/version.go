@@ -0,0 +1,255 @@
+package orb
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// MetadataVersion is the metadata key to force a version for a request.
+//
+// The value is either a semver constraint for the requested service,
+// e.g. "=1.5.0-rc1", or a comma separated list of "service=constraint"
+// pairs. It is read from the call's metadata and from the incoming
+// metadata, so it follows a request through all services.
+//
+// A bare constraint only applies to the call it was set on, from the
+// incoming metadata only "service=constraint" pairs get applied, else
+// the constraint would pin every service downstream.
+const MetadataVersion = "x-orb-version"
+
+// VersionRule pins the versions of a service and splits traffic between them.
+type VersionRule struct {
+	// Service is the service name, it may contain path.Match patterns. Empty matches all.
+	Service string `json:"service" yaml:"service"`
+
+	// Constraint is a semver constraint all nodes must match, e.g. ">=1.4 <2".
+	Constraint string `json:"constraint,omitempty" yaml:"constraint,omitempty"`
+
+	// Split splits traffic by weight between version constraints,
+	// e.g. 95 to "~1.4" and 5 to "~1.5".
+	//
+	// Every request picks its share at random, there is no stickiness,
+	// a caller may get different versions on consecutive requests.
+	Split []VersionWeight `json:"split,omitempty" yaml:"split,omitempty"`
+}
+
+// VersionWeight is a weighted version constraint of a VersionRule.
+type VersionWeight struct {
+	// Constraint is the semver constraint of this share.
+	Constraint string `json:"constraint" yaml:"constraint"`
+
+	// Weight is the relative share of traffic.
+	Weight int `json:"weight" yaml:"weight"`
+}
+
+// WithVersionRules adds version rules to the client.
+func WithVersionRules(rules ...VersionRule) client.Option {
+	return func(c client.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Versions = append(cfg.Versions, rules...)
+		}
+	}
+}
+
+// WithVersionConstraint only selects nodes matching the semver constraint for this call.
+func WithVersionConstraint(constraint string) client.CallOption {
+	return func(o *client.CallOptions) {
+		next := o.Selector
+
+		o.Selector = func(ctx context.Context, service string, nodes []registry.ServiceNode) (registry.ServiceNode, error) {
+			filtered, err := filterByConstraint(service, constraint, nodes)
+			if err != nil {
+				return registry.ServiceNode{}, err
+			}
+
+			return next(ctx, service, filtered)
+		}
+	}
+}
+
+//nolint:gochecknoglobals
+var constraintCache sync.Map
+
+// parseConstraint parses and caches a semver constraint.
+func parseConstraint(constraint string) (*semver.Constraints, error) {
+	if c, ok := constraintCache.Load(constraint); ok {
+		return c.(*semver.Constraints), nil //nolint:forcetypeassert
+	}
+
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, orberrors.ErrBadRequest.WrapF("invalid version constraint %q: %w", constraint, err)
+	}
+
+	constraintCache.Store(constraint, c)
+
+	return c, nil
+}
+
+// matchConstraint returns the nodes matching the constraint.
+func matchConstraint(c *semver.Constraints, nodes []registry.ServiceNode) []registry.ServiceNode {
+	result := make([]registry.ServiceNode, 0, len(nodes))
+
+	for _, node := range nodes {
+		v, err := semver.NewVersion(node.Version)
+		if err != nil {
+			continue
+		}
+
+		if c.Check(v) {
+			result = append(result, node)
+		}
+	}
+
+	return result
+}
+
+// filterByConstraint returns the nodes matching the constraint or an error if there are none.
+func filterByConstraint(service, constraint string, nodes []registry.ServiceNode) ([]registry.ServiceNode, error) {
+	c, err := parseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	result := matchConstraint(c, nodes)
+	if len(result) == 0 {
+		return nil, orberrors.ErrUnavailable.Wrap(
+			fmt.Errorf("%w: %s with version %q", client.ErrNoNodeFound, service, constraint),
+		)
+	}
+
+	return result, nil
+}
+
+// versionOverride returns the forced version constraint for the service.
+func versionOverride(ctx context.Context, service string, opts *client.CallOptions) string {
+	if value := opts.Metadata[MetadataVersion]; value != "" {
+		return parseVersionOverride(value, service, true)
+	}
+
+	if md, ok := metadata.Incoming(ctx); ok && md[MetadataVersion] != "" {
+		return parseVersionOverride(md[MetadataVersion], service, false)
+	}
+
+	return ""
+}
+
+// parseVersionOverride returns the constraint of the service from a MetadataVersion value,
+// a bare constraint is only returned with allowBare.
+func parseVersionOverride(value, service string, allowBare bool) string {
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+
+		name, constraint, found := strings.Cut(part, "=")
+		if !found || name == "" || strings.ContainsAny(name, "<>=~^! ") {
+			// Not a "service=constraint" pair, the value applies to all services.
+			if allowBare {
+				return value
+			}
+
+			continue
+		}
+
+		if name == service {
+			return constraint
+		}
+	}
+
+	return ""
+}
+
+// filterVersions applies the version override or the configured version rules to the nodes.
+func (c *Client) filterVersions(
+	ctx context.Context,
+	service string,
+	nodes []registry.ServiceNode,
+	opts *client.CallOptions,
+) ([]registry.ServiceNode, error) {
+	// The in-memory server has no versions.
+	if len(nodes) == 1 && nodes[0].Scheme == "memory" {
+		return nodes, nil
+	}
+
+	if constraint := versionOverride(ctx, service, opts); constraint != "" {
+		return filterByConstraint(service, constraint, nodes)
+	}
+
+	for _, rule := range c.config.Versions {
+		if !matchPattern(rule.Service, service) {
+			continue
+		}
+
+		var err error
+
+		if rule.Constraint != "" {
+			nodes, err = filterByConstraint(service, rule.Constraint, nodes)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(rule.Split) > 0 {
+			return splitByWeight(service, rule.Split, nodes)
+		}
+
+		return nodes, nil
+	}
+
+	return nodes, nil
+}
+
+// splitByWeight picks one weighted share which has nodes and returns its nodes.
+//
+// The pick is random per call.
+func splitByWeight(service string, split []VersionWeight, nodes []registry.ServiceNode) ([]registry.ServiceNode, error) {
+	shares := make([][]registry.ServiceNode, len(split))
+	total := 0
+
+	for i, share := range split {
+		if share.Weight <= 0 {
+			continue
+		}
+
+		c, err := parseConstraint(share.Constraint)
+		if err != nil {
+			return nil, err
+		}
+
+		shares[i] = matchConstraint(c, nodes)
+		if len(shares[i]) > 0 {
+			total += share.Weight
+		}
+	}
+
+	if total == 0 {
+		return nil, orberrors.ErrUnavailable.Wrap(
+			fmt.Errorf("%w: %s with any version of the split", client.ErrNoNodeFound, service),
+		)
+	}
+
+	pick := rand.IntN(total) //nolint:gosec
+
+	for i, share := range split {
+		if share.Weight <= 0 || len(shares[i]) == 0 {
+			continue
+		}
+
+		if pick < share.Weight {
+			return shares[i], nil
+		}
+
+		pick -= share.Weight
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,103 @@
+package orb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/stretchr/testify/require"
+)
+
+func versionNodes() []registry.ServiceNode {
+	return []registry.ServiceNode{
+		{Address: "v1.3", Version: "1.3.0"},
+		{Address: "v1.4", Version: "1.4.2"},
+		{Address: "v1.5", Version: "1.5.0"},
+		{Address: "v2", Version: "2.0.0"},
+		{Address: "dev", Version: "dev"},
+	}
+}
+
+func addresses(nodes []registry.ServiceNode) []string {
+	result := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		result = append(result, n.Address)
+	}
+
+	return result
+}
+
+func TestFilterVersionsRule(t *testing.T) {
+	c := &Client{config: NewConfig(WithVersionRules(VersionRule{Service: "svc.*", Constraint: ">=1.4 <2"}))}
+
+	nodes, err := c.filterVersions(context.Background(), "svc.users", versionNodes(), &client.CallOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1.4", "v1.5"}, addresses(nodes))
+
+	nodes, err = c.filterVersions(context.Background(), "other", versionNodes(), &client.CallOptions{})
+	require.NoError(t, err)
+	require.Len(t, nodes, 5)
+}
+
+func TestFilterVersionsSplit(t *testing.T) {
+	c := &Client{config: NewConfig(WithVersionRules(VersionRule{
+		Service: "svc",
+		Split: []VersionWeight{
+			{Constraint: "~1.4", Weight: 95},
+			{Constraint: "~1.5", Weight: 5},
+		},
+	}))}
+
+	counts := map[string]int{}
+
+	for range 2000 {
+		nodes, err := c.filterVersions(context.Background(), "svc", versionNodes(), &client.CallOptions{})
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+
+		counts[nodes[0].Address]++
+	}
+
+	require.Greater(t, counts["v1.4"], counts["v1.5"])
+	require.Positive(t, counts["v1.5"])
+	require.Equal(t, 2000, counts["v1.4"]+counts["v1.5"])
+}
+
+func TestFilterVersionsOverride(t *testing.T) {
+	c := &Client{config: NewConfig(WithVersionRules(VersionRule{Service: "svc", Constraint: "<2"}))}
+
+	opts := &client.CallOptions{Metadata: map[string]string{MetadataVersion: "=2.0.0"}}
+	nodes, err := c.filterVersions(context.Background(), "svc", versionNodes(), opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v2"}, addresses(nodes))
+
+	ctx, md := metadata.WithIncoming(context.Background())
+	md[MetadataVersion] = "other=~1.3, svc=~1.5"
+
+	nodes, err = c.filterVersions(ctx, "svc", versionNodes(), &client.CallOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1.5"}, addresses(nodes))
+
+	_, err = c.filterVersions(ctx, "svc", versionNodes()[:1], &client.CallOptions{})
+	require.Error(t, err)
+}
+
+func TestFilterVersionsIncomingBare(t *testing.T) {
+	c := &Client{config: NewConfig()}
+
+	// A bare constraint from upstream must not pin downstream services.
+	ctx, md := metadata.WithIncoming(context.Background())
+	md[MetadataVersion] = "=2.0.0"
+
+	nodes, err := c.filterVersions(ctx, "svc", versionNodes(), &client.CallOptions{})
+	require.NoError(t, err)
+	require.Len(t, nodes, 5)
+
+	// The call's own metadata has precedence and may be bare.
+	opts := &client.CallOptions{Metadata: map[string]string{MetadataVersion: "~1.4"}}
+	nodes, err = c.filterVersions(ctx, "svc", versionNodes(), opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1.4"}, addresses(nodes))
+}
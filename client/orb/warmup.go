@@ -0,0 +1,152 @@
+package orb
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/registry"
+)
+
+const (
+	// DefaultWarmupConnections is the default number of connections to open per node.
+	DefaultWarmupConnections = 1
+
+	// DefaultWarmupTimeout is the default timeout of the warm-up in Client.Start.
+	DefaultWarmupTimeout = 5 * time.Second
+)
+
+// WarmupConfig configures connection pre-warming.
+//
+// The client opens connections to all nodes of Services on Start and,
+// with Watch, to each node which gets added to the registry later.
+// Only transports implementing Warmer can be pre-warmed.
+type WarmupConfig struct {
+	// Services to pre-warm connections for, empty disables pre-warming.
+	Services []string `json:"services,omitempty" yaml:"services,omitempty"`
+
+	// Connections is the number of connections per node.
+	Connections int `json:"connections" yaml:"connections"`
+
+	// Timeout of the warm-up on Start and per added node.
+	Timeout config.Duration `json:"timeout" yaml:"timeout"`
+
+	// Watch pre-warms connections to nodes added to the registry after Start.
+	Watch bool `json:"watch" yaml:"watch"`
+}
+
+// WithWarmup pre-warms connections to the given services on Start and
+// on registry add events.
+func WithWarmup(connections int, services ...string) client.Option {
+	return func(c client.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Warmup.Services = services
+			cfg.Warmup.Connections = connections
+			cfg.Warmup.Watch = true
+		}
+	}
+}
+
+// asWarmer returns the Warmer of the transport if it implements one.
+func asWarmer(t Transport) (Warmer, bool) {
+	if tt, ok := t.(TransportType); ok {
+		t = tt.Transport
+	}
+
+	w, ok := t.(Warmer)
+
+	return w, ok
+}
+
+// warmupServices opens connections to all nodes of the configured services.
+func (c *Client) warmupServices(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.config.Warmup.Timeout))
+	defer cancel()
+
+	opts := c.makeOptions("", "")
+
+	for _, service := range c.config.Warmup.Services {
+		nodes, err := c.resolveService(ctx, service, opts)
+		if err != nil {
+			c.logger.Warn("Failed to resolve a service for warm-up", "service", service, "error", err)
+			continue
+		}
+
+		for _, node := range nodes {
+			c.warmupNode(ctx, node)
+		}
+	}
+}
+
+// warmupNode opens connections to the node, errors only get logged.
+func (c *Client) warmupNode(ctx context.Context, node registry.ServiceNode) {
+	if node.Scheme == "memory" {
+		return
+	}
+
+	if !c.config.Config.AnyTransport && !slices.Contains(c.config.Config.PreferredTransports, node.Scheme) {
+		return
+	}
+
+	t, err := c.transport(node.Scheme)
+	if err != nil {
+		return
+	}
+
+	w, ok := asWarmer(t)
+	if !ok {
+		return
+	}
+
+	if err := w.Warmup(ctx, node.Address, c.config.Warmup.Connections); err != nil {
+		c.logger.Warn(
+			"Failed to warm-up connections",
+			"service", node.Name,
+			"address", node.Address,
+			"transport", node.Scheme,
+			"error", err,
+		)
+
+		return
+	}
+
+	c.logger.Debug(
+		"Warmed-up connections",
+		"service", node.Name,
+		"address", node.Address,
+		"transport", node.Scheme,
+		"connections", c.config.Warmup.Connections,
+	)
+}
+
+// watchWarmup pre-warms connections to nodes of the service added to the registry until ctx is done.
+func (c *Client) watchWarmup(ctx context.Context, service string) {
+	watcher, err := c.registry.Watch(ctx, registry.WatchService(service))
+	if err != nil {
+		c.logger.Warn("Failed to watch a service for warm-up", "service", service, "error", err)
+		return
+	}
+
+	for {
+		result, err := watcher.Next()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, registry.ErrWatcherStopped) {
+				c.logger.Warn("Stopped watching a service for warm-up", "service", service, "error", err)
+			}
+
+			return
+		}
+
+		if result.Action != registry.Create {
+			continue
+		}
+
+		nodeCtx, cancel := context.WithTimeout(ctx, time.Duration(c.config.Warmup.Timeout))
+		c.warmupNode(nodeCtx, result.Node)
+		cancel()
+	}
+}
@@ -0,0 +1,55 @@
+package orb
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/container"
+	"github.com/stretchr/testify/require"
+)
+
+type warmupTransport struct {
+	warmed map[string]int
+}
+
+func (t *warmupTransport) Start() error                 { return nil }
+func (t *warmupTransport) Stop(_ context.Context) error { return nil }
+func (t *warmupTransport) Name() string                 { return "warmup" }
+
+func (t *warmupTransport) Request(_ context.Context, _ client.RequestInfos, _ any, _ any, _ *client.CallOptions) error {
+	return nil
+}
+
+func (t *warmupTransport) Stream(
+	_ context.Context,
+	_ client.RequestInfos,
+	_ *client.CallOptions,
+) (client.StreamIface[any, any], error) {
+	return nil, nil //nolint:nilnil
+}
+
+func (t *warmupTransport) Warmup(_ context.Context, address string, n int) error {
+	t.warmed[address] += n
+	return nil
+}
+
+func TestWarmupNode(t *testing.T) {
+	wt := &warmupTransport{warmed: map[string]int{}}
+
+	c := &Client{
+		config:     NewConfig(WithWarmup(3, "svc"), client.WithClientPreferredTransports("warmup")),
+		logger:     log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+		transports: container.NewMap[string, Transport](),
+	}
+	c.transports.Set("warmup", TransportType{Transport: wt})
+
+	c.warmupNode(context.Background(), registry.ServiceNode{Name: "svc", Scheme: "warmup", Address: "a:1"})
+	c.warmupNode(context.Background(), registry.ServiceNode{Name: "svc", Scheme: "other", Address: "b:1"})
+
+	require.Equal(t, map[string]int{"a:1": 3}, wt.warmed)
+}
@@ -0,0 +1,11 @@
+package drpc
+
+import (
+	"testing"
+
+	"github.com/go-orb/plugins/client/tests"
+)
+
+func TestEntrypointDrain(t *testing.T) {
+	tests.EntrypointDrain(t, "drpc", "drpc", nil)
+}
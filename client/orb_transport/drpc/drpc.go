@@ -42,14 +42,20 @@ func (e *encoder) Unmarshal(data []byte, msg drpc.Message) error {
 // Name is the transports name.
 const Name = "drpc"
 
+// TLSName is the name of the transport to dRPC servers with TLS.
+const TLSName = "drpcs"
+
 func init() {
 	orb.RegisterTransport(Name, NewTransport("tcp"))
 	orb.RegisterTransport("unix+"+Name, NewTransport("unix"))
+	orb.RegisterTransport(TLSName, NewTLSTransport("tcp"))
+	orb.RegisterTransport("unix+"+TLSName, NewTLSTransport("unix"))
 }
 
 // Transport is a go-orb/plugins/client/orb compatible transport.
 type Transport struct {
 	network string
+	secure  bool
 	config  *orb.Config
 	logger  log.Logger
 	pool    *pool.Pool
@@ -57,23 +63,6 @@ type Transport struct {
 
 // Start starts the transport.
 func (t *Transport) Start() error {
-	factory := func(dialCtx context.Context, addr string, _ *tls.Config) (*drpcconn.Conn, error) {
-		// Use the dial timeout from options
-		timeoutCtx, cancel := context.WithTimeout(dialCtx, time.Duration(t.config.DialTimeout))
-		defer cancel()
-
-		dialer := net.Dialer{}
-		rawconn, err := dialer.DialContext(timeoutCtx, t.network, addr)
-
-		if err != nil {
-			t.logger.Error("Failed to dial DRPC server", "address", addr, "error", err)
-			return nil, err
-		}
-
-		// Create a new DRPC connection
-		return drpcconn.New(rawconn), nil
-	}
-
 	t.logger.Debug(
 		"Creating a transport pool",
 		"pool_hosts", t.config.PoolHosts,
@@ -82,7 +71,7 @@ func (t *Transport) Start() error {
 		"pool_ttl", t.config.PoolTTL,
 	)
 
-	pool, err := pool.New(factory, t.config.PoolHosts*t.config.PoolSize, time.Duration(t.config.PoolTTL))
+	pool, err := pool.New(t.dial, t.config.PoolHosts*t.config.PoolSize, time.Duration(t.config.PoolTTL))
 	if err != nil {
 		return orberrors.From(err)
 	}
@@ -98,18 +87,69 @@ func (t *Transport) Stop(_ context.Context) error {
 	return nil
 }
 
+// Warmup opens up to n connections to the address.
+func (t *Transport) Warmup(ctx context.Context, address string, n int) error {
+	if _, err := t.pool.Warmup(ctx, address, t.config.TLSConfig, n); err != nil {
+		return orberrors.From(err)
+	}
+
+	return nil
+}
+
 // Name returns the name of this transport.
 func (t *Transport) Name() string {
+	name := Name
+	if t.secure {
+		name = TLSName
+	}
+
 	if t.network == "unix" {
-		return "unix+" + Name
+		return "unix+" + name
 	}
 
-	return Name
+	return name
+}
+
+// dial connects to addr, with TLS if the transport is secure.
+//
+// Without tlsConfig the certificate of the server doesn't get verified, like with grpcs.
+func (t *Transport) dial(ctx context.Context, addr string, tlsConfig *tls.Config) (*drpcconn.Conn, error) {
+	// Use the dial timeout from options
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(t.config.DialTimeout))
+	defer cancel()
+
+	dialer := net.Dialer{}
+	rawconn, err := dialer.DialContext(timeoutCtx, t.network, addr)
+
+	if err != nil {
+		t.logger.Error("Failed to dial DRPC server", "address", addr, "error", err)
+		return nil, err
+	}
+
+	if !t.secure {
+		// Create a new DRPC connection
+		return drpcconn.New(rawconn), nil
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	}
+
+	tlsConn := tls.Client(rawconn, tlsConfig)
+	if err := tlsConn.HandshakeContext(timeoutCtx); err != nil {
+		_ = rawconn.Close() //nolint:errcheck
+
+		t.logger.Error("Failed the TLS handshake with the DRPC server", "address", addr, "error", err)
+
+		return nil, err
+	}
+
+	return drpcconn.New(tlsConn), nil
 }
 
 // Request does the actual rpc request to the server.
 func (t *Transport) Request(ctx context.Context, infos client.RequestInfos, req any, result any, opts *client.CallOptions) error {
-	conn, err := t.pool.Get(ctx, infos.Address, nil)
+	conn, err := t.pool.Get(ctx, infos.Address, opts.TLSConfig)
 	if err != nil {
 		return orberrors.From(err)
 	}
@@ -213,25 +253,8 @@ func (t *Transport) Stream(ctx context.Context, infos client.RequestInfos, opts
 		ctx, cancel = context.WithCancel(ctx)
 	}
 
-	factory := func(dialCtx context.Context, addr string, _ *tls.Config) (*drpcconn.Conn, error) {
-		// Use the dial timeout from options
-		timeoutCtx, cancel := context.WithTimeout(dialCtx, time.Duration(t.config.DialTimeout))
-		defer cancel()
-
-		dialer := net.Dialer{}
-		rawconn, err := dialer.DialContext(timeoutCtx, t.network, addr)
-
-		if err != nil {
-			t.logger.Error("Failed to dial DRPC server", "address", addr, "error", err)
-			return nil, err
-		}
-
-		// Create a new DRPC connection
-		return drpcconn.New(rawconn), nil
-	}
-
 	// Get an existing connection from the pool
-	conn, err := factory(ctx, infos.Address, nil)
+	conn, err := t.dial(ctx, infos.Address, opts.TLSConfig)
 	if err != nil {
 		cancel()
 		return nil, orberrors.From(err)
@@ -313,3 +336,26 @@ func NewTransport(network string) func(logger log.Logger, cfg *orb.Config) (orb.
 		}}, nil
 	}
 }
+
+// NewTLSTransport creates a Transport which connects with TLS, the TLS config
+// of the client is used to verify the servers and to present a client certificate.
+//
+// Clients have to add "drpcs" to their preferred transports.
+func NewTLSTransport(network string) func(logger log.Logger, cfg *orb.Config) (orb.TransportType, error) {
+	return func(logger log.Logger, cfg *orb.Config) (orb.TransportType, error) {
+		logger.Debug("Creating transport",
+			"network", network,
+			"secure", true,
+			"pool_hosts", cfg.PoolHosts,
+			"pool_size", cfg.PoolSize,
+			"conn_timeout", cfg.ConnectionTimeout,
+		)
+
+		return orb.TransportType{Transport: &Transport{
+			config:  cfg,
+			logger:  logger,
+			network: network,
+			secure:  true,
+		}}, nil
+	}
+}
@@ -65,6 +65,19 @@ func setupServer(sn string) (*tests.SetupData, error) {
 		return nil, err
 	}
 
+	epTLS, err := drpc.New(
+		sn, "", "drpcs",
+		drpc.NewConfig(
+			drpc.WithHandlers(echoHRegister, fileHRegister),
+			drpc.WithTLS(nil),
+		),
+		logger, reg)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
 	epUnix, err := drpc.New(
 		sn, "", "unix+drpc",
 		drpc.NewConfig(
@@ -79,9 +92,24 @@ func setupServer(sn string) (*tests.SetupData, error) {
 		return nil, err
 	}
 
+	epUnixTLS, err := drpc.New(
+		sn, "", "unix+drpcs",
+		drpc.NewConfig(
+			drpc.WithNetwork("unix"),
+			drpc.WithAddress("/tmp/orb-rps-server-drpcs-"+sn+".sock"),
+			drpc.WithHandlers(echoHRegister, fileHRegister),
+			drpc.WithTLS(nil),
+		),
+		logger, reg)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
 	setupData.Logger = logger
 	setupData.Registry = reg
-	setupData.Entrypoints = []server.Entrypoint{ep, epUnix}
+	setupData.Entrypoints = []server.Entrypoint{ep, epTLS, epUnix, epUnixTLS}
 	setupData.Ctx = ctx
 	setupData.Stop = cancel
 
@@ -89,7 +117,7 @@ func setupServer(sn string) (*tests.SetupData, error) {
 }
 
 func newSuite() *tests.TestSuite {
-	s := tests.NewSuite(setupServer, []string{Name, "unix+" + Name})
+	s := tests.NewSuite(setupServer, []string{Name, TLSName, "unix+" + Name, "unix+" + TLSName})
 	// s.Debug = true
 	return s
 }
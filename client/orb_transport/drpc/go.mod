@@ -12,6 +12,7 @@ require (
 	github.com/go-orb/plugins/log/slog v0.2.0
 	github.com/go-orb/plugins/registry/mdns v0.1.0
 	github.com/go-orb/plugins/server/drpc v0.2.0
+	github.com/go-orb/plugins/server/http v0.2.0
 	github.com/stretchr/testify v1.10.0
 	google.golang.org/protobuf v1.36.6
 	storj.io/drpc v0.0.34
@@ -22,7 +23,6 @@ require (
 	github.com/cornelk/hashmap v1.0.8 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/go-orb/plugins/registry/regutil v0.2.0 // indirect
-	github.com/go-orb/plugins/server/http v0.2.0 // indirect
 	github.com/go-orb/plugins/server/memory v0.1.0 // indirect
 	github.com/go-task/slim-sprig/v3 v3.0.0 // indirect
 	github.com/google/pprof v0.0.0-20250317173921-a4b03ec1a45e // indirect
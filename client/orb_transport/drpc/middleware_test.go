@@ -0,0 +1,19 @@
+package drpc
+
+import (
+	"testing"
+
+	"github.com/go-orb/plugins/client/tests"
+)
+
+func TestEntrypointTimeout(t *testing.T) {
+	tests.EntrypointTimeout(t, "drpc", "drpc", nil)
+}
+
+func TestEntrypointStreamMiddlewares(t *testing.T) {
+	tests.EntrypointStreamMiddlewares(t, "drpc", "drpc", nil)
+}
+
+func TestEntrypointPanic(t *testing.T) {
+	tests.EntrypointPanic(t, "drpc", "drpc", nil)
+}
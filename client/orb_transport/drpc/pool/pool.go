@@ -171,6 +171,66 @@ func (p *Pool) Get(ctx context.Context, addr string, tlsConfig *tls.Config) (*Cl
 	return &wrapper, err
 }
 
+// Warmup creates up to n connections to addr ahead of the first request,
+// it returns the number of new connections.
+func (p *Pool) Warmup(ctx context.Context, addr string, tlsConfig *tls.Config, n int) (int, error) {
+	if p.IsClosed() {
+		return 0, ErrClosed
+	}
+
+	clients := p.GetClients(addr)
+
+	// Take the idle clients without waiting for busy ones.
+	taken := make([]ClientConn, 0, n)
+
+take:
+	for len(taken) < n {
+		select {
+		case wrapper := <-clients:
+			taken = append(taken, wrapper)
+		default:
+			break take
+		}
+	}
+
+	var (
+		created int
+		err     error
+	)
+
+	for i := range taken {
+		if taken[i].Conn != nil {
+			continue
+		}
+
+		taken[i].Conn, err = p.factory(ctx, addr, tlsConfig)
+		if err != nil {
+			break
+		}
+		taken[i].timeInitiated = time.Now()
+		taken[i].timeUsed = time.Now()
+		created++
+	}
+
+	// Return them to the pool.
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, wrapper := range taken {
+		if p.clients == nil {
+			if wrapper.Conn != nil {
+				wrapper.Conn.Close() //nolint:errcheck,gosec
+			}
+
+			continue
+		}
+
+		clients <- wrapper
+	}
+
+	return created, err
+}
+
 // Unhealthy marks the client conn as unhealthy, so that the connection
 // gets reset when closed.
 func (c *ClientConn) Unhealthy() {
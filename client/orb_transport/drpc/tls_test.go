@@ -0,0 +1,101 @@
+package drpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-orb/plugins/client/tests"
+	"github.com/go-orb/plugins/client/tests/proto/echo"
+	"github.com/go-orb/plugins/server/drpc"
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
+)
+
+// writeClientCertificate writes a self signed client certificate which is its own CA.
+func writeClientCertificate(t *testing.T, certFile, keyFile, commonName string, uris ...*url.URL) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		URIs:                  uris,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+}
+
+func TestEntrypointMTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+
+	spiffeID, err := url.Parse("spiffe://example.org/ns/default/sa/billing")
+	require.NoError(t, err)
+
+	writeClientCertificate(t, certFile, keyFile, "billing", spiffeID)
+
+	clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+
+	probes := &tests.ProbeLog{}
+	ep := tests.StartEntrypoint(t, "drpc", TLSName, nil,
+		drpc.WithMTLS(certFile),
+		server.WithEntrypointMiddlewares(&tests.Probe{Name: "peer", Log: probes}),
+	)
+
+	call := func(certs []tls.Certificate) error {
+		ctx, md := metadata.WithOutgoing(context.Background())
+		md[utls.MetadataPeerSPIFFEID] = "spiffe://example.org/admin"
+
+		_, err := echo.NewStreamsClient(ep.Client).Call(ctx, ep.Service, &echo.CallRequest{Name: "Alex"},
+			client.WithTLSConfig(&tls.Config{InsecureSkipVerify: true, Certificates: certs}), //nolint:gosec
+		)
+
+		return err
+	}
+
+	// Clients without a certificate get rejected.
+	require.Error(t, call(nil))
+
+	require.NoError(t, call([]tls.Certificate{clientCert}))
+
+	calls := probes.Calls()
+	require.Len(t, calls, 1)
+
+	md := calls[0].Metadata
+	require.Equal(t, "CN=billing", md[utls.MetadataPeerSubject])
+	require.Equal(t, "billing", md[utls.MetadataPeerCommonName])
+	require.Equal(t, spiffeID.String(), md[utls.MetadataPeerSPIFFEID], "the metadata must not override the certificate")
+}
@@ -90,6 +90,23 @@ func (t *Transport) Stop(_ context.Context) error {
 	return nil
 }
 
+// Warmup opens up to n connections to the address.
+func (t *Transport) Warmup(ctx context.Context, address string, n int) error {
+	t.poolLock.Lock()
+	p := t.pool
+	t.poolLock.Unlock()
+
+	if p == nil {
+		return orberrors.ErrUnavailable.Wrap(pool.ErrClosed)
+	}
+
+	if _, err := p.Warmup(ctx, address, t.config.TLSConfig, n); err != nil {
+		return toOrbError(err)
+	}
+
+	return nil
+}
+
 // Name returns the name of this transport.
 func (t *Transport) Name() string {
 	return t.name
@@ -0,0 +1,19 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/go-orb/plugins/client/tests"
+)
+
+func TestEntrypointTimeout(t *testing.T) {
+	tests.EntrypointTimeout(t, "grpc", "grpc", map[string]any{"insecure": true})
+}
+
+func TestEntrypointStreamMiddlewares(t *testing.T) {
+	tests.EntrypointStreamMiddlewares(t, "grpc", "grpc", map[string]any{"insecure": true})
+}
+
+func TestEntrypointPanic(t *testing.T) {
+	tests.EntrypointPanic(t, "grpc", "grpc", map[string]any{"insecure": true})
+}
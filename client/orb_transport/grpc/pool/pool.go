@@ -6,10 +6,12 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 )
 
 var (
@@ -171,6 +173,102 @@ func (p *Pool) Get(ctx context.Context, addr string, tlsConfig *tls.Config) (*Cl
 	return &wrapper, err
 }
 
+// Warmup creates up to n connections to addr ahead of the first request
+// and waits until they are ready or ctx is done, it returns the number
+// of new connections.
+func (p *Pool) Warmup(ctx context.Context, addr string, tlsConfig *tls.Config, n int) (int, error) {
+	if p.IsClosed() {
+		return 0, ErrClosed
+	}
+
+	clients := p.GetClients(addr)
+
+	// Take the idle clients without waiting for busy ones.
+	taken := make([]ClientConn, 0, n)
+
+take:
+	for len(taken) < n {
+		select {
+		case wrapper := <-clients:
+			taken = append(taken, wrapper)
+		default:
+			break take
+		}
+	}
+
+	var (
+		created []*grpc.ClientConn
+		err     error
+	)
+
+	for i := range taken {
+		if taken[i].ClientConn != nil {
+			continue
+		}
+
+		taken[i].ClientConn, err = p.factory(ctx, addr, tlsConfig)
+		if err != nil {
+			break
+		}
+
+		// gRPC connects lazily, start connecting now.
+		taken[i].ClientConn.Connect()
+		taken[i].timeInitiated = time.Now()
+		taken[i].timeUsed = time.Now()
+		created = append(created, taken[i].ClientConn)
+	}
+
+	p.putBack(clients, taken)
+
+	if err != nil {
+		return len(created), err
+	}
+
+	for _, cc := range created {
+		if err := waitReady(ctx, cc); err != nil {
+			return len(created), err
+		}
+	}
+
+	return len(created), nil
+}
+
+// putBack returns the taken clients to the pool.
+func (p *Pool) putBack(clients chan ClientConn, taken []ClientConn) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, wrapper := range taken {
+		if p.clients == nil {
+			if wrapper.ClientConn != nil {
+				wrapper.ClientConn.Close() //nolint:errcheck,gosec
+			}
+
+			continue
+		}
+
+		clients <- wrapper
+	}
+}
+
+// waitReady waits until the connection is ready or ctx is done.
+func waitReady(ctx context.Context, cc *grpc.ClientConn) error {
+	for {
+		state := cc.GetState()
+
+		switch state { //nolint:exhaustive
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return ErrAlreadyClosed
+		}
+
+		if !cc.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+		}
+	}
+}
+
 // Unhealthy marks the client conn as unhealthy, so that the connection
 // gets reset when closed.
 func (c *ClientConn) Unhealthy() {
@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func factory(_ context.Context, addr string, _ *tls.Config) (*grpc.ClientConn, error) {
+	return grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+func TestWarmupReady(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	go srv.Serve(lis) //nolint:errcheck
+
+	defer srv.Stop()
+
+	p, err := New(factory, 2, time.Minute)
+	require.NoError(t, err)
+
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := p.Warmup(ctx, lis.Addr().String(), nil, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, created)
+
+	// Warmup returns once the connections are ready.
+	conn, err := p.Get(ctx, lis.Addr().String(), nil)
+	require.NoError(t, err)
+	require.Equal(t, connectivity.Ready, conn.GetState())
+	require.NoError(t, conn.Close())
+}
+
+func TestWarmupTimeout(t *testing.T) {
+	// Nothing listens on the address anymore.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, lis.Close())
+
+	p, err := New(factory, 1, time.Minute)
+	require.NoError(t, err)
+
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = p.Warmup(ctx, lis.Addr().String(), nil, 1)
+	require.ErrorIs(t, err, ErrTimeout)
+	require.Less(t, time.Since(start), 2*time.Second)
+}
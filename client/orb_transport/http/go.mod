@@ -3,6 +3,7 @@ module github.com/go-orb/plugins/client/orb_transport/http
 go 1.23.6
 
 require (
+	github.com/coder/websocket v1.8.13
 	github.com/go-orb/go-orb v0.4.1
 	github.com/go-orb/plugins/client/orb v0.2.1
 	github.com/go-orb/plugins/client/tests v0.3.0
@@ -14,6 +15,7 @@ require (
 	github.com/go-orb/plugins/server/http v0.3.1
 	github.com/quic-go/quic-go v0.50.1
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/net v0.38.0
 )
 
 require (
@@ -40,7 +42,6 @@ require (
 	golang.org/x/crypto v0.36.0 // indirect
 	golang.org/x/exp v0.0.0-20250305212735-054e65f0b394 // indirect
 	golang.org/x/mod v0.24.0 // indirect
-	golang.org/x/net v0.38.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
 	golang.org/x/text v0.23.0 // indirect
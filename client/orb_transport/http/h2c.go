@@ -0,0 +1,261 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/plugins/client/orb"
+)
+
+// dialAddressKey contains the address to dial in the request context,
+// it's used for unix sockets where the URL doesn't contain the address.
+type dialAddressKey struct{}
+
+// dialAddress returns the address from the context or the given address.
+func dialAddress(ctx context.Context, addr string) string {
+	if a, ok := ctx.Value(dialAddressKey{}).(string); ok && a != "" {
+		return a
+	}
+
+	return addr
+}
+
+// h2cPool is a http.RoundTripper for HTTP/2 without TLS (h2c).
+//
+// It keeps up to size connections per host for up to maxHosts hosts,
+// new connections get dialed only when all others are busy.
+type h2cPool struct {
+	transport *http2.Transport
+	network   string
+	dialer    net.Dialer
+	size      int
+	maxHosts  int
+
+	mu    sync.Mutex
+	hosts map[string]*h2cHost
+}
+
+type h2cHost struct {
+	conns    []*http2.ClientConn
+	dialing  int
+	lastUsed time.Time
+}
+
+func newH2CPool(network string, cfg *orb.Config) *h2cPool {
+	return &h2cPool{
+		transport: &http2.Transport{
+			AllowHTTP:       true,
+			IdleConnTimeout: time.Duration(cfg.PoolTTL),
+		},
+		network:  network,
+		dialer:   net.Dialer{Timeout: time.Duration(cfg.DialTimeout)},
+		size:     max(cfg.PoolSize, 1),
+		maxHosts: max(cfg.PoolHosts, 1),
+		hosts:    make(map[string]*h2cHost),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *h2cPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	cc, err := p.conn(req.Context(), dialAddress(req.Context(), req.URL.Host))
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.RoundTrip(req)
+}
+
+// conn returns an idle connection to addr, dials a new one while the pool
+// isn't full and otherwise returns the least busy connection.
+func (p *h2cPool) conn(ctx context.Context, addr string) (*http2.ClientConn, error) {
+	p.mu.Lock()
+
+	host, ok := p.hosts[addr]
+	if !ok {
+		p.evictLocked()
+
+		host = &h2cHost{}
+		p.hosts[addr] = host
+	}
+
+	host.lastUsed = time.Now()
+
+	var (
+		best       *http2.ClientConn
+		bestActive = -1
+		alive      = host.conns[:0]
+	)
+
+	for _, cc := range host.conns {
+		state := cc.State()
+		if state.Closed {
+			continue
+		}
+
+		alive = append(alive, cc)
+
+		if state.Closing || !cc.CanTakeNewRequest() {
+			continue
+		}
+
+		if state.StreamsActive == 0 {
+			p.mu.Unlock()
+			return cc, nil
+		}
+
+		if bestActive < 0 || state.StreamsActive < bestActive {
+			best, bestActive = cc, state.StreamsActive
+		}
+	}
+
+	host.conns = alive
+
+	if best != nil && len(host.conns)+host.dialing >= p.size {
+		p.mu.Unlock()
+		return best, nil
+	}
+
+	host.dialing++
+	p.mu.Unlock()
+
+	cc, err := p.dial(ctx, addr)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	host.dialing--
+
+	if err != nil {
+		if best != nil {
+			return best, nil
+		}
+
+		return nil, err
+	}
+
+	host.conns = append(host.conns, cc)
+
+	return cc, nil
+}
+
+// warmup dials connections to addr until there are n, at most the pool size.
+func (p *h2cPool) warmup(ctx context.Context, addr string, n int) error {
+	p.mu.Lock()
+
+	host, ok := p.hosts[addr]
+	if !ok {
+		p.evictLocked()
+
+		host = &h2cHost{}
+		p.hosts[addr] = host
+	}
+
+	host.lastUsed = time.Now()
+	missing := min(n, p.size) - len(host.conns) - host.dialing
+	host.dialing += max(missing, 0)
+
+	p.mu.Unlock()
+
+	var result error
+
+	for range missing {
+		cc, err := p.dial(ctx, addr)
+
+		p.mu.Lock()
+		host.dialing--
+
+		if err == nil {
+			host.conns = append(host.conns, cc)
+		}
+		p.mu.Unlock()
+
+		if err != nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+// dial creates a new h2c connection to addr.
+func (p *h2cPool) dial(ctx context.Context, addr string) (*http2.ClientConn, error) {
+	conn, err := p.dialer.DialContext(ctx, p.network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cc, err := p.transport.NewClientConn(conn)
+	if err != nil {
+		_ = conn.Close() //nolint:errcheck
+		return nil, err
+	}
+
+	return cc, nil
+}
+
+// evictLocked removes the least recently used host when the pool is full.
+func (p *h2cPool) evictLocked() {
+	if len(p.hosts) < p.maxHosts {
+		return
+	}
+
+	var (
+		oldestAddr string
+		oldest     *h2cHost
+	)
+
+	for addr, host := range p.hosts {
+		if oldest == nil || host.lastUsed.Before(oldest.lastUsed) {
+			oldestAddr, oldest = addr, host
+		}
+	}
+
+	delete(p.hosts, oldestAddr)
+
+	for _, cc := range oldest.conns {
+		go cc.Shutdown(context.Background()) //nolint:errcheck
+	}
+}
+
+// CloseIdleConnections closes all connections, busy connections get closed
+// once their requests are done. It gets called by http.Client.CloseIdleConnections.
+func (p *h2cPool) CloseIdleConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for addr, host := range p.hosts {
+		for _, cc := range host.conns {
+			if cc.State().StreamsActive == 0 {
+				_ = cc.Close() //nolint:errcheck
+				continue
+			}
+
+			go cc.Shutdown(context.Background()) //nolint:errcheck
+		}
+
+		delete(p.hosts, addr)
+	}
+}
+
+// NewH2CTransport creates a new h2c (HTTP/2 without TLS) transport for the orb client.
+func NewH2CTransport(network string) func(logger log.Logger, cfg *orb.Config) (orb.TransportType, error) {
+	return func(logger log.Logger, cfg *orb.Config) (orb.TransportType, error) {
+		return NewTransport(
+			"h2c",
+			logger,
+			"http",
+			network,
+			cfg,
+			&http.Client{
+				Timeout:   time.Duration(cfg.ConnectionTimeout),
+				Transport: newH2CPool(network, cfg),
+			},
+		)
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/go-orb/plugins/client/orb"
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
+
+	"github.com/go-orb/plugins/server/http/wire"
 )
 
 const networkUnix = "unix"
@@ -32,12 +34,58 @@ func init() {
 	orb.RegisterTransport("http3", NewHTTP3Transport)
 	orb.RegisterTransport("https", NewHTTPSTransport)
 	orb.RegisterTransport("unix+http", NewHTTPTransport(networkUnix))
+	orb.RegisterTransport("h2c", NewH2CTransport("tcp"))
+	orb.RegisterTransport("unix+h2c", NewH2CTransport(networkUnix))
+	orb.RegisterTransport("ws", NewWSTransport)
+	orb.RegisterTransport("wss", NewWSSTransport)
 }
 
+// drainLimit is the maximum number of bytes read from a response body before closing it.
+const drainLimit = 64 * 1024
+
 //nolint:gochecknoglobals
 var stdHeaders = []string{"Content-Length", "Content-Type", "Date", "Server"}
 
+//nolint:gochecknoglobals
+var (
+	// ErrRequestTooLarge is returned when the encoded request is larger than MaxCallSendMsgSize.
+	ErrRequestTooLarge = orberrors.New(http.StatusRequestEntityTooLarge, "request message too large")
+
+	// ErrResponseTooLarge is returned when the response is larger than MaxCallRecvMsgSize,
+	// the server handled the request but the client refused to read the response.
+	ErrResponseTooLarge = orberrors.New(http.StatusInternalServerError, "response message too large")
+
+	errLimitExceeded = errors.New("read limit exceeded")
+)
+
+// limitReader returns errLimitExceeded when more than n bytes get read.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, errLimitExceeded
+	}
+
+	// Read one byte more than allowed to detect oversized bodies.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	if l.n < 0 {
+		return n, errLimitExceeded
+	}
+
+	return n, err
+}
+
 var _ (orb.Transport) = (*Transport)(nil)
+var _ (orb.Warmer) = (*Transport)(nil)
 
 // Transport is a go-orb/plugins/client/orb compatible transport.
 type Transport struct {
@@ -48,6 +96,9 @@ type Transport struct {
 	network string
 	scheme  string
 
+	// dialer keeps the connections of Warmup, nil for custom http.Clients.
+	dialer *preDialer
+
 	bufPool *sync.Pool
 }
 
@@ -68,6 +119,33 @@ func (t *Transport) Stop(_ context.Context) error {
 		t.hclient.CloseIdleConnections()
 	}
 
+	if t.dialer != nil {
+		t.dialer.close()
+	}
+
+	return nil
+}
+
+// Warmup opens up to n connections to the address.
+//
+// The connections get dialed ahead and handed to net/http on its next dials,
+// transports with custom http.Clients or HTTP/3 don't warm up.
+func (t *Transport) Warmup(ctx context.Context, address string, n int) error {
+	var err error
+
+	switch p := t.hclient.Transport.(type) {
+	case *h2cPool:
+		err = p.warmup(ctx, address, n)
+	default:
+		if t.dialer != nil {
+			err = t.dialer.warmup(ctx, address, n)
+		}
+	}
+
+	if err != nil {
+		return orberrors.From(err)
+	}
+
 	return nil
 }
 
@@ -98,10 +176,24 @@ func (t *Transport) Request(ctx context.Context, infos client.RequestInfos, req
 		releaseBuff = true
 
 		if err := codec.NewEncoder(buff).Encode(reqTyped); err != nil {
+			buff.Reset()
+			t.bufPool.Put(buff)
+
 			return orberrors.ErrBadRequest.Wrap(err)
 		}
 	}
 
+	if opts.MaxCallSendMsgSize > 0 && buff.Len() > opts.MaxCallSendMsgSize {
+		size := buff.Len()
+
+		if releaseBuff {
+			buff.Reset()
+			t.bufPool.Put(buff)
+		}
+
+		return ErrRequestTooLarge.WrapF("%d bytes, MaxCallSendMsgSize is %d bytes", size, opts.MaxCallSendMsgSize)
+	}
+
 	// Set the connection timeout
 	ctx, cancel := context.WithTimeout(ctx, opts.ConnectionTimeout)
 	defer cancel()
@@ -113,20 +205,15 @@ func (t *Transport) Request(ctx context.Context, infos client.RequestInfos, req
 
 	// Create a net/http request.
 	if t.network == networkUnix {
+		// The dialer picks the socket path from the context.
+		ctx = context.WithValue(ctx, dialAddressKey{}, infos.Address)
+
 		hReq, err = http.NewRequestWithContext(
 			ctx,
 			http.MethodPost,
 			fmt.Sprintf("%s://%s%s", t.scheme, networkUnix, infos.Endpoint),
 			buff,
 		)
-
-		t.hclient.Transport.(*http.Transport).DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) { //nolint:errcheck
-			dialer := net.Dialer{
-				Timeout: time.Duration(t.config.DialTimeout),
-			}
-
-			return dialer.DialContext(ctx, t.network, infos.Address)
-		}
 	} else {
 		hReq, err = http.NewRequestWithContext(
 			ctx,
@@ -166,15 +253,11 @@ func (t *Transport) Request(ctx context.Context, infos client.RequestInfos, req
 		t.bufPool.Put(buff)
 	}
 
-	responseBytes, err := io.ReadAll(resp.Body)
-	if err != nil && !errors.Is(err, io.EOF) {
-		return orberrors.From(err)
-	}
-
-	// Close the request body.
-	if err := resp.Body.Close(); err != nil {
-		return orberrors.From(err)
-	}
+	// Drain and close the response body, so the connection can be reused.
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit)) //nolint:errcheck
+		_ = resp.Body.Close()                                             //nolint:errcheck
+	}()
 
 	if opts.ResponseMetadata != nil {
 		md := opts.ResponseMetadata
@@ -199,11 +282,26 @@ func (t *Transport) Request(ctx context.Context, infos client.RequestInfos, req
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return orberrors.HTTP(resp.StatusCode)
+		return statusError(resp)
+	}
+
+	maxSize := int64(opts.MaxCallRecvMsgSize)
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return ErrResponseTooLarge.WrapF("%d bytes, MaxCallRecvMsgSize is %d bytes", resp.ContentLength, maxSize)
+	}
+
+	var body io.Reader = resp.Body
+	if maxSize > 0 {
+		body = &limitReader{r: resp.Body, n: maxSize}
 	}
 
 	switch resultTyped := result.(type) {
 	case *[]byte:
+		responseBytes, err := io.ReadAll(body)
+		if err != nil {
+			return responseError(err, maxSize)
+		}
+
 		*resultTyped = responseBytes
 	default:
 		codec, err := codecs.GetMime(opts.ContentType)
@@ -211,16 +309,58 @@ func (t *Transport) Request(ctx context.Context, infos client.RequestInfos, req
 			return orberrors.ErrBadRequest.Wrap(err)
 		}
 
-		// Decode the response into `result`.
-		err = codec.Unmarshal(responseBytes, result)
-		if err != nil {
-			return orberrors.ErrBadRequest.Wrap(err)
+		// Stream the response body into `result`.
+		if err := codec.NewDecoder(body).Decode(result); err != nil {
+			return responseError(err, maxSize)
 		}
 	}
 
 	return nil
 }
 
+// statusError decodes the error body written by server/http's WriteError,
+// for other servers the body becomes the wrapped error.
+func statusError(resp *http.Response) error {
+	orbe := orberrors.HTTP(resp.StatusCode)
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, drainLimit))
+	if err != nil || len(data) == 0 {
+		return orbe
+	}
+
+	if codec, err := codecs.GetMime(resp.Header.Get("Content-Type")); err == nil {
+		body := &wire.ErrorBody{}
+		if err := codec.Unmarshal(data, body); err == nil && body.Code != 0 {
+			return body.Err()
+		}
+	}
+
+	// Plain text bodies usually start with the message of the code.
+	if msg := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(string(data)), orbe.Message), ": "); msg != "" {
+		return orbe.Wrap(errors.New(msg))
+	}
+
+	return orbe
+}
+
+// responseError converts an error while reading the response body into an orberror.
+func responseError(err error, maxSize int64) error {
+	if errors.Is(err, errLimitExceeded) {
+		return ErrResponseTooLarge.WrapF("more than %d bytes, the MaxCallRecvMsgSize", maxSize)
+	}
+
+	if _, ok := orberrors.As(err); ok {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return orberrors.From(err)
+	}
+
+	return orberrors.ErrBadRequest.Wrap(err)
+}
+
 // Stream creates a bidirectional stream to the service endpoint.
 // HTTP transport does not support streaming operations by default.
 func (t *Transport) Stream(_ context.Context, _ client.RequestInfos, _ *client.CallOptions) (client.StreamIface[any, any], error) {
@@ -235,6 +375,18 @@ func NewTransport(
 	network string,
 	cfg *orb.Config,
 	hclient *http.Client,
+) (orb.TransportType, error) {
+	return newTransport(name, logger, scheme, network, cfg, hclient, nil)
+}
+
+func newTransport(
+	name string,
+	logger log.Logger,
+	scheme string,
+	network string,
+	cfg *orb.Config,
+	hclient *http.Client,
+	dialer *preDialer,
 ) (orb.TransportType, error) {
 	return orb.TransportType{Transport: &Transport{
 		config:  cfg,
@@ -243,6 +395,7 @@ func NewTransport(
 		scheme:  scheme,
 		network: network,
 		hclient: hclient,
+		dialer:  dialer,
 	},
 	}, nil
 }
@@ -250,7 +403,9 @@ func NewTransport(
 // NewHTTPTransport creates a new https transport for the orb client.
 func NewHTTPTransport(network string) func(logger log.Logger, cfg *orb.Config) (orb.TransportType, error) {
 	return func(logger log.Logger, cfg *orb.Config) (orb.TransportType, error) {
-		return NewTransport(
+		dialer := newPreDialer(network, cfg)
+
+		return newTransport(
 			"http",
 			logger,
 			"http",
@@ -266,14 +421,10 @@ func NewHTTPTransport(network string) func(logger log.Logger, cfg *orb.Config) (
 					ExpectContinueTimeout: 1 * time.Second,
 					ForceAttemptHTTP2:     false,
 					DisableKeepAlives:     false,
-					DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
-						dialer := net.Dialer{
-							Timeout: time.Duration(cfg.DialTimeout),
-						}
-						return dialer.DialContext(ctx, network, addr)
-					},
+					DialContext:           dialer.DialContext,
 				},
 			},
+			dialer,
 		)
 	}
 }
@@ -323,7 +474,9 @@ func NewHTTPSTransport(logger log.Logger, cfg *orb.Config) (orb.TransportType, e
 		tlsConfig = cfg.TLSConfig
 	}
 
-	return NewTransport(
+	dialer := newPreDialer("tcp", cfg)
+
+	return newTransport(
 		"https",
 		logger,
 		"https",
@@ -339,14 +492,11 @@ func NewHTTPSTransport(logger log.Logger, cfg *orb.Config) (orb.TransportType, e
 				ExpectContinueTimeout: 1 * time.Second,
 				ForceAttemptHTTP2:     false,
 				DisableKeepAlives:     false,
-				Dial: (&net.Dialer{
-					Timeout:   time.Duration(cfg.DialTimeout),
-					KeepAlive: 15 * time.Second,
-					DualStack: false,
-				}).Dial,
-				TLSHandshakeTimeout: time.Duration(cfg.DialTimeout),
-				TLSClientConfig:     tlsConfig,
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   time.Duration(cfg.DialTimeout),
+				TLSClientConfig:       tlsConfig,
 			},
 		},
+		dialer,
 	)
 }
@@ -1,21 +1,40 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
 	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-orb/go-orb/client"
 	"github.com/go-orb/go-orb/log"
 	"github.com/go-orb/go-orb/registry"
 	"github.com/go-orb/go-orb/server"
 	"github.com/go-orb/go-orb/types"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/go-orb/plugins/client/orb"
 	"github.com/go-orb/plugins/client/tests"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/go-orb/plugins/server/http"
 
 	echohandler "github.com/go-orb/plugins/client/tests/handler/echo"
+	filehandler "github.com/go-orb/plugins/client/tests/handler/file"
 	echoproto "github.com/go-orb/plugins/client/tests/proto/echo"
+	fileproto "github.com/go-orb/plugins/client/tests/proto/file"
 
 	// Blank imports here are fine.
 	_ "github.com/go-orb/plugins/codecs/json"
@@ -97,9 +116,45 @@ func setupServer(sn string) (*tests.SetupData, error) {
 		return nil, err
 	}
 
+	ep4, err := http.New(
+		sn,
+		"",
+		"h2c",
+		http.NewConfig(
+			http.WithHandlers(hRegister),
+			http.WithInsecure(),
+			http.WithAllowH2C(),
+		),
+		logger,
+		reg,
+	)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	ep5, err := http.New(
+		sn,
+		"",
+		"ws",
+		http.NewConfig(
+			http.WithHandlers(hRegister, fileproto.RegisterFileServiceHandler(new(filehandler.Handler))),
+			http.WithInsecure(),
+			http.WithWebSocket(),
+		),
+		logger,
+		reg,
+	)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
 	setupData.Logger = logger
 	setupData.Registry = reg
-	setupData.Entrypoints = []server.Entrypoint{ep1, ep2, ep3}
+	setupData.Entrypoints = []server.Entrypoint{ep1, ep2, ep3, ep4, ep5}
 	setupData.Ctx = ctx
 	setupData.Stop = cancel
 
@@ -107,7 +162,7 @@ func setupServer(sn string) (*tests.SetupData, error) {
 }
 
 func newSuite() *tests.TestSuite {
-	s := tests.NewSuite(setupServer, []string{"http", "https", "http3"})
+	s := tests.NewSuite(setupServer, []string{"http", "https", "http3", "h2c", "ws"})
 	// s.Debug = true
 	return s
 }
@@ -120,3 +175,178 @@ func TestSuite(t *testing.T) {
 	// Run the tests.
 	suite.Run(t, newSuite())
 }
+
+func TestStatusError(t *testing.T) {
+	notFound := orberrors.ErrNotFound.Wrap(errors.New("no such user"))
+	sent := orberrors.ErrBadRequest.Wrap(fmt.Errorf("lookup: %w", notFound))
+
+	for _, accept := range []string{"application/json", "application/x-protobuf", "text/plain"} {
+		req := httptest.NewRequest(nethttp.MethodPost, "/", nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("X-Request-Id", "abc")
+
+		rec := httptest.NewRecorder()
+		http.WriteError(rec, req, sent)
+
+		err := statusError(rec.Result()) //nolint:bodyclose
+		require.Equal(t, sent.Error(), err.Error(), accept)
+		require.ErrorIs(t, err, orberrors.ErrBadRequest, accept)
+		require.ErrorIs(t, err, orberrors.ErrNotFound, accept)
+	}
+
+	// Bodies of other servers get wrapped.
+	resp := &nethttp.Response{
+		StatusCode: nethttp.StatusConflict,
+		Header:     nethttp.Header{"Content-Type": []string{"text/plain"}},
+		Body:       io.NopCloser(strings.NewReader("conflict: already exists")),
+	}
+
+	err := statusError(resp)
+	require.ErrorIs(t, err, orberrors.HTTP(nethttp.StatusConflict))
+	require.Equal(t, "conflict: already exists", err.Error())
+}
+
+func newTestTransport(t *testing.T, factory orb.TransportFactory) *Transport {
+	t.Helper()
+
+	logger, err := log.New()
+	require.NoError(t, err)
+
+	cfg := orb.NewConfig()
+
+	tt, err := factory(logger, &cfg)
+	require.NoError(t, err)
+
+	transport, ok := tt.Transport.(*Transport)
+	require.True(t, ok)
+	require.NoError(t, transport.Start())
+
+	t.Cleanup(func() { _ = transport.Stop(context.Background()) }) //nolint:errcheck
+
+	return transport
+}
+
+func TestMessageSizeLimits(t *testing.T) {
+	const limit = 16
+
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		_, _ = io.Copy(io.Discard, r.Body) //nolint:errcheck
+
+		size, _ := strconv.Atoi(r.Header.Get("X-Size")) //nolint:errcheck
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+
+		if r.Header.Get("X-Chunked") != "" {
+			// Flushing the headers first drops the Content-Length.
+			w.(nethttp.Flusher).Flush() //nolint:errcheck
+		}
+
+		_, _ = w.Write(bytes.Repeat([]byte("a"), size)) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	transport := newTestTransport(t, NewHTTPTransport("tcp"))
+	infos := client.RequestInfos{Address: strings.TrimPrefix(srv.URL, "http://"), Endpoint: "/echo.Streams/Call"}
+
+	tests := []struct {
+		name     string
+		sendSize int
+		recvSize int
+		chunked  bool
+		want     *orberrors.Error
+		wantCode int
+	}{
+		{name: "send at limit", sendSize: limit},
+		{name: "send over limit", sendSize: limit + 1, want: ErrRequestTooLarge, wantCode: nethttp.StatusRequestEntityTooLarge},
+		{name: "receive at limit", recvSize: limit},
+		{name: "receive over limit", recvSize: limit + 1, want: ErrResponseTooLarge, wantCode: nethttp.StatusInternalServerError},
+		{name: "receive chunked at limit", recvSize: limit, chunked: true},
+		{
+			name: "receive chunked over limit", recvSize: limit + 1, chunked: true,
+			want: ErrResponseTooLarge, wantCode: nethttp.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := map[string]string{"X-Size": strconv.Itoa(tt.recvSize)}
+			if tt.chunked {
+				md["X-Chunked"] = "1"
+			}
+
+			opts := &client.CallOptions{
+				ContentType:        "application/octet-stream",
+				ConnectionTimeout:  time.Second,
+				Metadata:           md,
+				MaxCallSendMsgSize: limit,
+				MaxCallRecvMsgSize: limit,
+			}
+
+			var result []byte
+
+			err := transport.Request(context.Background(), infos, bytes.Repeat([]byte("a"), tt.sendSize), &result, opts)
+			if tt.want == nil {
+				require.NoError(t, err)
+				require.Len(t, result, tt.recvSize)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.want)
+
+			orbe, ok := orberrors.As(err)
+			require.True(t, ok)
+			require.Equal(t, tt.wantCode, orbe.Code)
+		})
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	for name, factory := range map[string]orb.TransportFactory{
+		"http": NewHTTPTransport("tcp"),
+		"h2c":  NewH2CTransport("tcp"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var requests, conns atomic.Int64
+
+			handler := nethttp.HandlerFunc(func(w nethttp.ResponseWriter, _ *nethttp.Request) {
+				requests.Add(1)
+				w.WriteHeader(nethttp.StatusNoContent)
+			})
+
+			srv := httptest.NewUnstartedServer(h2c.NewHandler(handler, &http2.Server{}))
+			srv.Config.ConnState = func(_ net.Conn, state nethttp.ConnState) {
+				if state == nethttp.StateNew {
+					conns.Add(1)
+				}
+			}
+			srv.Start()
+
+			defer srv.Close()
+
+			address := strings.TrimPrefix(srv.URL, "http://")
+			transport := newTestTransport(t, factory)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			// A nil *orberrors.Error would be a non-nil error, NoError catches that.
+			require.NoError(t, transport.Warmup(ctx, address, 2))
+			require.Eventually(t, func() bool { return conns.Load() == 2 }, time.Second, 10*time.Millisecond)
+
+			// Warmup only connects, it sends no requests.
+			require.Zero(t, requests.Load())
+
+			// Requests use the warm connections.
+			req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := transport.hclient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			require.Equal(t, int64(1), requests.Load())
+			require.Equal(t, int64(2), conns.Load())
+		})
+	}
+}
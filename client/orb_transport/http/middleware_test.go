@@ -0,0 +1,11 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/go-orb/plugins/client/tests"
+)
+
+func TestEntrypointTimeout(t *testing.T) {
+	tests.EntrypointTimeout(t, "http", "http", map[string]any{"insecure": true})
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-orb/plugins/client/orb"
+)
+
+// preDialer is the dialer of the net/http based transports, it hands out
+// connections dialed by warmup before dialing new ones.
+//
+// net/http has no way to add connections to its idle pool, so warmup dials
+// them here and the http.Transport takes them on its next dials.
+type preDialer struct {
+	network string
+	dialer  net.Dialer
+	size    int
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	conns map[string][]warmConn
+}
+
+type warmConn struct {
+	conn   net.Conn
+	dialed time.Time
+}
+
+func newPreDialer(network string, cfg *orb.Config) *preDialer {
+	return &preDialer{
+		network: network,
+		dialer: net.Dialer{
+			Timeout:   time.Duration(cfg.DialTimeout),
+			KeepAlive: 15 * time.Second,
+		},
+		size:  max(cfg.PoolSize, 1),
+		ttl:   time.Duration(cfg.PoolTTL),
+		conns: make(map[string][]warmConn),
+	}
+}
+
+// DialContext returns a warm connection to addr or dials a new one.
+func (d *preDialer) DialContext(ctx context.Context, _, addr string) (net.Conn, error) {
+	addr = dialAddress(ctx, addr)
+
+	if conn := d.take(addr); conn != nil {
+		return conn, nil
+	}
+
+	return d.dialer.DialContext(ctx, d.network, addr)
+}
+
+// take returns a warm connection to addr, expired ones get closed.
+func (d *preDialer) take(addr string) net.Conn {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for len(d.conns[addr]) > 0 {
+		wc := d.conns[addr][0]
+		d.conns[addr] = d.conns[addr][1:]
+
+		if d.ttl <= 0 || time.Since(wc.dialed) < d.ttl {
+			return wc.conn
+		}
+
+		_ = wc.conn.Close() //nolint:errcheck
+	}
+
+	delete(d.conns, addr)
+
+	return nil
+}
+
+// warmup dials connections to addr until there are n warm ones, at most the pool size.
+func (d *preDialer) warmup(ctx context.Context, addr string, n int) error {
+	d.mu.Lock()
+	missing := min(n, d.size) - len(d.conns[addr])
+	d.mu.Unlock()
+
+	if missing <= 0 {
+		return nil
+	}
+
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+
+	results := make(chan result, missing)
+
+	for range missing {
+		go func() {
+			conn, err := d.dialer.DialContext(ctx, d.network, addr)
+			results <- result{conn: conn, err: err}
+		}()
+	}
+
+	var err error
+
+	for range missing {
+		r := <-results
+		if r.err != nil {
+			err = r.err
+			continue
+		}
+
+		d.mu.Lock()
+		d.conns[addr] = append(d.conns[addr], warmConn{conn: r.conn, dialed: time.Now()})
+		d.mu.Unlock()
+	}
+
+	return err
+}
+
+// close closes all warm connections.
+func (d *preDialer) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for addr, conns := range d.conns {
+		for _, wc := range conns {
+			_ = wc.conn.Close() //nolint:errcheck
+		}
+
+		delete(d.conns, addr)
+	}
+}
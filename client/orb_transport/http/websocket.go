@@ -0,0 +1,283 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/go-orb/plugins/client/orb"
+
+	"github.com/go-orb/plugins/server/http/wire"
+)
+
+var _ (orb.Transport) = (*WebSocketTransport)(nil)
+
+// WebSocketTransport sends requests like the http and https transports and
+// opens streams over WebSockets, see server/http for the framing.
+type WebSocketTransport struct {
+	*Transport
+
+	wsScheme string
+}
+
+// Stream opens a WebSocket to the service endpoint.
+func (t *WebSocketTransport) Stream(ctx context.Context, infos client.RequestInfos, opts *client.CallOptions) (client.StreamIface[any, any], error) {
+	codec, err := codecs.GetMime(opts.ContentType)
+	if err != nil {
+		return nil, orberrors.ErrBadRequest.Wrap(err)
+	}
+
+	var cancel context.CancelFunc
+
+	if opts.StreamTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.StreamTimeout)
+	} else {
+		// The stream cancels it on Close.
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	header := http.Header{}
+	for name, value := range opts.Metadata {
+		header.Set(name, value)
+	}
+
+	header.Set("Content-Type", opts.ContentType)
+
+	dialCtx, dialCancel := context.WithTimeout(ctx, opts.ConnectionTimeout)
+	defer dialCancel()
+
+	conn, resp, err := websocket.Dial(
+		dialCtx,
+		fmt.Sprintf("%s://%s%s", t.wsScheme, infos.Address, infos.Endpoint),
+		&websocket.DialOptions{HTTPClient: t.hclient, HTTPHeader: header},
+	)
+	if err != nil {
+		cancel()
+
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			if resp.Body != nil {
+				return nil, statusError(resp)
+			}
+
+			return nil, orberrors.HTTP(resp.StatusCode).Wrap(err)
+		}
+
+		return nil, orberrors.From(err)
+	}
+
+	if opts.MaxCallRecvMsgSize > 0 {
+		// One more byte for the flag.
+		conn.SetReadLimit(int64(opts.MaxCallRecvMsgSize) + 1)
+	}
+
+	return &webSocketClientStream{
+		ctx:    ctx,
+		cancel: cancel,
+		conn:   conn,
+		codec:  codec,
+		opts:   opts,
+	}, nil
+}
+
+// webSocketClientStream implements client.StreamIface over a WebSocket.
+type webSocketClientStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	conn   *websocket.Conn
+	codec  codecs.Marshaler
+	opts   *client.CallOptions
+
+	mu         sync.Mutex
+	closed     bool
+	sendClosed bool
+}
+
+// Context returns the context for this stream.
+func (w *webSocketClientStream) Context() context.Context {
+	return w.ctx
+}
+
+// Send sends a message to the stream.
+func (w *webSocketClientStream) Send(msg any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return orberrors.ErrBadRequest.WrapNew("stream is closed")
+	}
+
+	if w.sendClosed {
+		return orberrors.ErrBadRequest.WrapNew("send direction is closed")
+	}
+
+	data, err := w.codec.Marshal(msg)
+	if err != nil {
+		return orberrors.ErrBadRequest.Wrap(err)
+	}
+
+	if w.opts.MaxCallSendMsgSize > 0 && len(data) > w.opts.MaxCallSendMsgSize {
+		return ErrRequestTooLarge.WrapF("%d bytes, MaxCallSendMsgSize is %d bytes", len(data), w.opts.MaxCallSendMsgSize)
+	}
+
+	return w.write(wire.WebSocketFlagMessage, data)
+}
+
+// Recv receives a message from the stream, metadata frames get copied to the response metadata.
+// It returns io.EOF after the server finished the stream.
+func (w *webSocketClientStream) Recv(msg any) error {
+	if w.closed {
+		return orberrors.ErrBadRequest.WrapNew("stream is closed")
+	}
+
+	for {
+		typ, data, err := w.conn.Read(w.ctx)
+		if err != nil {
+			return webSocketError(err)
+		}
+
+		if typ != websocket.MessageBinary || len(data) == 0 {
+			return orberrors.ErrBadRequest.WrapNew("invalid websocket frame")
+		}
+
+		switch data[0] {
+		case wire.WebSocketFlagMetadata:
+			md := map[string]string{}
+			if err := json.Unmarshal(data[1:], &md); err != nil {
+				return orberrors.ErrBadRequest.Wrap(err)
+			}
+
+			if w.opts.ResponseMetadata != nil {
+				for k, v := range md {
+					w.opts.ResponseMetadata[k] = v
+				}
+			}
+		case wire.WebSocketFlagMessage:
+			if err := w.codec.Unmarshal(data[1:], msg); err != nil {
+				return orberrors.ErrBadRequest.Wrap(err)
+			}
+
+			return nil
+		default:
+			return orberrors.ErrBadRequest.WrapNew(fmt.Sprintf("unknown websocket frame flag %d", data[0]))
+		}
+	}
+}
+
+// CloseSend tells the server that no more messages follow.
+func (w *webSocketClientStream) CloseSend() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return orberrors.ErrBadRequest.WrapNew("stream is closed")
+	}
+
+	if w.sendClosed {
+		return nil
+	}
+
+	w.sendClosed = true
+
+	return w.write(wire.WebSocketFlagCloseSend, nil)
+}
+
+// Close closes the connection.
+func (w *webSocketClientStream) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	w.sendClosed = true
+
+	defer w.cancel()
+
+	err := w.conn.Close(websocket.StatusNormalClosure, "")
+	if err != nil && websocket.CloseStatus(err) == -1 && !errors.Is(err, net.ErrClosed) {
+		return orberrors.From(err)
+	}
+
+	return nil
+}
+
+func (w *webSocketClientStream) write(flag byte, data []byte) error {
+	frame := make([]byte, 0, len(data)+1)
+	frame = append(frame, flag)
+	frame = append(frame, data...)
+
+	if err := w.conn.Write(w.ctx, websocket.MessageBinary, frame); err != nil {
+		return webSocketError(err)
+	}
+
+	return nil
+}
+
+// webSocketError converts the close code of the server back into an orberror.
+func webSocketError(err error) error {
+	var closeErr websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		return orberrors.From(err)
+	}
+
+	if closeErr.Code == websocket.StatusNormalClosure {
+		return io.EOF
+	}
+
+	code := int(closeErr.Code) - wire.WebSocketCloseCodeOffset
+	if code < 100 || code > 999 {
+		return orberrors.From(err)
+	}
+
+	orbe := orberrors.HTTP(code)
+
+	// The reason is the error message of the server, which starts with the message of its code.
+	if reason := strings.TrimPrefix(strings.TrimPrefix(closeErr.Reason, orbe.Message), ": "); reason != "" {
+		return orbe.Wrap(errors.New(reason))
+	}
+
+	return orbe
+}
+
+// NewWSTransport creates a new ws transport for the orb client,
+// requests are sent over HTTP/1.1.
+func NewWSTransport(logger log.Logger, cfg *orb.Config) (orb.TransportType, error) {
+	tt, err := NewHTTPTransport("tcp")(logger, cfg)
+	if err != nil {
+		return orb.TransportType{}, err
+	}
+
+	return newWebSocketTransport(tt, "ws"), nil
+}
+
+// NewWSSTransport creates a new wss transport for the orb client,
+// requests are sent over HTTPS/1.1.
+func NewWSSTransport(logger log.Logger, cfg *orb.Config) (orb.TransportType, error) {
+	tt, err := NewHTTPSTransport(logger, cfg)
+	if err != nil {
+		return orb.TransportType{}, err
+	}
+
+	return newWebSocketTransport(tt, "wss"), nil
+}
+
+// newWebSocketTransport wraps a http transport, name is also the scheme of the WebSockets.
+func newWebSocketTransport(tt orb.TransportType, name string) orb.TransportType {
+	t := tt.Transport.(*Transport) //nolint:errcheck
+	t.name = name
+
+	return orb.TransportType{Transport: &WebSocketTransport{Transport: t, wsScheme: name}}
+}
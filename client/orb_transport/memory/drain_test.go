@@ -0,0 +1,11 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/go-orb/plugins/client/tests"
+)
+
+func TestEntrypointDrain(t *testing.T) {
+	tests.EntrypointDrain(t, "memory", "memory", nil)
+}
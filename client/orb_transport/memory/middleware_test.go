@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/go-orb/plugins/client/tests"
+)
+
+func TestEntrypointTimeout(t *testing.T) {
+	tests.EntrypointTimeout(t, "memory", "memory", nil)
+}
+
+func TestEntrypointStreamMiddlewares(t *testing.T) {
+	tests.EntrypointStreamMiddlewares(t, "memory", "memory", nil)
+}
+
+func TestEntrypointPanic(t *testing.T) {
+	tests.EntrypointPanic(t, "memory", "memory", nil)
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/stretchr/testify/require"
+
+	echohandler "github.com/go-orb/plugins/client/tests/handler/echo"
+	"github.com/go-orb/plugins/client/tests/proto/echo"
+	"github.com/go-orb/plugins/server/srvutil"
+)
+
+// healthReporter is implemented by the entrypoints which report their health.
+type healthReporter interface {
+	Health(ctx context.Context) error
+}
+
+// EntrypointDrain checks the entrypoints of the server plugin fail their health
+// while stopping, finish in-flight requests within the drain timeout and
+// cancel the ones still running afterwards.
+func EntrypointDrain(t *testing.T, plugin string, transport string, configData map[string]any) {
+	t.Helper()
+
+	ep := StartEntrypoint(t, plugin, transport, withConfig(configData, map[string]any{
+		"drainDelay":   "100ms",
+		"drainTimeout": "5s",
+	}))
+
+	health, ok := ep.Entrypoint.(healthReporter)
+	require.True(t, ok, "the %s entrypoint doesn't report its health", plugin)
+	require.NoError(t, health.Health(context.Background()))
+
+	call := slowCall(ep)
+	stopped := stopEntrypoint(ep)
+
+	require.Eventually(t, func() bool {
+		return errors.Is(health.Health(context.Background()), srvutil.ErrDraining)
+	}, time.Second, 5*time.Millisecond)
+
+	// In-flight requests finish within the drain timeout.
+	require.NoError(t, <-call)
+	require.NoError(t, <-stopped)
+	require.ErrorIs(t, health.Health(context.Background()), srvutil.ErrNotStarted)
+
+	// Requests still running after the drain timeout get canceled.
+	ep = StartEntrypoint(t, plugin, transport, withConfig(configData, map[string]any{
+		"drainTimeout": "50ms",
+	}))
+
+	call = slowCall(ep)
+	start := time.Now()
+
+	require.NoError(t, <-stopEntrypoint(ep))
+	require.Error(t, <-call)
+	require.Less(t, time.Since(start), echohandler.SlowDelay)
+}
+
+// slowCall starts a slow call and waits for it to arrive at the handler.
+func slowCall(ep *Entrypoint) <-chan error {
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := echo.NewStreamsClient(ep.Client).Call(
+			context.Background(), ep.Service, &echo.CallRequest{Name: "slow"},
+			client.WithRequestTimeout(5*time.Second),
+		)
+		result <- err
+	}()
+
+	// There's no way to tell when the request arrived, give it a head start.
+	time.Sleep(echohandler.SlowDelay / 5)
+
+	return result
+}
+
+// stopEntrypoint stops the entrypoint in the background.
+func stopEntrypoint(ep *Entrypoint) <-chan error {
+	result := make(chan error, 1)
+
+	go func() { result <- ep.Stop(context.Background()) }()
+
+	return result
+}
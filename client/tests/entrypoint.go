@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/types"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-orb/plugins/server/srvutil"
+
+	echohandler "github.com/go-orb/plugins/client/tests/handler/echo"
+	filehandler "github.com/go-orb/plugins/client/tests/handler/file"
+	echoproto "github.com/go-orb/plugins/client/tests/proto/echo"
+	fileproto "github.com/go-orb/plugins/client/tests/proto/file"
+
+	// The entrypoint tests use the in-memory registry.
+	_ "github.com/go-orb/plugins/registry/memory"
+)
+
+// Entrypoint is a started entrypoint with a client for it.
+type Entrypoint struct {
+	server.Entrypoint
+
+	// Client only uses the transport under test.
+	Client client.Type
+
+	// Service is the unique service name of the entrypoint.
+	Service string
+}
+
+// StartEntrypoint starts an entrypoint of the server plugin with the echo and file handlers
+// and a client for the transport, both get stopped when the test finishes.
+//
+// The entrypoint gets created by the plugin's provider from configData, like
+// from a config file, without an address it listens on a random local port.
+func StartEntrypoint(
+	t *testing.T,
+	plugin string,
+	transport string,
+	configData map[string]any,
+	opts ...server.Option,
+) *Entrypoint {
+	t.Helper()
+
+	ctx := context.Background()
+	service := ServiceName + "-" + uuid.NewString()
+
+	logger, err := log.New()
+	require.NoError(t, err)
+
+	reg, err := registry.New(nil, &types.Components{}, logger, registry.WithPlugin("memory"))
+	require.NoError(t, err)
+	require.NoError(t, reg.Start(ctx))
+
+	configData = maps.Clone(configData)
+	if configData == nil {
+		configData = map[string]any{}
+	}
+
+	if _, ok := configData["address"]; !ok {
+		configData["address"] = "127.0.0.1:0"
+	}
+
+	provider, ok := server.Plugins.Get(plugin)
+	require.True(t, ok, "unknown server plugin %s", plugin)
+
+	opts = append(opts, server.WithEntrypointHandlers(
+		echoproto.RegisterStreamsHandler(new(echohandler.Handler)),
+		fileproto.RegisterFileServiceHandler(new(filehandler.Handler)),
+	))
+
+	ep, err := provider(service, "", transport, configData, logger, reg, opts...)
+	require.NoError(t, err)
+	require.NoError(t, ep.Start(ctx))
+
+	cli, err := client.New(nil, &types.Components{}, logger, reg, client.WithClientPreferredTransports(transport))
+	require.NoError(t, err)
+	require.NoError(t, cli.Start(ctx))
+
+	t.Cleanup(func() {
+		require.NoError(t, cli.Stop(ctx))
+		require.NoError(t, ep.Stop(ctx))
+		require.NoError(t, reg.Stop(ctx))
+	})
+
+	return &Entrypoint{Entrypoint: ep, Client: cli, Service: service}
+}
+
+// ProbeCall is a request seen by a Probe.
+type ProbeCall struct {
+	// Probe is the name of the probe.
+	Probe string
+	// Method is the full method of the request, e.g. "/echo.Streams/Call".
+	Method string
+	// Stream is true for streaming RPCs.
+	Stream bool
+	// Deadline is the deadline of the request context, zero without one.
+	Deadline time.Time
+	// Metadata is a copy of the incoming metadata.
+	Metadata map[string]string
+}
+
+// ProbeLog records the requests seen by probes in the order they run.
+type ProbeLog struct {
+	mu    sync.Mutex
+	calls []ProbeCall
+}
+
+// Calls returns the recorded requests.
+func (l *ProbeLog) Calls() []ProbeCall {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]ProbeCall(nil), l.calls...)
+}
+
+// Probes returns the names of the probes in the order they ran.
+func (l *ProbeLog) Probes() []string {
+	calls := l.Calls()
+	names := make([]string, 0, len(calls))
+
+	for _, c := range calls {
+		names = append(names, c.Probe)
+	}
+
+	return names
+}
+
+func (l *ProbeLog) record(ctx context.Context, probe string, stream bool) {
+	md, _ := metadata.Incoming(ctx)
+	deadline, _ := ctx.Deadline()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls = append(l.calls, ProbeCall{
+		Probe:    probe,
+		Method:   srvutil.FullMethod(md),
+		Stream:   stream,
+		Deadline: deadline,
+		Metadata: maps.Clone(md),
+	})
+}
+
+var _ srvutil.StreamMiddleware = (*Probe)(nil)
+
+// Probe is a server middleware which records the unary and streaming requests it sees.
+type Probe struct {
+	// Name of the probe in the log.
+	Name string
+
+	// Log records the requests.
+	Log *ProbeLog
+
+	// Err short-circuits the chain, the probe returns it without calling next.
+	Err error
+
+	// Panic makes the probe panic instead of calling next.
+	Panic bool
+}
+
+// Start implements types.Component.
+func (p *Probe) Start(context.Context) error { return nil }
+
+// Stop implements types.Component.
+func (p *Probe) Stop(context.Context) error { return nil }
+
+// Type implements types.Component.
+func (p *Probe) Type() string { return "middleware" }
+
+// String implements types.Component.
+func (p *Probe) String() string { return p.Name }
+
+// Call records the unary request.
+func (p *Probe) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		p.Log.record(ctx, p.Name, false)
+
+		if p.Panic {
+			panic("probe " + p.Name + " panics")
+		}
+
+		if p.Err != nil {
+			return nil, p.Err
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// Stream records the streaming request.
+func (p *Probe) Stream(next srvutil.StreamHandler) srvutil.StreamHandler {
+	return func(ctx context.Context, stream srvutil.ServerStream) error {
+		p.Log.record(ctx, p.Name, true)
+
+		if p.Panic {
+			panic("probe " + p.Name + " panics")
+		}
+
+		if p.Err != nil {
+			return p.Err
+		}
+
+		return next(ctx, stream)
+	}
+}
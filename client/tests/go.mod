@@ -6,7 +6,10 @@ require (
 	github.com/go-orb/go-orb v0.3.0
 	github.com/go-orb/plugins/server/drpc v0.2.0
 	github.com/go-orb/plugins/server/http v0.2.0
+	github.com/go-orb/plugins/registry/memory v0.1.0
 	github.com/go-orb/plugins/server/memory v0.1.0
+	github.com/go-orb/plugins/server/middleware/timeout v0.1.0
+	github.com/go-orb/plugins/server/srvutil v0.1.0
 	github.com/google/uuid v1.6.0
 	github.com/stretchr/testify v1.10.0
 	google.golang.org/grpc v1.71.0
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/rand"
 	"errors"
+	"time"
 
 	"github.com/go-orb/go-orb/util/metadata"
 	"github.com/go-orb/go-orb/util/orberrors"
@@ -13,15 +14,30 @@ import (
 
 var _ echo.StreamsServer = (*Handler)(nil)
 
+// SlowDelay is the time Call takes for the name "slow".
+const SlowDelay = 500 * time.Millisecond
+
 // Handler is a test handler.
 type Handler struct {
 }
 
 // Call implements the call method.
-func (c *Handler) Call(_ context.Context, request *echo.CallRequest) (*echo.CallResponse, error) {
+func (c *Handler) Call(ctx context.Context, request *echo.CallRequest) (*echo.CallResponse, error) {
 	switch request.GetName() {
+	case "slow":
+		// Can be used to test timeouts, it answers after SlowDelay.
+		select {
+		case <-time.After(SlowDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		return &echo.CallResponse{Msg: "Hello " + request.GetName()}, nil
 	case "error":
 		return nil, errors.New("you asked for an error, here you go")
+	case "panic":
+		// Can be used to test the recovery of the entrypoints.
+		panic("you asked for a panic, here you go")
 	case "32byte":
 		msg := make([]byte, 32)
 		if _, err := rand.Reader.Read(msg); err != nil {
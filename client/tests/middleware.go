@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/stretchr/testify/require"
+
+	echohandler "github.com/go-orb/plugins/client/tests/handler/echo"
+	"github.com/go-orb/plugins/client/tests/proto/echo"
+	"github.com/go-orb/plugins/client/tests/proto/file"
+
+	// The entrypoint middleware tests configure the timeout middleware.
+	_ "github.com/go-orb/plugins/server/middleware/timeout"
+)
+
+// EntrypointTimeout checks the timeout middleware from the entrypoint config
+// matches the full methods of the entrypoints of the server plugin.
+func EntrypointTimeout(t *testing.T, plugin string, transport string, configData map[string]any) {
+	t.Helper()
+
+	configData = withConfig(configData, map[string]any{
+		"middlewares": []any{
+			map[string]any{
+				"plugin":  "timeout",
+				"timeout": "0s",
+				"methods": map[string]any{
+					"/echo.Streams/Ca*": "50ms",
+				},
+			},
+		},
+	})
+
+	ep := StartEntrypoint(t, plugin, transport, configData)
+	streams := echo.NewStreamsClient(ep.Client)
+
+	start := time.Now()
+	_, err := streams.Call(context.Background(), ep.Service, &echo.CallRequest{Name: "slow"}, client.WithRequestTimeout(5*time.Second))
+	require.Error(t, err, "the method pattern matches, the slow call must time out")
+	require.Less(t, time.Since(start), echohandler.SlowDelay)
+
+	// Calls within the timeout succeed.
+	resp, err := streams.Call(context.Background(), ep.Service, &echo.CallRequest{Name: "Alex"})
+	require.NoError(t, err)
+	require.Equal(t, "Hello Alex", resp.GetMsg())
+}
+
+// EntrypointStreamMiddlewares checks the stream middlewares of the entrypoints of the server plugin
+// run in the order of the unary middlewares and can short-circuit the chain.
+func EntrypointStreamMiddlewares(t *testing.T, plugin string, transport string, configData map[string]any) {
+	t.Helper()
+
+	probes := &ProbeLog{}
+	ep := StartEntrypoint(t, plugin, transport, configData, server.WithEntrypointMiddlewares(
+		&Probe{Name: "first", Log: probes},
+		&Probe{Name: "second", Log: probes},
+	))
+
+	require.NoError(t, upload(ep))
+
+	// Like Call, the last middleware is the outermost one.
+	require.Equal(t, []string{"second", "first"}, probes.Probes())
+
+	for _, call := range probes.Calls() {
+		require.True(t, call.Stream)
+		require.Equal(t, file.EndpointFileServiceUploadFile, call.Method)
+	}
+
+	// Unary calls run the chain in the same order.
+	_, err := echo.NewStreamsClient(ep.Client).Call(context.Background(), ep.Service, &echo.CallRequest{Name: "Alex"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"second", "first", "second", "first"}, probes.Probes())
+
+	// A middleware which doesn't call next stops the chain before the handler.
+	probes = &ProbeLog{}
+	ep = StartEntrypoint(t, plugin, transport, configData, server.WithEntrypointMiddlewares(
+		&Probe{Name: "first", Log: probes},
+		&Probe{Name: "blocker", Log: probes, Err: orberrors.ErrUnauthorized},
+		&Probe{Name: "last", Log: probes},
+	))
+
+	err = upload(ep)
+	require.Error(t, err)
+	require.Equal(t, orberrors.ErrUnauthorized.Code, orberrors.From(err).Code)
+	require.Equal(t, []string{"last", "blocker"}, probes.Probes())
+}
+
+// panicCounter is implemented by the entrypoints which recover panics.
+type panicCounter interface {
+	Panics() uint64
+}
+
+// EntrypointPanic checks the entrypoints of the server plugin recover panics of
+// handlers and stream middlewares, answer them with an internal error and count them.
+func EntrypointPanic(t *testing.T, plugin string, transport string, configData map[string]any) {
+	t.Helper()
+
+	ep := StartEntrypoint(t, plugin, transport, configData)
+
+	counter, ok := ep.Entrypoint.(panicCounter)
+	require.True(t, ok, "the %s entrypoint doesn't count panics", plugin)
+
+	streams := echo.NewStreamsClient(ep.Client)
+
+	_, err := streams.Call(context.Background(), ep.Service, &echo.CallRequest{Name: "panic"})
+	require.Error(t, err)
+	require.Equal(t, orberrors.ErrInternalServerError.Code, orberrors.From(err).Code)
+	require.Equal(t, uint64(1), counter.Panics())
+
+	// The entrypoint keeps serving after the panic.
+	resp, err := streams.Call(context.Background(), ep.Service, &echo.CallRequest{Name: "Alex"})
+	require.NoError(t, err)
+	require.Equal(t, "Hello Alex", resp.GetMsg())
+	require.Equal(t, uint64(1), counter.Panics())
+
+	// Panics of stream middlewares get recovered as well.
+	probes := &ProbeLog{}
+	ep = StartEntrypoint(t, plugin, transport, configData, server.WithEntrypointMiddlewares(
+		&Probe{Name: "panic", Log: probes, Panic: true},
+	))
+	counter = ep.Entrypoint.(panicCounter) //nolint:errcheck
+
+	err = upload(ep)
+	require.Error(t, err)
+	require.Equal(t, orberrors.ErrInternalServerError.Code, orberrors.From(err).Code)
+	require.Equal(t, uint64(1), counter.Panics())
+	require.Equal(t, []string{"panic"}, probes.Probes())
+}
+
+// upload uploads a file chunk with the file service of the entrypoint and returns the first error.
+func upload(ep *Entrypoint) error {
+	stream, err := file.NewFileServiceClient(ep.Client).UploadFile(context.Background(), ep.Service)
+	if err != nil {
+		return err
+	}
+
+	defer stream.Close() //nolint:errcheck
+
+	if err := stream.Send(&file.FileChunk{Filename: "probe.bin", Data: []byte("probe")}); err != nil {
+		return err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	return stream.Recv(&file.UploadResponse{})
+}
+
+// withConfig returns a copy of configData with the values of extra.
+func withConfig(configData map[string]any, extra map[string]any) map[string]any {
+	result := make(map[string]any, len(configData)+len(extra))
+	maps.Copy(result, configData)
+	maps.Copy(result, extra)
+
+	return result
+}
@@ -371,10 +371,18 @@ func registerFileServiceMemoryHandler(srv *memory.Server, handler FileServiceHan
 
 // registerFileServiceHTTPHandler registers the service to an HTTP server.
 func registerFileServiceHTTPHandler(srv *mhttp.Server, handler FileServiceHandler) {
-	// HTTP transport does not support streaming for UploadFile
-	log.Warn("Streaming endpoint not registered with HTTP transport", "endpoint", "/file.FileService/UploadFile")
-	// HTTP transport does not support streaming for AuthorizedUploadFile
-	log.Warn("Streaming endpoint not registered with HTTP transport", "endpoint", "/file.FileService/AuthorizedUploadFile")
+	streamUploadFile := func(stream mhttp.WebSocketStream[FileChunk, UploadResponse]) error {
+		return handler.UploadFile(stream)
+	}
+	if err := srv.Router().Handle("GET", "/file.FileService/UploadFile", mhttp.NewWebSocketHandler(srv, streamUploadFile, HandlerFileService, "UploadFile")); err != nil {
+		log.Error("Failed to register a route", "handler", HandlerFileService, "error", err)
+	}
+	streamAuthorizedUploadFile := func(stream mhttp.WebSocketStream[FileChunk, UploadResponse]) error {
+		return handler.AuthorizedUploadFile(stream)
+	}
+	if err := srv.Router().Handle("GET", "/file.FileService/AuthorizedUploadFile", mhttp.NewWebSocketHandler(srv, streamAuthorizedUploadFile, HandlerFileService, "AuthorizedUploadFile")); err != nil {
+		log.Error("Failed to register a route", "handler", HandlerFileService, "error", err)
+	}
 }
 
 // RegisterFileServiceHandler will return a registration function that can be
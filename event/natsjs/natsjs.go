@@ -535,6 +535,22 @@ func (n *NatsJS) Stop(_ context.Context) error {
 	return nil
 }
 
+// ErrNotConnected is the health of the plugin while the connection to NATS is down.
+var ErrNotConnected = errors.New("not connected to nats")
+
+// Health returns ErrNotConnected while the connection to NATS is down.
+func (n *NatsJS) Health(_ context.Context) error {
+	if n.nc == nil {
+		return ErrNotConnected
+	}
+
+	if !n.nc.IsConnected() {
+		return fmt.Errorf("%w: %s", ErrNotConnected, n.nc.Status())
+	}
+
+	return nil
+}
+
 // String returns the plugin name.
 func (n *NatsJS) String() string {
 	return Name
@@ -4,7 +4,8 @@ go 1.23.6
 
 require (
 	golang.org/x/text v0.23.0
-	google.golang.org/protobuf v1.36.5
+	google.golang.org/genproto/googleapis/api v0.0.0-20250324211829-b45e905df463
+	google.golang.org/protobuf v1.36.6
 )
 
 require github.com/google/go-cmp v0.7.0 // indirect
@@ -52,6 +52,8 @@ func main() {
 		"set to true to use generic types for streaming client and server objects; this flag is EXPERIMENTAL and may be changed or removed in a future release (gRPC)",
 	)
 	servers = flags.String("supported_servers", "drpc;grpc;http", "semicolon separated list of servers to generate for")
+	flags.BoolVar(&orb.OpenAPI, "openapi", false, "generate OpenAPI v3 documents for the http server")
+	flags.StringVar(&orb.OpenAPIVersion, "openapi_version", orb.OpenAPIVersion, "info.version of the generated OpenAPI documents")
 
 	flags.StringVar(&drpcConf.Protolib, "protolib", "google.golang.org/protobuf", "which protobuf library to use for encoding")
 	flags.BoolVar(&drpcConf.JSON, "json", true, "generate encoders with json support")
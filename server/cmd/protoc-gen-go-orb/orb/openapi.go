@@ -0,0 +1,449 @@
+package orb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// OpenAPI enables generation of OpenAPI v3 documents for the http server.
+//
+//nolint:gochecknoglobals
+var OpenAPI bool
+
+// OpenAPIVersion is the info.version of the generated OpenAPI documents.
+//
+//nolint:gochecknoglobals
+var OpenAPIVersion = "1.0.0"
+
+const (
+	openAPISpecVersion = "3.0.3"
+	openAPISchemaRef   = "#/components/schemas/"
+	openAPIErrorRef    = "#/components/responses/Error"
+	openAPIMaxDepth    = 3
+)
+
+// openAPIDocument is an OpenAPI v3 document.
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Tags       []openAPITag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas   map[string]*openAPISchema   `json:"schemas,omitempty"`
+	Responses map[string]*openAPIResponse `json:"responses,omitempty"`
+}
+
+type openAPIOperation struct {
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	OperationID string                      `json:"operationId"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Required    bool           `json:"required,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIResponse struct {
+	Ref         string                      `json:"$ref,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Deprecated           bool                      `json:"deprecated,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+func newOpenAPIDocument(title string) *openAPIDocument {
+	return &openAPIDocument{
+		OpenAPI: openAPISpecVersion,
+		Info:    openAPIInfo{Title: title, Version: OpenAPIVersion},
+		Paths:   map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: map[string]*openAPISchema{},
+			Responses: map[string]*openAPIResponse{
+				"Error": {
+					Description: "Error",
+					Content: map[string]openAPIMediaType{
+						"text/plain": {Schema: &openAPISchema{Type: "string"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// merge adds the tags, paths and schemas of other to the document.
+func (d *openAPIDocument) merge(other *openAPIDocument) {
+	d.Tags = append(d.Tags, other.Tags...)
+
+	for path, ops := range other.Paths {
+		if d.Paths[path] == nil {
+			d.Paths[path] = map[string]*openAPIOperation{}
+		}
+
+		for method, op := range ops {
+			d.Paths[path][method] = op
+		}
+	}
+
+	for name, schema := range other.Components.Schemas {
+		d.Components.Schemas[name] = schema
+	}
+}
+
+func (d *openAPIDocument) addOperation(path, method string, op *openAPIOperation) {
+	if d.Paths[path] == nil {
+		d.Paths[path] = map[string]*openAPIOperation{}
+	}
+
+	d.Paths[path][strings.ToLower(method)] = op
+}
+
+// buildOpenAPI creates the OpenAPI document of a service, it contains the default
+// POST route and the google.api.http bindings of all unary and server-streaming methods.
+func buildOpenAPI(service *protogen.Service, desc serviceDesc) *openAPIDocument {
+	doc := newOpenAPIDocument(string(service.Desc.FullName()))
+	doc.Tags = []openAPITag{{
+		Name:        string(service.Desc.FullName()),
+		Description: comment(service.Comments.Leading),
+	}}
+
+	for i, method := range service.Methods {
+		if method.Desc.IsStreamingClient() {
+			continue
+		}
+
+		op := doc.newOperation(service, method, method.GoName)
+		op.RequestBody = doc.requestBody(method.Input, nil)
+		doc.addOperation(desc.Methods[i].Path, http.MethodPost, op)
+
+		for n, b := range desc.Methods[i].Bindings {
+			op := doc.newOperation(service, method, method.GoName+"_"+strconv.Itoa(n))
+			path, vars := openAPIPath(b.Path)
+
+			for _, v := range vars {
+				op.Parameters = append(op.Parameters, &openAPIParameter{
+					Name:     v,
+					In:       "path",
+					Required: true,
+					Schema:   doc.fieldPathSchema(method.Input, v),
+				})
+			}
+
+			switch b.Body {
+			case "":
+				op.Parameters = append(op.Parameters, doc.queryParameters(method.Input, "", vars, 0)...)
+			case "*":
+				op.RequestBody = doc.requestBody(method.Input, nil)
+			default:
+				field := findGoField(method.Input, b.Body)
+				op.Parameters = append(op.Parameters, doc.queryParameters(method.Input, "", append(vars, b.Body), 0)...)
+				op.RequestBody = doc.requestBody(nil, doc.fieldSchema(field))
+			}
+
+			doc.addOperation(path, b.Method, op)
+		}
+	}
+
+	return doc
+}
+
+func (d *openAPIDocument) newOperation(service *protogen.Service, method *protogen.Method, id string) *openAPIOperation {
+	description := comment(method.Comments.Leading)
+	summary, _, _ := strings.Cut(description, "\n")
+
+	content := map[string]openAPIMediaType{
+		"application/json": {Schema: d.messageSchema(method.Output)},
+	}
+
+	if method.Desc.IsStreamingServer() {
+		description = strings.TrimSpace(description + "\n\nServer-streaming, each message is a server-sent event or a line of JSON.")
+		content = map[string]openAPIMediaType{
+			"text/event-stream":    {Schema: d.messageSchema(method.Output)},
+			"application/x-ndjson": {Schema: d.messageSchema(method.Output)},
+		}
+	}
+
+	return &openAPIOperation{
+		Tags:        []string{string(service.Desc.FullName())},
+		Summary:     summary,
+		Description: description,
+		OperationID: service.GoName + "_" + id,
+		Deprecated:  isDeprecated(method.Desc),
+		Responses: map[string]*openAPIResponse{
+			"200":     {Description: "OK", Content: content},
+			"default": {Ref: openAPIErrorRef},
+		},
+	}
+}
+
+func (d *openAPIDocument) requestBody(msg *protogen.Message, schema *openAPISchema) *openAPIRequestBody {
+	if msg != nil {
+		schema = d.messageSchema(msg)
+	}
+
+	return &openAPIRequestBody{
+		Required: true,
+		Content:  map[string]openAPIMediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// queryParameters returns the scalar fields of msg that aren't bound by the path or the body.
+func (d *openAPIDocument) queryParameters(msg *protogen.Message, prefix string, bound []string, depth int) []*openAPIParameter {
+	result := []*openAPIParameter{}
+
+	for _, field := range msg.Fields {
+		name := prefix + string(field.Desc.Name())
+		if slices.Contains(bound, name) || field.Desc.IsMap() {
+			continue
+		}
+
+		if field.Message != nil {
+			if !field.Desc.IsList() && depth < openAPIMaxDepth {
+				result = append(result, d.queryParameters(field.Message, name+".", bound, depth+1)...)
+			}
+
+			continue
+		}
+
+		result = append(result, &openAPIParameter{
+			Name:        name,
+			In:          "query",
+			Description: comment(field.Comments.Leading),
+			Schema:      d.fieldSchema(field),
+		})
+	}
+
+	return result
+}
+
+// fieldPathSchema returns the schema of the field at the dotted path.
+func (d *openAPIDocument) fieldPathSchema(msg *protogen.Message, path string) *openAPISchema {
+	names := strings.Split(path, ".")
+
+	for i, name := range names {
+		field := findGoField(msg, name)
+		if field == nil {
+			return &openAPISchema{Type: "string"}
+		}
+
+		if i == len(names)-1 || field.Message == nil {
+			return d.fieldSchema(field)
+		}
+
+		msg = field.Message
+	}
+
+	return &openAPISchema{Type: "string"}
+}
+
+// messageSchema adds msg and all messages it references to the components and returns a reference to it.
+func (d *openAPIDocument) messageSchema(msg *protogen.Message) *openAPISchema {
+	name := string(msg.Desc.FullName())
+	ref := &openAPISchema{Ref: openAPISchemaRef + name}
+
+	if _, ok := d.Components.Schemas[name]; ok {
+		return ref
+	}
+
+	schema := &openAPISchema{
+		Type:        "object",
+		Description: comment(msg.Comments.Leading),
+		Properties:  map[string]*openAPISchema{},
+	}
+	// Register before the fields to support recursive messages.
+	d.Components.Schemas[name] = schema
+
+	for _, field := range msg.Fields {
+		fs := d.fieldSchema(field)
+		if c := comment(field.Comments.Leading); c != "" && fs.Ref == "" {
+			fs.Description = strings.TrimSpace(c + "\n" + fs.Description)
+		}
+
+		schema.Properties[string(field.Desc.Name())] = fs
+	}
+
+	return ref
+}
+
+// fieldSchema returns the schema of a field as the encoding/json codec of the http server encodes it.
+func (d *openAPIDocument) fieldSchema(field *protogen.Field) *openAPISchema {
+	if field.Desc.IsMap() {
+		return &openAPISchema{
+			Type:                 "object",
+			AdditionalProperties: d.fieldSchema(field.Message.Fields[1]),
+		}
+	}
+
+	var schema *openAPISchema
+
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		schema = &openAPISchema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		schema = &openAPISchema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		schema = &openAPISchema{Type: "integer", Format: "uint32"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		schema = &openAPISchema{Type: "integer", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		schema = &openAPISchema{Type: "integer", Format: "uint64"}
+	case protoreflect.FloatKind:
+		schema = &openAPISchema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		schema = &openAPISchema{Type: "number", Format: "double"}
+	case protoreflect.BytesKind:
+		schema = &openAPISchema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		schema = &openAPISchema{Type: "integer", Format: "int32"}
+		names := make([]string, 0, len(field.Enum.Values))
+
+		for _, v := range field.Enum.Values {
+			schema.Enum = append(schema.Enum, int32(v.Desc.Number()))
+			names = append(names, fmt.Sprintf("%d = %s", v.Desc.Number(), v.Desc.Name()))
+		}
+
+		schema.Description = strings.Join(names, ", ")
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		schema = d.messageSchema(field.Message)
+	case protoreflect.StringKind:
+		schema = &openAPISchema{Type: "string"}
+	default:
+		schema = &openAPISchema{Type: "string"}
+	}
+
+	if field.Desc.IsList() {
+		return &openAPISchema{Type: "array", Items: schema}
+	}
+
+	return schema
+}
+
+// openAPIPath converts a httprouter path to an OpenAPI path and returns its variables.
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	vars := []string{}
+
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			vars = append(vars, s[1:])
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), vars
+}
+
+// genOpenAPI sets the OpenAPI documents of the services and generates
+// a _orb.openapi.json file with the merged document of all services.
+func genOpenAPI(gen *protogen.Plugin, file *protogen.File, services []serviceDesc) {
+	fileDoc := newOpenAPIDocument(string(file.Desc.Package()))
+
+	for i, service := range file.Services {
+		doc := buildOpenAPI(service, services[i])
+		fileDoc.merge(doc)
+
+		literal, err := marshalOpenAPI(doc)
+		if err != nil {
+			errMessage("Failed to encode the OpenAPI document of '%s': %v", service.Desc.FullName(), err)
+			continue
+		}
+
+		services[i].OpenAPI = literal
+	}
+
+	data, err := json.MarshalIndent(fileDoc, "", "  ")
+	if err != nil {
+		errMessage("Failed to encode the OpenAPI document of '%s': %v", file.Desc.Path(), err)
+		return
+	}
+
+	gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_orb.openapi.json", "").P(string(data))
+}
+
+// marshalOpenAPI returns the document as a Go string literal.
+func marshalOpenAPI(doc *openAPIDocument) (string, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(string(data), "`") {
+		return strconv.Quote(string(data)), nil
+	}
+
+	return "`" + string(data) + "`", nil
+}
+
+func findGoField(msg *protogen.Message, name string) *protogen.Field {
+	for _, f := range msg.Fields {
+		if string(f.Desc.Name()) == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func isDeprecated(desc protoreflect.MethodDescriptor) bool {
+	type deprecated interface{ GetDeprecated() bool }
+
+	d, ok := desc.Options().(deprecated)
+
+	return ok && d.GetDeprecated()
+}
+
+func comment(c protogen.Comments) string {
+	lines := strings.Split(strings.TrimSpace(string(c)), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
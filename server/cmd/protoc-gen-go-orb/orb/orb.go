@@ -55,12 +55,20 @@ func GenerateFile(gen *protogen.Plugin, file *protogen.File) *protogen.Generated
 
 	// Generate service code.
 	if len(file.Services) > 0 {
+		services := genServices(file, gFile)
+
+		serverHTTP := slices.Contains(Servers, "http")
+		if OpenAPI && serverHTTP {
+			genOpenAPI(gen, file, services)
+		}
+
 		mainTemplate := protoFile{
 			ServerGRPC:  slices.Contains(Servers, "grpc"),
 			ServerDRPC:  slices.Contains(Servers, "drpc"),
-			ServerHTTP:  slices.Contains(Servers, "http"),
+			ServerHTTP:  serverHTTP,
 			ServerHertz: slices.Contains(Servers, "hertz"),
-			Services:    genServices(file, gFile),
+			OpenAPI:     OpenAPI && serverHTTP,
+			Services:    services,
 		}.Render()
 
 		gFile.P(mainTemplate)
@@ -97,9 +105,11 @@ func genService(file *protogen.File, generated *protogen.GeneratedFile, service
 	}
 
 	for _, method := range service.Methods {
-		serviceDescription.AddMethod(
-			buildMethodDesc(generated, method, http.MethodPost,
-				fmt.Sprintf("/%s/%s", service.Desc.FullName(), method.Desc.Name())))
+		desc := buildMethodDesc(generated, method, http.MethodPost,
+			fmt.Sprintf("/%s/%s", service.Desc.FullName(), method.Desc.Name()))
+		desc.Bindings = buildRESTBindings(method)
+
+		serviceDescription.AddMethod(desc)
 	}
 
 	return serviceDescription
@@ -0,0 +1,223 @@
+package orb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// REST path template errors.
+var (
+	errCustomVerb      = errors.New("custom verbs are not supported")
+	errUnnamedWildcard = errors.New("wildcards have to be bound to a field")
+	errComplexVariable = errors.New("variables with a pattern are only supported as last segment")
+	errInvalidTemplate = errors.New("invalid path template")
+)
+
+// restBinding is a google.api.http binding of a method.
+type restBinding struct {
+	// Method is the HTTP method.
+	Method string
+	// Path is the route in httprouter syntax.
+	Path string
+	// Body is the body selector, "" for no body, "*" for the whole request message or a field name.
+	Body string
+}
+
+// buildRESTBindings reads the google.api.http annotation of a method including its additional bindings.
+func buildRESTBindings(method *protogen.Method) []restBinding {
+	rule, ok := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return nil
+	}
+
+	if method.Desc.IsStreamingClient() {
+		errMessage("Method '%s' is client-streaming, its google.api.http annotation is ignored", method.Desc.FullName())
+		return nil
+	}
+
+	rules := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+	result := make([]restBinding, 0, len(rules))
+
+	for _, r := range rules {
+		var verb, template string
+
+		switch p := r.GetPattern().(type) {
+		case *annotations.HttpRule_Get:
+			verb, template = http.MethodGet, p.Get
+		case *annotations.HttpRule_Put:
+			verb, template = http.MethodPut, p.Put
+		case *annotations.HttpRule_Post:
+			verb, template = http.MethodPost, p.Post
+		case *annotations.HttpRule_Delete:
+			verb, template = http.MethodDelete, p.Delete
+		case *annotations.HttpRule_Patch:
+			verb, template = http.MethodPatch, p.Patch
+		case *annotations.HttpRule_Custom:
+			verb, template = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+		default:
+			errMessage("Method '%s' has a google.api.http binding without a pattern", method.Desc.FullName())
+			continue
+		}
+
+		if r.GetResponseBody() != "" {
+			errMessage("Method '%s': response_body is not supported, the whole response is returned", method.Desc.FullName())
+		}
+
+		path, vars, err := restPath(template)
+		if err != nil {
+			errMessage("Method '%s': skipping '%s %s': %v", method.Desc.FullName(), verb, template, err)
+			continue
+		}
+
+		valid := true
+
+		for _, v := range vars {
+			if err := validateFieldPath(method.Input.Desc, v); err != nil {
+				errMessage("Method '%s': skipping '%s %s': %v", method.Desc.FullName(), verb, template, err)
+
+				valid = false
+			}
+		}
+
+		if body := r.GetBody(); body != "" && body != "*" {
+			if method.Input.Desc.Fields().ByName(protoreflect.Name(body)) == nil {
+				errMessage("Method '%s': skipping '%s %s': body field '%s' not found", method.Desc.FullName(), verb, template, body)
+
+				valid = false
+			}
+		}
+
+		if !valid {
+			continue
+		}
+
+		result = append(result, restBinding{Method: verb, Path: path, Body: r.GetBody()})
+	}
+
+	return result
+}
+
+// restPath converts a google.api.http path template to the httprouter syntax,
+// it returns the route and the field paths of its variables.
+//
+// "{name}" and "{name=*}" become ":name", a trailing "{name=**}" or any other
+// trailing pattern becomes the catch-all "*name".
+func restPath(template string) (string, []string, error) {
+	if !strings.HasPrefix(template, "/") {
+		return "", nil, fmt.Errorf("%w: '%s' has to start with '/'", errInvalidTemplate, template)
+	}
+
+	segments, err := splitTemplate(template[1:])
+	if err != nil {
+		return "", nil, err
+	}
+
+	last := segments[len(segments)-1]
+	if idx := strings.LastIndex(last, ":"); idx >= 0 && !strings.HasSuffix(last, "}") {
+		return "", nil, fmt.Errorf("%w: '%s'", errCustomVerb, last[idx:])
+	}
+
+	vars := []string{}
+	result := make([]string, 0, len(segments))
+
+	for i, seg := range segments {
+		switch {
+		case seg == "*" || seg == "**":
+			return "", nil, errUnnamedWildcard
+		case strings.HasPrefix(seg, "{"):
+			field, pattern, _ := strings.Cut(strings.TrimSuffix(seg[1:], "}"), "=")
+			field = strings.TrimSpace(field)
+
+			if field == "" {
+				return "", nil, fmt.Errorf("%w: '%s'", errInvalidTemplate, seg)
+			}
+
+			vars = append(vars, field)
+
+			switch {
+			case pattern == "" || pattern == "*":
+				result = append(result, ":"+field)
+			case i == len(segments)-1:
+				result = append(result, "*"+field)
+			default:
+				return "", nil, fmt.Errorf("%w: '%s'", errComplexVariable, seg)
+			}
+		default:
+			result = append(result, seg)
+		}
+	}
+
+	return "/" + strings.Join(result, "/"), vars, nil
+}
+
+// splitTemplate splits a path template into its segments, variables are kept as a single segment.
+func splitTemplate(template string) ([]string, error) {
+	segments := []string{}
+	depth := 0
+	start := 0
+
+	for i, c := range template {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				segments = append(segments, template[start:i])
+				start = i + 1
+			}
+		}
+
+		if depth < 0 || depth > 1 {
+			return nil, fmt.Errorf("%w: unbalanced braces in '%s'", errInvalidTemplate, template)
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unbalanced braces in '%s'", errInvalidTemplate, template)
+	}
+
+	segments = append(segments, template[start:])
+
+	for _, s := range segments {
+		if s == "" {
+			return nil, fmt.Errorf("%w: empty segment in '/%s'", errInvalidTemplate, template)
+		}
+	}
+
+	return segments, nil
+}
+
+// validateFieldPath checks that the dotted field path exists in the message and ends at a scalar.
+func validateFieldPath(msg protoreflect.MessageDescriptor, path string) error {
+	names := strings.Split(path, ".")
+
+	for i, name := range names {
+		fd := msg.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("field '%s' not found in '%s'", path, msg.FullName())
+		}
+
+		if fd.IsMap() || fd.IsList() {
+			return fmt.Errorf("field '%s' shouldn't be a map or a list", path)
+		}
+
+		if i < len(names)-1 {
+			if fd.Message() == nil {
+				return fmt.Errorf("field '%s' is not a message", strings.Join(names[:i+1], "."))
+			}
+
+			msg = fd.Message()
+		}
+	}
+
+	return nil
+}
@@ -76,7 +76,7 @@ func New{{.Type}}Client(client client.Client) *{{.Type}}Client {
 }
 
 {{- $service := .}}{{ range .Methods }}
-{{- if not .ClientStreaming }}
+{{- if not (or .ClientStreaming .ServerStreaming) }}
 // {{.Name}} requests {{.Name}}.
 func (c *{{$service.Type}}Client) {{.Name}}(ctx context.Context, service string, req *{{.Request}}, opts ...client.CallOption) (*{{.Reply}}, error) {
 	return client.Request[{{.Reply}}](ctx, c.client, service, Endpoint{{$service.Type}}{{.Name}}, req, opts...)
@@ -87,12 +87,24 @@ func (c *{{$service.Type}}Client) {{.Name}}(ctx context.Context, service string,
 	return client.Stream[*{{.Request}}, *{{.Reply}}](ctx, c.client, service, Endpoint{{$service.Type}}{{.Name}}, opts...)
 }
 {{- else if and (not .ClientStreaming) .ServerStreaming }}
-// {{.Name}} creates a server-streaming connection to {{.Name}}.
+// {{.Name}} creates a server-streaming connection to {{.Name}}, it sends req and closes the send direction.
 func (c *{{$service.Type}}Client) {{.Name}}(ctx context.Context, service string, req *{{.Request}}, opts ...client.CallOption) (client.StreamIface[*{{.Request}}, *{{.Reply}}], error) {
-	streamReq := &client.StreamReq[*{{.Request}}, *{{.Reply}}]{
-		ReqBody: req,
+	stream, err := client.Stream[*{{.Request}}, *{{.Reply}}](ctx, c.client, service, Endpoint{{$service.Type}}{{.Name}}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Send(req); err != nil {
+		stream.Close() //nolint:errcheck
+		return nil, err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		stream.Close() //nolint:errcheck
+		return nil, err
 	}
-	return c.client.Stream(ctx, streamReq, append(opts, client.WithEndpoint(Endpoint{{$service.Type}}{{.Name}}), client.WithService(service))...)
+
+	return stream, nil
 }
 {{- else }}
 // {{.Name}} creates a bidirectional-streaming connection to {{.Name}}.
@@ -105,7 +117,7 @@ func (c *{{$service.Type}}Client) {{.Name}}(ctx context.Context, service string,
 // {{.Type}}Handler is the Handler for {{.Name}}
 type {{.Type}}Handler interface {
 	{{- range .Methods }}
-	{{- if not .ClientStreaming }}
+	{{- if not (or .ClientStreaming .ServerStreaming) }}
 	{{.Name}}(ctx context.Context, req *{{.Request}}) (*{{.Reply}}, error)
 	{{- else if and .ClientStreaming (not .ServerStreaming) }}
 	{{.Name}}(stream {{$service.Type}}{{.Name}}Stream) error
@@ -145,7 +157,7 @@ type orbGRPC{{.Type}} struct {
 }
 
 {{- $service := .}}{{ range .Methods }}
-{{- if not .ClientStreaming }}
+{{- if not (or .ClientStreaming .ServerStreaming) }}
 // {{.Name}} implements the {{$service.Type}}Server interface by adapting to the {{$service.Type}}Handler.
 func (s *orbGRPC{{$service.Type}}) {{.Name}}(ctx context.Context, req *{{.Request}}) (*{{.Reply}}, error) {
 	return s.handler.{{.Name}}(ctx, req)
@@ -294,7 +306,7 @@ type orbDRPC{{.Type}}Handler struct {
 }
 
 {{- $service := .}}{{ range .Methods }}
-{{- if not .ClientStreaming }}
+{{- if not (or .ClientStreaming .ServerStreaming) }}
 // {{.Name}} implements the DRPC{{$service.Type}}Server interface by adapting to the {{$service.Type}}Handler.
 func (w *orbDRPC{{$service.Type}}Handler) {{.Name}}(ctx context.Context, req *{{.Request}}) (*{{.Reply}}, error) {
 	return w.handler.{{.Name}}(ctx, req)
@@ -416,14 +428,47 @@ func register{{.Type}}MemoryHandler(srv *memory.Server, handler {{.Type}}Handler
 
 {{- if $.ServerHTTP }}
 
+{{- if $.OpenAPI }}
+
+// OpenAPI{{.Type}} is the OpenAPI v3 document of {{.Name}}.
+const OpenAPI{{.Type}} = {{.OpenAPI}}
+{{- end }}
+
 // register{{.Type}}HTTPHandler registers the service to an HTTP server.
 func register{{.Type}}HTTPHandler(srv *mhttp.Server, handler {{.Type}}Handler) {
-	{{- range .Methods}}
+	{{- if $.OpenAPI }}
+	if err := srv.AddOpenAPI(OpenAPI{{.Type}}); err != nil {
+		log.Warn("Failed to add the OpenAPI document", "handler", Handler{{.Type}}, "error", err)
+	}
+	{{ end }}
+	{{- range $method := .Methods}}
 	{{- if not (or .ClientStreaming .ServerStreaming) }}
 	srv.Router().{{.Method}}("{{.Path}}", mhttp.NewGRPCHandler(srv, handler.{{.Name}}, Handler{{$service.Type}}, "{{.Name}}"))
+	{{- range .Bindings }}
+	if err := srv.Router().Handle("{{.Method}}", "{{.Path}}", mhttp.NewRESTHandler(srv, handler.{{$method.Name}}, Handler{{$service.Type}}, "{{$method.Name}}", "{{.Body}}")); err != nil {
+		log.Error("Failed to register a route", "handler", Handler{{$service.Type}}, "error", err)
+	}
+	{{- end }}
+	{{- else if not .ClientStreaming }}
+	stream{{.Name}} := func(req *{{.Request}}, stream mhttp.ServerStream[{{.Reply}}]) error {
+		return handler.{{.Name}}(req, stream)
+	}
+	srv.Router().Post("{{.Path}}", mhttp.NewServerStreamHandler(srv, stream{{.Name}}, Handler{{$service.Type}}, "{{.Name}}"))
+	if err := srv.Router().Handle("GET", "{{.Path}}", mhttp.NewServerStreamHandler(srv, stream{{.Name}}, Handler{{$service.Type}}, "{{.Name}}")); err != nil {
+		log.Error("Failed to register a route", "handler", Handler{{$service.Type}}, "error", err)
+	}
+	{{- range .Bindings }}
+	if err := srv.Router().Handle("{{.Method}}", "{{.Path}}", mhttp.NewRESTServerStreamHandler(srv, stream{{$method.Name}}, Handler{{$service.Type}}, "{{$method.Name}}", "{{.Body}}")); err != nil {
+		log.Error("Failed to register a route", "handler", Handler{{$service.Type}}, "error", err)
+	}
+	{{- end }}
 	{{- else }}
-	// HTTP transport does not support streaming for {{.Name}}
-	log.Warn("Streaming endpoint not registered with HTTP transport", "endpoint", "{{.Path}}")
+	stream{{.Name}} := func(stream mhttp.WebSocketStream[{{.Request}}, {{.Reply}}]) error {
+		return handler.{{.Name}}(stream)
+	}
+	if err := srv.Router().Handle("GET", "{{.Path}}", mhttp.NewWebSocketHandler(srv, stream{{.Name}}, Handler{{$service.Type}}, "{{.Name}}")); err != nil {
+		log.Error("Failed to register a route", "handler", Handler{{$service.Type}}, "error", err)
+	}
 	{{- end }}
 	{{- end }}
 }
@@ -483,6 +528,7 @@ type protoFile struct {
 	ServerDRPC  bool
 	ServerHTTP  bool
 	ServerHertz bool
+	OpenAPI     bool
 	Services    []serviceDesc
 	PackageName string
 	SourceFile  string
@@ -514,6 +560,8 @@ type serviceDesc struct {
 	Name      string
 	Metadata  string
 	Methods   []methodDesc
+	// OpenAPI is the OpenAPI document of the service as Go string literal.
+	OpenAPI string
 }
 
 // methodDesc describes a service method.
@@ -528,6 +576,8 @@ type methodDesc struct {
 	Path            string
 	Method          string
 	MethodUpper     string
+	// Bindings are the google.api.http bindings of the method.
+	Bindings []restBinding
 }
 
 func (m *methodDesc) String() string {
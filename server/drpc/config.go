@@ -1,10 +1,14 @@
 package drpc
 
 import (
+	"crypto/tls"
 	"net"
+	"time"
 
+	"github.com/go-orb/go-orb/config"
 	"github.com/go-orb/go-orb/log"
 	"github.com/go-orb/go-orb/server"
+	mtls "github.com/go-orb/go-orb/util/tls"
 )
 
 const (
@@ -30,6 +34,42 @@ type Config struct {
 	// specific interface, but with a random port, you can use '<IP>:0'.
 	Address string `json:"address" yaml:"address"`
 
+	// Region is published to the registry with this entrypoint,
+	// clients use it for locality-aware routing.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Zone is published to the registry as the node's "zone" metadata,
+	// clients use it for locality-aware routing.
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+
+	// DrainDelay is the time between deregistering and closing the listener on
+	// Stop. It gives the registry time to propagate. Defaults to 0.
+	DrainDelay config.Duration `json:"drainDelay,omitempty" yaml:"drainDelay,omitempty"`
+
+	// DrainTimeout is the maximum time Stop waits for in-flight RPCs and streams
+	// after closing the listener, the remaining connections get closed afterwards.
+	// Defaults to 0, which waits until the stop context is done.
+	DrainTimeout config.Duration `json:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty"`
+
+	// TLS config, if set the entrypoint serves "drpcs". Without a certificate a
+	// self-signed certificate will be generated.
+	//
+	// You can load a tls config from yaml/json with the following options:
+	//
+	// ```yaml
+	// clientCAFiles:
+	//    - xxx
+	// clientAuth: "none" | "request" | "require" |  "verify" | "require+verify"
+	// certificates:
+	//   - certFile: xxx
+	//     keyFile: xxx
+	// ```
+	TLS *mtls.Config `json:"tls,omitempty" yaml:"tls,omitempty"`
+
+	// Middlewares are the middlewares of this entrypoint, they get created from
+	// server.Middlewares and get appended to the global server middlewares.
+	Middlewares []server.MiddlewareConfig `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+
 	// Logger allows you to dynamically change the log level and plugin for a
 	// specific entrypoint.
 	Logger log.Config `json:"logger" yaml:"logger"`
@@ -114,3 +154,82 @@ func WithLogPlugin(plugin string) server.Option {
 		}
 	}
 }
+
+// WithRegion sets the region this entrypoint advertises in the registry.
+func WithRegion(region string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Region = region
+		}
+	}
+}
+
+// WithZone sets the zone this entrypoint advertises in the registry.
+func WithZone(zone string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Zone = zone
+		}
+	}
+}
+
+// WithDrain sets the delay before the listener gets closed on Stop, and the
+// maximum time to wait for in-flight RPCs afterwards.
+func WithDrain(delay, timeout time.Duration) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.DrainDelay = config.Duration(delay)
+			cfg.DrainTimeout = config.Duration(timeout)
+		}
+	}
+}
+
+// WithTLS serves "drpcs" with the TLS config, nil serves a self-signed certificate.
+func WithTLS(config *tls.Config) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.Config = config
+		}
+	}
+}
+
+// WithTLSFiles serves "drpcs" with the certificate and key from files.
+func WithTLSFiles(certFile, keyFile string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.ConfigFiles.Certificates = append(cfg.TLS.ConfigFiles.Certificates, struct {
+				CertFile string `json:"certFile" yaml:"certFile"`
+				KeyFile  string `json:"keyFile"  yaml:"keyFile"`
+			}{CertFile: certFile, KeyFile: keyFile})
+		}
+	}
+}
+
+// WithMTLS serves "drpcs", requires client certificates and verifies them against the CAs in
+// clientCAFiles, handlers get the identity of the client in the incoming metadata.
+func WithMTLS(clientCAFiles ...string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.ConfigFiles.ClientCAFiles = append(cfg.TLS.ConfigFiles.ClientCAFiles, clientCAFiles...)
+			cfg.TLS.ConfigFiles.ClientAuth = mtls.ClientAuth{ClientAuthType: tls.RequireAndVerifyClientCert}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package drpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+)
+
+// serve accepts connections until the listener gets closed and serves them
+// until the server context is done, it returns when all connections are closed.
+//
+// drpcserver.Serve closes the open connections when the listener gets closed,
+// serve keeps them open to drain the in-flight RPCs.
+func (s *Server) serve(listener net.Listener) {
+	var conns sync.WaitGroup
+	defer conns.Wait()
+
+	stop := context.AfterFunc(s.ctx, func() { _ = listener.Close() }) //nolint:errcheck
+	defer stop()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Error("while accepting dRPC connections", "error", err)
+			}
+
+			return
+		}
+
+		conns.Add(1)
+
+		go func() {
+			defer conns.Done()
+
+			if err := s.server.ServeOne(s.ctx, conn); err != nil && s.ctx.Err() == nil {
+				s.logger.Debug("dRPC connection closed", "error", err)
+			}
+		}()
+	}
+}
@@ -3,12 +3,15 @@ package drpc
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+	"sync/atomic"
+	"time"
 
 	"storj.io/drpc/drpcserver"
 
@@ -17,8 +20,13 @@ import (
 	"github.com/go-orb/go-orb/registry"
 	orbserver "github.com/go-orb/go-orb/server"
 	"github.com/go-orb/go-orb/util/addr"
+	mtls "github.com/go-orb/go-orb/util/tls"
 
 	"github.com/lithammer/shortuuid/v4"
+
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
+	"github.com/go-orb/plugins/server/middleware/recovery"
+	"github.com/go-orb/plugins/server/srvutil"
 )
 
 var _ orbserver.Entrypoint = (*Server)(nil)
@@ -51,12 +59,26 @@ type Server struct {
 
 	endpoints []string
 
-	started bool
+	// recovery recovers panics of the handlers and the middlewares.
+	recovery *recovery.Middleware
+
+	// activeRequests are the RPCs in-flight.
+	activeRequests atomic.Int64
+
+	// listener is closed first on Stop, serveDone is closed when all connections are closed.
+	listener  net.Listener
+	serveDone chan struct{}
+
+	// tlsLoaded is set once the TLS files have been loaded.
+	tlsLoaded bool
+
+	started  atomic.Bool
+	draining atomic.Bool
 }
 
 // Start will create the listeners and start the server on the entrypoint.
 func (s *Server) Start(ctx context.Context) error {
-	if s.started {
+	if s.started.Load() {
 		return nil
 	}
 
@@ -88,6 +110,16 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}
 
+	if s.config.TLS != nil {
+		if err := s.setupTLS(); err != nil {
+			_ = listener.Close() //nolint:errcheck
+
+			return err
+		}
+
+		listener = tls.NewListener(listener, s.config.TLS.Config)
+	}
+
 	s.address = listener.Addr().String()
 
 	s.logger = s.logger.With(slog.String("transport", s.Transport()), slog.String("address", s.address))
@@ -95,28 +127,60 @@ func (s *Server) Start(ctx context.Context) error {
 	s.logger.Info("dRPC server listening")
 
 	s.ctx, s.cancelFunc = context.WithCancel(ctx)
+	s.listener = listener
+	s.serveDone = make(chan struct{})
 
 	go func() {
-		if err := s.server.Serve(s.ctx, listener); err != nil {
-			s.logger.Error("while starting the dRPC Server", "error", err)
-		}
+		defer close(s.serveDone)
+
+		s.serve(listener)
 	}()
 
-	s.started = true
+	s.started.Store(true)
 
 	return s.registryRegister(ctx)
 }
 
-// Stop will stop the dRPC server.
+// Stop drains and stops the dRPC server.
+//
+// It deregisters the entrypoint, fails its health, waits DrainDelay for that to
+// propagate, stops accepting connections and waits for in-flight RPCs and streams
+// up to DrainTimeout before closing the remaining connections.
 func (s *Server) Stop(ctx context.Context) error {
-	if !s.started {
+	if !s.started.Load() {
 		return nil
 	}
 
-	// Stops the dRPC Server.
+	err := s.registryDeregister(ctx)
+	if err != nil {
+		s.logger.Error("failed to deregister the dRPC server", "error", err)
+	}
+
+	s.draining.Store(true)
+	defer s.draining.Store(false)
+
+	srvutil.SleepContext(ctx, time.Duration(s.config.DrainDelay))
+
+	drainCtx, cancel := srvutil.DrainContext(ctx, time.Duration(s.config.DrainTimeout))
+	defer cancel()
+
+	_ = s.listener.Close() //nolint:errcheck
+
+	if !srvutil.WaitIdle(drainCtx, &s.activeRequests) {
+		s.logger.Warn("Closed the dRPC server before all RPCs finished", "active", s.activeRequests.Load())
+	}
+
+	// Stops the dRPC Server and closes the connections.
 	s.cancelFunc()
 
-	return s.registryDeregister(ctx)
+	select {
+	case <-s.serveDone:
+	case <-ctx.Done():
+	}
+
+	s.started.Store(false)
+
+	return err
 }
 
 // AddHandler adds a handler for later registration.
@@ -126,7 +190,7 @@ func (s *Server) AddHandler(handler orbserver.RegistrationFunc) {
 
 // Register executes a registration function on the entrypoint.
 func (s *Server) Register(register orbserver.RegistrationFunc) {
-	if !s.started {
+	if !s.started.Load() {
 		return
 	}
 
@@ -146,20 +210,90 @@ func (s *Server) Network() string {
 
 // Address returns the address the entrypoint is listening on, for example: [::]:8381.
 func (s *Server) Address() string {
-	if !s.started {
+	if !s.started.Load() {
 		return ""
 	}
 
 	return s.address
 }
 
-// Transport returns the client transport to use: "drpc".
+// Transport returns the client transport to use: "drpc", or "drpcs" with TLS.
 func (s *Server) Transport() string {
+	transport := "drpc"
+	if s.config.TLS != nil {
+		transport = "drpcs"
+	}
+
 	if s.config.Network == "unix" {
-		return "unix+drpc"
+		return "unix+" + transport
 	}
 
-	return "drpc"
+	return transport
+}
+
+// tlsHosts returns the hosts of self-signed certificates, unix sockets have none.
+func (s *Server) tlsHosts() []string {
+	if s.config.Network == "unix" {
+		return nil
+	}
+
+	return []string{s.config.Address}
+}
+
+// setupTLS completes the TLS config and loads its files.
+func (s *Server) setupTLS() error {
+	if s.tlsLoaded {
+		return nil
+	}
+
+	files := s.config.TLS.ConfigFiles
+
+	if utls.FilesFromConfig(files).Empty() {
+		if s.config.TLS.Config != nil {
+			return nil
+		}
+
+		config, err := mtls.GenTLSConfig(s.tlsHosts()...)
+		if err != nil {
+			return fmt.Errorf("failed to generate self signed certificate: %w", err)
+		}
+
+		s.config.TLS.Config = config
+
+		return nil
+	}
+
+	var base *tls.Config
+
+	switch {
+	case s.config.TLS.Config != nil:
+		base = s.config.TLS.Config.Clone()
+	case len(files.Certificates) == 0:
+		// Only client CAs, generate a self signed certificate.
+		config, err := mtls.GenTLSConfig(s.tlsHosts()...)
+		if err != nil {
+			return fmt.Errorf("failed to generate self signed certificate: %w", err)
+		}
+
+		base = config
+	default:
+		base = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if files.ClientAuth.ClientAuthType != tls.NoClientCert {
+		base.ClientAuth = files.ClientAuth.ClientAuthType
+	}
+
+	// The reloader only loads the files here, it doesn't watch them.
+	loader, err := utls.NewReloader(utls.FilesFromConfig(files), utls.WithReloadLogger(s.logger))
+	if err != nil {
+		return fmt.Errorf("failed to load the TLS files: %w", err)
+	}
+
+	s.tlsLoaded = true
+	s.config.TLS = &mtls.Config{ConfigFiles: files, Config: loader.ServerConfig(base)}
+
+	return nil
 }
 
 // String returns the entrypoint type.
@@ -167,6 +301,16 @@ func (s *Server) String() string {
 	return Plugin
 }
 
+// Health returns srvutil.ErrNotStarted while the entrypoint isn't serving and srvutil.ErrDraining while it stops.
+func (s *Server) Health(_ context.Context) error {
+	return srvutil.Health(s.started.Load(), s.draining.Load())
+}
+
+// Panics returns the number of panics recovered by the entrypoint, e.g. for alerting.
+func (s *Server) Panics() uint64 {
+	return s.recovery.Panics()
+}
+
 // Enabled returns if this entrypoint has been enbaled in config.
 func (s *Server) Enabled() bool {
 	return s.config.Enabled
@@ -188,15 +332,22 @@ func (s *Server) Router() *Mux {
 }
 
 func (s *Server) registryService() registry.ServiceNode {
-	return registry.ServiceNode{
+	node := registry.ServiceNode{
 		Name:     s.serviceName,
 		Version:  s.serviceVersion,
 		Address:  s.Address(),
 		Node:     s.id,
 		Network:  s.Network(),
 		Scheme:   s.Transport(),
+		Region:   s.config.Region,
 		Metadata: make(map[string]string),
 	}
+
+	if s.config.Zone != "" {
+		node.Metadata[srvutil.MetadataZone] = s.config.Zone
+	}
+
+	return node
 }
 
 func (s *Server) registryRegister(ctx context.Context) error {
@@ -224,6 +375,13 @@ func Provide(
 		return nil, err
 	}
 
+	mws, err := srvutil.Middlewares(cfg.Middlewares, configData, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.OptMiddlewares = append(cfg.OptMiddlewares, mws...)
+
 	return New(name, version, epName, cfg, logger, reg)
 }
 
@@ -257,6 +415,7 @@ func New(name string, version string, epName string, acfg any, logger log.Logger
 		config:         cfg,
 		logger:         logger,
 		registry:       reg,
+		recovery:       recovery.New(recovery.NewConfig(), logger),
 		handlers:       cfg.OptHandlers,
 		middlewares:    cfg.OptMiddlewares,
 		ctx:            ctx,
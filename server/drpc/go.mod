@@ -4,6 +4,9 @@ go 1.23.6
 
 require (
 	github.com/go-orb/go-orb v0.4.1
+	github.com/go-orb/plugins/server/http v0.3.1
+	github.com/go-orb/plugins/server/middleware/recovery v0.1.0
+	github.com/go-orb/plugins/server/srvutil v0.1.0
 	github.com/lithammer/shortuuid/v4 v4.2.0
 	github.com/zeebo/errs v1.4.0
 	google.golang.org/protobuf v1.36.5
@@ -6,6 +6,7 @@ package drpc
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -15,11 +16,14 @@ import (
 	"github.com/go-orb/go-orb/util/metadata"
 	"github.com/go-orb/go-orb/util/orberrors"
 	"github.com/go-orb/plugins/server/drpc/message"
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
+	"github.com/go-orb/plugins/server/srvutil"
 	"github.com/zeebo/errs"
 	proto "google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"storj.io/drpc"
+	"storj.io/drpc/drpcctx"
 	"storj.io/drpc/drpcerr"
 	"storj.io/drpc/drpcmetadata"
 )
@@ -80,8 +84,44 @@ func (s *streamWrapper) Close() error {
 	return s.stream.Close()
 }
 
+// contentEncoder returns the encoder for the content type of the stream, it defaults to proto.
+func contentEncoder(stream drpc.Stream) (drpc.Encoding, error) {
+	contentType := codecs.MimeProto
+
+	if dMeta, ok := drpcmetadata.Get(stream.Context()); ok {
+		contentType = dMeta["Content-Type"]
+	}
+
+	codec, err := codecs.GetMime(contentType)
+	if err != nil {
+		return nil, drpcerr.WithCode(fmt.Errorf("invalid content type: %q", contentType), http.StatusInternalServerError)
+	}
+
+	return &encoder{codec: codec}, nil
+}
+
+// peerTLSState returns the TLS state of the connection of the stream.
+func peerTLSState(ctx context.Context) *tls.ConnectionState {
+	tr, ok := drpcctx.Transport(ctx)
+	if !ok {
+		return nil
+	}
+
+	conn, ok := tr.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+
+	state := conn.ConnectionState()
+
+	return &state
+}
+
 // HandleRPC handles the rpc that has been requested by the stream.
 func (m *Mux) HandleRPC(stream drpc.Stream, rpc string) (err error) {
+	m.orbSrv.activeRequests.Add(1)
+	defer m.orbSrv.activeRequests.Add(-1)
+
 	data, rpcOK := m.rpcs[rpc]
 	if !rpcOK {
 		return drpc.ProtocolError.New("unknown rpc: %q", rpc)
@@ -99,6 +139,8 @@ func (m *Mux) HandleRPC(stream drpc.Stream, rpc string) (err error) {
 		}
 	}
 
+	utls.SetPeerMetadata(reqMd, peerTLSState(ctx))
+
 	fmSplit := strings.Split(rpc, "/")
 
 	if len(fmSplit) >= 3 {
@@ -106,32 +148,19 @@ func (m *Mux) HandleRPC(stream drpc.Stream, rpc string) (err error) {
 		reqMd[metadata.Method] = fmSplit[2]
 	}
 
-	var req interface{}
-
-	if data.in1 != streamType {
-		// Check for content type in metadata
-		ctx := stream.Context()
-		dMeta, hasMeta := drpcmetadata.Get(ctx)
-		contentType := codecs.MimeProto
-
-		if hasMeta {
-			contentType = dMeta["Content-Type"]
-		}
-
-		codec, err := codecs.GetMime(contentType)
-		if err != nil {
-			return drpcerr.WithCode(fmt.Errorf("invalid content type: %q", contentType), http.StatusInternalServerError)
-		}
+	if data.in1 == streamType || data.in2 == streamType {
+		return m.handleStream(ctx, stream, data)
+	}
 
-		msg := reflect.New(data.in1.Elem()).Interface()
+	enc, err := contentEncoder(stream)
+	if err != nil {
+		return err
+	}
 
-		if err := stream.MsgRecv(msg, &encoder{codec: codec}); err != nil {
-			return errs.Wrap(err)
-		}
+	req := reflect.New(data.in1.Elem()).Interface()
 
-		req = msg
-	} else {
-		req = &streamWrapper{stream: stream, ctx: ctx}
+	if err := stream.MsgRecv(req, enc); err != nil {
+		return errs.Wrap(err)
 	}
 
 	// Apply middleware.
@@ -143,6 +172,8 @@ func (m *Mux) HandleRPC(stream drpc.Stream, rpc string) (err error) {
 		h = m.Call(h)
 	}
 
+	h = m.orbSrv.recovery.Call(h)
+
 	// Calls all middlewares until the actual RPC.
 	out, err := h(ctx, req)
 
@@ -163,3 +194,87 @@ func (m *Mux) HandleRPC(stream drpc.Stream, rpc string) (err error) {
 		return stream.CloseSend()
 	}
 }
+
+// orbStream is the innermost ServerStream of the stream middlewares.
+type orbStream struct {
+	*streamWrapper
+
+	recvEnc drpc.Encoding
+	sendEnc drpc.Encoding
+}
+
+func (s *orbStream) SendMsg(msg any) error {
+	return s.MsgSend(msg, s.sendEnc)
+}
+
+func (s *orbStream) RecvMsg(msg any) error {
+	return s.MsgRecv(msg, s.recvEnc)
+}
+
+// middlewareStream is the drpc.Stream for the RPC, it passes the messages
+// through the stream middlewares.
+type middlewareStream struct {
+	drpc.Stream
+
+	stream ServerStream
+	ctx    context.Context
+}
+
+func (s *middlewareStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *middlewareStream) MsgSend(msg drpc.Message, _ drpc.Encoding) error {
+	return s.stream.SendMsg(msg)
+}
+
+func (s *middlewareStream) MsgRecv(msg drpc.Message, _ drpc.Encoding) error {
+	return s.stream.RecvMsg(msg)
+}
+
+// handleStream handles a streaming RPC, it runs the stream middlewares.
+func (m *Mux) handleStream(ctx context.Context, stream drpc.Stream, data rpcData) error {
+	recvEnc, err := contentEncoder(stream)
+	if err != nil {
+		return err
+	}
+
+	wrapper := &streamWrapper{stream: stream, ctx: ctx}
+
+	h := func(ctx context.Context, ss ServerStream) error {
+		mStream := &middlewareStream{Stream: wrapper, stream: ss, ctx: ctx}
+
+		var in1, in2 any = mStream, nil
+
+		// Unitary input, stream output.
+		if data.in1 != streamType {
+			msg := reflect.New(data.in1.Elem()).Interface()
+			if err := ss.RecvMsg(msg); err != nil {
+				return errs.Wrap(err)
+			}
+
+			in1, in2 = msg, mStream
+		}
+
+		out, err := data.receiver(data.srv, ctx, in1, in2)
+		if err != nil {
+			return err
+		}
+
+		if out != nil {
+			return ss.SendMsg(out)
+		}
+
+		return nil
+	}
+
+	err = m.orbSrv.recovery.Stream(srvutil.ChainStream(m.orbSrv.middlewares, h))(ctx, &orbStream{streamWrapper: wrapper, recvEnc: recvEnc, sendEnc: data.enc})
+	if err != nil {
+		orbE := orberrors.From(err)
+		drpcE := drpcerr.WithCode(orbE, uint64(orbE.Code)) //nolint:gosec
+
+		return errs.Wrap(drpcE)
+	}
+
+	return stream.CloseSend()
+}
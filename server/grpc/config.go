@@ -9,7 +9,9 @@ import (
 
 	"github.com/go-orb/go-orb/config"
 	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/metrics"
 	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/types"
 	mtls "github.com/go-orb/go-orb/util/tls"
 )
 
@@ -31,6 +33,15 @@ const (
 
 	// DefaultTimeout is set to 5s.
 	DefaultTimeout = time.Second * 5
+
+	// DefaultHealthInterval is the interval the health status gets updated in.
+	DefaultHealthInterval = time.Second * 5
+
+	// DefaultHealthTimeout is the timeout of all checks of one update.
+	DefaultHealthTimeout = time.Second * 5
+
+	// DefaultTLSReloadInterval is the interval the TLS files get checked for changes in.
+	DefaultTLSReloadInterval = time.Second * 10
 )
 
 // Config provides options to the gRPC entrypoint.
@@ -76,6 +87,10 @@ type Config struct {
 	// ```
 	TLS *mtls.Config `json:"tls,omitempty" yaml:"tls,omitempty"`
 
+	// TLSReload watches the certificate, key and CA files of TLS and swaps them
+	// without a restart when they change.
+	TLSReload TLSReloadConfig `json:"tlsReload" yaml:"tlsReload"`
+
 	// GRPCOptions are options provided by the grpc package, and will be directly
 	// passed ot the gRPC server.
 	GRPCOptions []grpc.ServerOption `json:"-" yaml:"-"`
@@ -87,6 +102,30 @@ type Config struct {
 	// This is useful for healthprobes, such as in Kubernetes (>=1.24).
 	HealthService bool `json:"health" yaml:"health"`
 
+	// HealthInterval is the interval the health status of the services gets
+	// updated in. Defaults to 5s.
+	HealthInterval config.Duration `json:"healthInterval" yaml:"healthInterval"`
+
+	// HealthTimeout is the timeout of all checks of one update. Defaults to 5s.
+	HealthTimeout config.Duration `json:"healthTimeout" yaml:"healthTimeout"`
+
+	// OptHealthComponents are the components whose state the health service reports.
+	OptHealthComponents *types.Components `json:"-" yaml:"-"`
+
+	// OptHealthChecks are additional checks by name, checks named after a
+	// gRPC service only affect that service.
+	OptHealthChecks map[string]HealthCheck `json:"-" yaml:"-"`
+
+	// DrainDelay is the time between deregistering and setting the services to
+	// NOT_SERVING, and closing the listener on Stop. It gives the registry and
+	// load balancers time to stop sending requests. Defaults to 0.
+	DrainDelay config.Duration `json:"drainDelay,omitempty" yaml:"drainDelay,omitempty"`
+
+	// DrainTimeout is the maximum time Stop waits for in-flight RPCs and streams
+	// after closing the listener, the remaining connections get closed afterwards.
+	// Defaults to 0, which waits until the stop context is done.
+	DrainTimeout config.Duration `json:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty"`
+
 	// Reflection dictates whether the server should implementent gRPC
 	// reflection. This is used by e.g. the gRPC proxy. Defaults to true.
 	Reflection bool `json:"reflection" yaml:"reflection"`
@@ -101,11 +140,35 @@ type Config struct {
 	// the address and TLS config.
 	Listener net.Listener `json:"-" yaml:"-"`
 
+	// Region is published to the registry with this entrypoint,
+	// clients use it for locality-aware routing.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Zone is published to the registry as the node's "zone" metadata,
+	// clients use it for locality-aware routing.
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+
+	// Middlewares are the middlewares of this entrypoint, they get created from
+	// server.Middlewares and get appended to the global server middlewares.
+	Middlewares []server.MiddlewareConfig `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+
 	// Logger allows you to dynamically change the log level and plugin for a
 	// specific entrypoint.
 	Logger log.Config `json:"logger" yaml:"logger"`
 }
 
+// TLSReloadConfig configures reloading of the TLS files.
+type TLSReloadConfig struct {
+	// Enabled watches the files. Defaults to false.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Interval the files get checked for changes in. Defaults to 10s.
+	Interval config.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// OptMetrics reports the days until the certificates expire.
+	OptMetrics metrics.Metrics `json:"-" yaml:"-"`
+}
+
 // NewConfig will create a new default config for the entrypoint.
 func NewConfig(options ...server.Option) *Config {
 	cfg := &Config{
@@ -113,12 +176,17 @@ func NewConfig(options ...server.Option) *Config {
 			Plugin:  Plugin,
 			Enabled: true,
 		},
-		Network:       DefaultNetwork,
-		Address:       DefaultAddress,
-		Timeout:       config.Duration(DefaultTimeout),
-		HealthService: DefaultHealthService,
-		Reflection:    DefaultgRPCReflection,
-		Insecure:      DefaultInsecure,
+		Network:        DefaultNetwork,
+		Address:        DefaultAddress,
+		Timeout:        config.Duration(DefaultTimeout),
+		HealthService:  DefaultHealthService,
+		HealthInterval: config.Duration(DefaultHealthInterval),
+		HealthTimeout:  config.Duration(DefaultHealthTimeout),
+		Reflection:     DefaultgRPCReflection,
+		Insecure:       DefaultInsecure,
+		TLSReload: TLSReloadConfig{
+			Interval: config.Duration(DefaultTLSReloadInterval),
+		},
 	}
 
 	for _, option := range options {
@@ -187,7 +255,69 @@ func WithTLS(config *tls.Config) server.Option {
 	return func(c server.EntrypointConfigType) {
 		cfg, ok := c.(*Config)
 		if ok {
-			cfg.TLS = &mtls.Config{Config: config}
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.Config = config
+		}
+	}
+}
+
+// WithTLSFiles loads the certificate and key from files on start,
+// use WithTLSReload to swap them when they change.
+func WithTLSFiles(certFile, keyFile string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.ConfigFiles.Certificates = append(cfg.TLS.ConfigFiles.Certificates, struct {
+				CertFile string `json:"certFile" yaml:"certFile"`
+				KeyFile  string `json:"keyFile"  yaml:"keyFile"`
+			}{CertFile: certFile, KeyFile: keyFile})
+		}
+	}
+}
+
+// WithMTLS requires client certificates and verifies them against the CAs in
+// clientCAFiles, handlers get the identity of the client in the incoming metadata.
+func WithMTLS(clientCAFiles ...string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.ConfigFiles.ClientCAFiles = append(cfg.TLS.ConfigFiles.ClientCAFiles, clientCAFiles...)
+			cfg.TLS.ConfigFiles.ClientAuth = mtls.ClientAuth{ClientAuthType: tls.RequireAndVerifyClientCert}
+		}
+	}
+}
+
+// WithTLSReload swaps the TLS files when they change, they get checked every interval.
+func WithTLSReload(interval time.Duration) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.TLSReload.Enabled = true
+
+			if interval > 0 {
+				cfg.TLSReload.Interval = config.Duration(interval)
+			}
+		}
+	}
+}
+
+// WithTLSReloadMetrics reports the days until the TLS certificates expire to m.
+func WithTLSReloadMetrics(m metrics.Metrics) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.TLSReload.OptMetrics = m
 		}
 	}
 }
@@ -226,6 +356,55 @@ func WithHealthService(health bool) server.Option {
 	}
 }
 
+// WithHealthComponents enables the health service and reports the state of the
+// components per service.
+func WithHealthComponents(components *types.Components) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.HealthService = true
+			cfg.OptHealthComponents = components
+		}
+	}
+}
+
+// WithHealthCheck adds a check to the health service, a check named after a
+// gRPC service only affects that service.
+func WithHealthCheck(name string, check HealthCheck) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.OptHealthChecks == nil {
+				cfg.OptHealthChecks = make(map[string]HealthCheck)
+			}
+
+			cfg.OptHealthChecks[name] = check
+		}
+	}
+}
+
+// WithHealthInterval sets the interval the health status gets updated in.
+func WithHealthInterval(interval time.Duration) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.HealthInterval = config.Duration(interval)
+		}
+	}
+}
+
+// WithDrain sets the delay before the listener gets closed on Stop, and the
+// maximum time to wait for in-flight RPCs afterwards.
+func WithDrain(delay, timeout time.Duration) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.DrainDelay = config.Duration(delay)
+			cfg.DrainTimeout = config.Duration(timeout)
+		}
+	}
+}
+
 // WithReflection dictates whether the server should implementent gRPC
 // reflection. This is used by e.g. the gRPC proxy. Defaults to false.
 func WithReflection(reflection bool) server.Option {
@@ -266,3 +445,23 @@ func WithLogPlugin(plugin string) server.Option {
 		}
 	}
 }
+
+// WithRegion sets the region this entrypoint advertises in the registry.
+func WithRegion(region string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Region = region
+		}
+	}
+}
+
+// WithZone sets the zone this entrypoint advertises in the registry.
+func WithZone(zone string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Zone = zone
+		}
+	}
+}
@@ -9,6 +9,9 @@ require (
 	github.com/go-orb/plugins/config/source/file v0.2.0
 	github.com/go-orb/plugins/log/slog v0.2.0
 	github.com/go-orb/plugins/registry/mdns v0.1.1
+	github.com/go-orb/plugins/server/http v0.3.1
+	github.com/go-orb/plugins/server/middleware/recovery v0.1.0
+	github.com/go-orb/plugins/server/srvutil v0.1.0
 	github.com/lithammer/shortuuid/v4 v4.2.0
 	github.com/stretchr/testify v1.10.0
 	google.golang.org/grpc v1.71.0
@@ -9,6 +9,8 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sync/atomic"
+	"time"
 
 	"log/slog"
 
@@ -27,6 +29,10 @@ import (
 	mnet "github.com/go-orb/go-orb/util/net"
 	mtls "github.com/go-orb/go-orb/util/tls"
 
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
+	"github.com/go-orb/plugins/server/middleware/recovery"
+	"github.com/go-orb/plugins/server/srvutil"
+
 	"github.com/lithammer/shortuuid/v4"
 )
 
@@ -50,10 +56,20 @@ type Server struct {
 
 	lis net.Listener
 
+	// tlsReloader serves the certificates of the TLS files.
+	tlsReloader *utls.Reloader
+
 	// health server implements the gRPC health protocol.
 	health *health.Server
+	// healthCancel stops the updates of the health server.
+	healthCancel context.CancelFunc
+
+	// recovery recovers panics of the handlers and the middlewares.
+	recovery *recovery.Middleware
 
-	started bool
+	started atomic.Bool
+	// draining is set while the entrypoint stops.
+	draining atomic.Bool
 }
 
 // Provide provides a gRPC server by config.
@@ -72,6 +88,13 @@ func Provide(
 		return nil, err
 	}
 
+	mws, err := srvutil.Middlewares(cfg.Middlewares, configData, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.OptMiddlewares = append(cfg.OptMiddlewares, mws...)
+
 	return New(serviceName, serviceVersion, epName, cfg, logger, reg)
 }
 
@@ -97,6 +120,7 @@ func New(
 		config:         cfg,
 		logger:         logger,
 		registry:       reg,
+		recovery:       recovery.New(recovery.NewConfig(), logger),
 	}
 
 	srv.setupgRPCServer()
@@ -106,6 +130,7 @@ func New(
 
 func (s *Server) setupgRPCServer() {
 	grpcOpts := []grpc.ServerOption{
+		grpc.Creds(listenerCredentials{}),
 		grpc.UnaryInterceptor(s.unaryServerInterceptor()),
 		grpc.StreamInterceptor(s.streamServerInterceptor()),
 	}
@@ -128,7 +153,7 @@ func (s *Server) setupgRPCServer() {
 
 // Start start the gRPC server.
 func (s *Server) Start(ctx context.Context) error {
-	if s.started {
+	if s.started.Load() {
 		return nil
 	}
 
@@ -152,6 +177,10 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}
 
+	if s.tlsReloader != nil && s.config.TLSReload.Enabled {
+		s.tlsReloader.Start()
+	}
+
 	s.logger = s.logger.With(slog.String("transport", s.Transport()), slog.String("address", s.Address()))
 
 	s.logger.Info("gRPC server listening")
@@ -163,53 +192,81 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}()
 
-	if s.health != nil {
-		s.health.Resume()
-	}
-
 	// Register with registry.
 	if err := s.registryRegister(ctx); err != nil {
 		return err
 	}
 
-	s.started = true
+	s.started.Store(true)
+
+	if s.health != nil {
+		// Resume clears the shutdown of a previous Stop, the statuses get updated right after.
+		s.health.Resume()
+
+		var healthCtx context.Context
+
+		healthCtx, s.healthCancel = context.WithCancel(context.Background())
+		go s.watchHealth(healthCtx)
+	}
 
 	return nil
 }
 
-// Stop stop the gRPC server.
+// Stop drains and stops the gRPC server.
+//
+// It deregisters the entrypoint, sets all services to NOT_SERVING, waits DrainDelay
+// for that to propagate, stops accepting connections and waits for in-flight RPCs
+// and streams up to DrainTimeout before closing the remaining connections.
 func (s *Server) Stop(ctx context.Context) error {
-	if !s.started {
+	if !s.started.Load() {
 		return nil
 	}
 
 	s.logger.Info("gRPC server shutting down", "address", s.lis.Addr().String())
 
-	if err := s.registryDeregister(ctx); err != nil {
-		return err
+	err := s.registryDeregister(ctx)
+	if err != nil {
+		s.logger.Error("failed to deregister the gRPC server", "error", err)
 	}
 
-	done := make(chan any)
+	s.draining.Store(true)
+	defer s.draining.Store(false)
 
-	go func() {
-		if s.health != nil {
-			s.health.Shutdown()
-		}
+	if s.healthCancel != nil {
+		s.healthCancel()
+	}
 
-		s.server.GracefulStop()
+	if s.health != nil {
+		s.health.Shutdown()
+	}
+
+	srvutil.SleepContext(ctx, time.Duration(s.config.DrainDelay))
 
-		done <- nil
+	drainCtx, cancel := srvutil.DrainContext(ctx, time.Duration(s.config.DrainTimeout))
+	defer cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		s.server.GracefulStop()
+		close(done)
 	}()
 
 	select {
-	case <-ctx.Done():
+	case <-drainCtx.Done():
+		s.logger.Warn("Closed the gRPC server before all RPCs finished")
 		s.server.Stop()
+		<-done
 	case <-done:
 	}
 
-	s.started = false
+	if s.tlsReloader != nil {
+		s.tlsReloader.Stop()
+	}
 
-	return nil
+	s.started.Store(false)
+
+	return err
 }
 
 // Config returns the server config.
@@ -260,6 +317,11 @@ func (s *Server) String() string {
 	return Plugin
 }
 
+// Panics returns the number of panics recovered by the entrypoint, e.g. for alerting.
+func (s *Server) Panics() uint64 {
+	return s.recovery.Panics()
+}
+
 // Enabled returns if this entrypoint has been enbaled in config.
 func (s *Server) Enabled() bool {
 	return s.config.Enabled
@@ -312,6 +374,12 @@ func (s *Server) listen() error {
 		s.config.TLS = &mtls.Config{Config: config}
 	}
 
+	if s.tlsReloader == nil && s.tlsFromFiles() {
+		if err := s.setupTLSReload(); err != nil {
+			return err
+		}
+	}
+
 	var tlsConfig *tls.Config
 
 	if s.config.TLS != nil && s.config.TLS.Config != nil {
@@ -330,16 +398,69 @@ func (s *Server) listen() error {
 	return nil
 }
 
+// tlsFromFiles reports whether the TLS config gets served from its files.
+func (s *Server) tlsFromFiles() bool {
+	return !s.config.Insecure && s.config.TLS != nil && !utls.FilesFromConfig(s.config.TLS.ConfigFiles).Empty()
+}
+
+// setupTLSReload replaces the TLS config with the one of the reloader.
+func (s *Server) setupTLSReload() error {
+	files := s.config.TLS.ConfigFiles
+
+	var base *tls.Config
+
+	switch {
+	case s.config.TLS.Config != nil:
+		base = s.config.TLS.Config.Clone()
+	case len(files.Certificates) == 0:
+		// Only client CAs, generate a self signed certificate.
+		config, err := mtls.GenTLSConfig(s.config.Address)
+		if err != nil {
+			return fmt.Errorf("failed to generate self signed certificate: %w", err)
+		}
+
+		base = config
+	default:
+		base = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if files.ClientAuth.ClientAuthType != tls.NoClientCert {
+		base.ClientAuth = files.ClientAuth.ClientAuthType
+	}
+
+	reloader, err := utls.NewReloader(
+		utls.FilesFromConfig(files),
+		utls.WithReloadInterval(time.Duration(s.config.TLSReload.Interval)),
+		utls.WithReloadLogger(s.logger),
+		utls.WithReloadMetrics(s.config.TLSReload.OptMetrics),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load the TLS files: %w", err)
+	}
+
+	s.tlsReloader = reloader
+	s.config.TLS = &mtls.Config{ConfigFiles: files, Config: reloader.ServerConfig(base)}
+
+	return nil
+}
+
 func (s *Server) registryService() registry.ServiceNode {
-	return registry.ServiceNode{
+	node := registry.ServiceNode{
 		Name:     s.serviceName,
 		Version:  s.serviceVersion,
 		Node:     s.id,
 		Address:  s.Address(),
 		Network:  s.Network(),
 		Scheme:   s.Transport(),
+		Region:   s.config.Region,
 		Metadata: make(map[string]string),
 	}
+
+	if s.config.Zone != "" {
+		node.Metadata[srvutil.MetadataZone] = s.config.Zone
+	}
+
+	return node
 }
 
 func (s *Server) registryRegister(ctx context.Context) error {
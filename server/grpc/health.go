@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/types"
+
+	"github.com/go-orb/plugins/server/srvutil"
+)
+
+// HealthCheck is a user-registered check, a nil error means healthy.
+type HealthCheck = srvutil.HealthCheck
+
+// Health returns srvutil.ErrNotStarted while the entrypoint isn't serving and srvutil.ErrDraining while it stops.
+func (s *Server) Health(_ context.Context) error {
+	return srvutil.Health(s.started.Load(), s.draining.Load())
+}
+
+// componentsHealth returns the first error of the components checked by srvutil.ComponentHealth,
+// go-orb servers are checked by their entrypoints.
+func componentsHealth(ctx context.Context, components *types.Components) error {
+	var check func(c any) error
+
+	check = func(c any) error {
+		switch comp := c.(type) {
+		case *server.Server:
+			for name, ep := range comp.GetEntrypoints().All() {
+				if err := check(ep); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		default:
+			if hc, ok := srvutil.ComponentHealth(c); ok {
+				return hc(ctx)
+			}
+		}
+
+		return nil
+	}
+
+	if components == nil {
+		return nil
+	}
+
+	for _, c := range components.Iterate(false) {
+		if err := check(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateHealth sets the serving status of every registered service, and of the server
+// as a whole with the empty service name.
+//
+// Components and checks fail all services, checks named after a service only fail that service.
+func (s *Server) updateHealth(ctx context.Context) {
+	timeout := time.Duration(s.config.HealthTimeout)
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	status := func(err error) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		if err != nil {
+			return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+
+	services := s.server.GetServiceInfo()
+
+	err := componentsHealth(ctx, s.config.OptHealthComponents)
+
+	// Without components, e.g. for entrypoints from a config file, the registry is checked.
+	if check, ok := srvutil.ComponentHealth(s.registry); ok && err == nil && s.config.OptHealthComponents == nil {
+		if err = check(ctx); err != nil {
+			err = fmt.Errorf("%s: %w", srvutil.ComponentName(s.registry), err)
+		}
+	}
+
+	for name, check := range s.config.OptHealthChecks {
+		if _, ok := services[name]; ok || err != nil {
+			continue
+		}
+
+		err = check(ctx)
+	}
+
+	if err != nil {
+		s.logger.Warn("gRPC health check failed", "error", err)
+	}
+
+	s.health.SetServingStatus("", status(err))
+
+	for name := range services {
+		serviceErr := err
+
+		if check, ok := s.config.OptHealthChecks[name]; ok && serviceErr == nil {
+			serviceErr = check(ctx)
+		}
+
+		s.health.SetServingStatus(name, status(serviceErr))
+	}
+}
+
+// watchHealth updates the serving status until the context is done.
+func (s *Server) watchHealth(ctx context.Context) {
+	s.updateHealth(ctx)
+
+	interval := time.Duration(s.config.HealthInterval)
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.updateHealth(ctx)
+		}
+	}
+}
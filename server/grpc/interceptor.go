@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-orb/go-orb/util/metadata"
 	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/go-orb/plugins/server/srvutil"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	gmetadata "google.golang.org/grpc/metadata"
@@ -33,6 +34,9 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 			}
 		}
 
+		// The identity of a verified client certificate, never from metadata.
+		setPeerMetadata(ctx, reqMd)
+
 		fmSplit := strings.Split(info.FullMethod, "/")
 		if len(fmSplit) >= 3 {
 			reqMd[metadata.Service] = fmSplit[1]
@@ -52,6 +56,8 @@ func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 			h = m.Call(h)
 		}
 
+		h = s.recovery.Call(h)
+
 		result, err := h(ctx, req)
 
 		if len(outMd) > 0 {
@@ -108,6 +114,26 @@ func (s *serverStreamWrapper) SendMsg(msg interface{}) error {
 	return s.ServerStream.SendMsg(msg)
 }
 
+// middlewareStream passes the messages of a grpc.ServerStream through the stream middlewares,
+// headers and trailers go to the grpc.ServerStream directly.
+type middlewareStream struct {
+	grpc.ServerStream
+	stream ServerStream
+	ctx    context.Context
+}
+
+func (s *middlewareStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *middlewareStream) SendMsg(msg any) error {
+	return s.stream.SendMsg(msg)
+}
+
+func (s *middlewareStream) RecvMsg(msg any) error {
+	return s.stream.RecvMsg(msg)
+}
+
 func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv any, serverStream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		ctx, reqMd := metadata.WithIncoming(serverStream.Context())
@@ -124,6 +150,9 @@ func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 			}
 		}
 
+		// The identity of a verified client certificate, never from metadata.
+		setPeerMetadata(ctx, reqMd)
+
 		fmSplit := strings.Split(info.FullMethod, "/")
 		if len(fmSplit) >= 3 {
 			reqMd[metadata.Service] = fmSplit[1]
@@ -136,7 +165,11 @@ func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
 			defer cancel()
 		}
 
-		err := handler(srv, &serverStreamWrapper{serverStream, ctx})
+		h := func(ctx context.Context, stream ServerStream) error {
+			return handler(srv, &middlewareStream{ServerStream: serverStream, stream: stream, ctx: ctx})
+		}
+
+		err := s.recovery.Stream(srvutil.ChainStream(s.config.OptMiddlewares, h))(ctx, &serverStreamWrapper{serverStream, ctx})
 
 		if err != nil {
 			oErr := orberrors.From(err)
@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
+)
+
+// errClientHandshake is returned by listenerCredentials, they only serve connections.
+var errClientHandshake = errors.New("grpc: listener credentials can't be used by clients")
+
+// listenerCredentials report the TLS state of connections from the TLS listener to
+// gRPC, so handlers can get the peer certificates. The handshake of other connections
+// is a no-op like with insecure credentials.
+type listenerCredentials struct{}
+
+var _ credentials.TransportCredentials = listenerCredentials{}
+
+func (listenerCredentials) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errClientHandshake
+}
+
+func (listenerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return conn, listenerAuthInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}}, nil
+	}
+
+	// gRPC sets the connection timeout as deadline.
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, nil, err
+	}
+
+	return conn, credentials.TLSInfo{
+		State:          tlsConn.ConnectionState(),
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+	}, nil
+}
+
+func (listenerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "tls"}
+}
+
+func (c listenerCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (listenerCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// listenerAuthInfo is the auth info of connections without TLS.
+type listenerAuthInfo struct {
+	credentials.CommonAuthInfo
+}
+
+func (listenerAuthInfo) AuthType() string {
+	return "insecure"
+}
+
+// peerTLSState returns the TLS state of the connection of the request.
+func peerTLSState(ctx context.Context) *tls.ConnectionState {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+
+	return &info.State
+}
+
+// setPeerMetadata sets the identity of a verified client certificate in md.
+func setPeerMetadata(ctx context.Context, md map[string]string) {
+	utls.SetPeerMetadata(md, peerTLSState(ctx))
+}
@@ -2,23 +2,43 @@ package tests
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	gmetadata "google.golang.org/grpc/metadata"
 
 	"github.com/go-orb/go-orb/config"
 	"github.com/go-orb/go-orb/log"
 	"github.com/go-orb/go-orb/registry"
 	"github.com/go-orb/go-orb/server"
 	"github.com/go-orb/go-orb/types"
+	"github.com/go-orb/go-orb/util/metadata"
 	mtls "github.com/go-orb/go-orb/util/tls"
 
 	mgrpc "github.com/go-orb/plugins/server/grpc"
 	"github.com/go-orb/plugins/server/grpc/tests/handler"
 	"github.com/go-orb/plugins/server/grpc/tests/proto"
 	tgrpc "github.com/go-orb/plugins/server/grpc/tests/util/grpc"
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
 
 	_ "github.com/go-orb/plugins/codecs/json"
 	_ "github.com/go-orb/plugins/codecs/yaml"
@@ -84,6 +104,229 @@ func TestGrpcStartStop(t *testing.T) {
 	cleanup(t)
 }
 
+type healthComponent struct {
+	err atomic.Value
+}
+
+func (c *healthComponent) Start(context.Context) error { return nil }
+func (c *healthComponent) Stop(context.Context) error  { return nil }
+func (c *healthComponent) Type() string                { return "kvstore" }
+func (c *healthComponent) String() string              { return "redis" }
+
+func (c *healthComponent) Health(context.Context) error {
+	err, _ := c.err.Load().(error) //nolint:errcheck
+	return err
+}
+
+func TestGrpcHealth(t *testing.T) {
+	kv := &healthComponent{}
+
+	components := types.NewComponents()
+	require.NoError(t, components.Add(kv, types.PriorityKVStore))
+
+	h, _ := server.Handlers.Get("Streams")
+	srv, cleanup, err := tgrpc.SetupServer(
+		mgrpc.WithInsecure(),
+		mgrpc.WithAddress("127.0.0.1:0"),
+		mgrpc.WithHandlers(h),
+		mgrpc.WithHealthComponents(components),
+		mgrpc.WithHealthInterval(10*time.Millisecond),
+		mgrpc.WithHealthCheck("echo.Streams", func(context.Context) error { return nil }),
+	)
+	require.NoError(t, err, "setup server")
+	defer cleanup(t)
+
+	conn, err := grpc.NewClient(srv.Address(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	defer conn.Close() //nolint:errcheck
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	requireStatus := func(service string, want grpc_health_v1.HealthCheckResponse_ServingStatus) {
+		require.Eventually(t, func() bool {
+			resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+			return err == nil && resp.GetStatus() == want
+		}, time.Second, 5*time.Millisecond, service)
+	}
+
+	requireStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	requireStatus("echo.Streams", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	kv.err.Store(errors.New("connection refused"))
+
+	requireStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	requireStatus("echo.Streams", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestGrpcDrain(t *testing.T) {
+	h, _ := server.Handlers.Get("Streams")
+	srv, cleanup, err := tgrpc.SetupServer(
+		mgrpc.WithInsecure(),
+		mgrpc.WithAddress("127.0.0.1:0"),
+		mgrpc.WithHandlers(h),
+		mgrpc.WithHealthService(true),
+		mgrpc.WithDrain(300*time.Millisecond, time.Second),
+	)
+	require.NoError(t, err, "setup server")
+	defer cleanup(t)
+
+	conn, err := grpc.NewClient(srv.Address(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	defer conn.Close() //nolint:errcheck
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	stopped := make(chan error, 1)
+
+	go func() { stopped <- srv.Stop(context.Background()) }()
+
+	// Services report NOT_SERVING during the drain delay, requests still get served.
+	require.Eventually(t, func() bool {
+		resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		return err == nil && resp.GetStatus() == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, tgrpc.MakeRequest(srv.Address(), "Alex", nil), "request while draining")
+
+	require.NoError(t, <-stopped)
+	require.Error(t, srv.(*mgrpc.Server).Health(context.Background())) //nolint:errcheck
+}
+
+// writeCertificate writes a self signed certificate with commonName for 127.0.0.1,
+// opts change the template.
+func writeCertificate(t *testing.T, certFile, keyFile, commonName string, opts ...func(*x509.Certificate)) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	for _, o := range opts {
+		o(template)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+}
+
+func TestGrpcTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	writeCertificate(t, certFile, keyFile, "first")
+
+	h, _ := server.Handlers.Get("Streams")
+	srv, cleanup, err := tgrpc.SetupServer(
+		mgrpc.WithAddress("127.0.0.1:0"),
+		mgrpc.WithHandlers(h),
+		mgrpc.WithTLSFiles(certFile, keyFile),
+		mgrpc.WithTLSReload(20*time.Millisecond),
+	)
+	require.NoError(t, err, "setup server")
+	defer cleanup(t)
+
+	commonName := func() string {
+		conn, err := tls.Dial("tcp", srv.Address(), &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+			NextProtos:         []string{"h2"},
+		})
+		require.NoError(t, err)
+
+		defer conn.Close() //nolint:errcheck
+
+		require.Equal(t, "h2", conn.ConnectionState().NegotiatedProtocol)
+
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}
+
+	require.Equal(t, "first", commonName())
+
+	writeCertificate(t, certFile, keyFile, "second")
+
+	require.Eventually(t, func() bool { return commonName() == "second" }, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestGrpcMTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+
+	spiffeID, err := url.Parse("spiffe://example.org/ns/default/sa/billing")
+	require.NoError(t, err)
+
+	// The client certificate is its own CA.
+	writeCertificate(t, certFile, keyFile, "billing", func(c *x509.Certificate) {
+		c.IsCA = true
+		c.BasicConstraintsValid = true
+		c.KeyUsage |= x509.KeyUsageCertSign
+		c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		c.IPAddresses = nil
+		c.URIs = []*url.URL{spiffeID}
+	})
+
+	clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+
+	peer := make(chan map[string]string, 1)
+
+	h, _ := server.Handlers.Get("Streams")
+	srv, cleanup, err := tgrpc.SetupServer(
+		mgrpc.WithAddress("127.0.0.1:0"),
+		mgrpc.WithHandlers(h),
+		mgrpc.WithMTLS(certFile),
+		mgrpc.WithGRPCOptions(grpc.ChainUnaryInterceptor(
+			func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				md, _ := metadata.Incoming(ctx)
+				peer <- md
+
+				return handler(ctx, req)
+			},
+		)),
+	)
+	require.NoError(t, err, "setup server")
+	defer cleanup(t)
+
+	call := func(certs []tls.Certificate) error {
+		creds := credentials.NewTLS(&tls.Config{InsecureSkipVerify: true, Certificates: certs}) //nolint:gosec
+
+		conn, err := grpc.NewClient(srv.Address(), grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+
+		defer conn.Close() //nolint:errcheck
+
+		ctx := gmetadata.AppendToOutgoingContext(context.Background(), utls.MetadataPeerSPIFFEID, "spiffe://example.org/admin")
+
+		return conn.Invoke(ctx, "/echo.Streams/Call", &proto.CallRequest{Name: "Alex"}, &proto.CallResponse{})
+	}
+
+	// Clients without a certificate get rejected.
+	require.Error(t, call(nil))
+
+	require.NoError(t, call([]tls.Certificate{clientCert}))
+
+	md := <-peer
+	require.Equal(t, "CN=billing", md[utls.MetadataPeerSubject])
+	require.Equal(t, spiffeID.String(), md[utls.MetadataPeerSPIFFEID], "the metadata must not override the certificate")
+}
+
 func TestGrpcIntegration(t *testing.T) {
 	name := "com.example.test"
 	version := "v1.0.0"
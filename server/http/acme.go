@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/go-orb/go-orb/kvstore"
+)
+
+// Errors returned by the ACME setup.
+var (
+	ErrACMENoDomains = errors.New("acme: no domains configured")
+	ErrACMENoCache   = errors.New("acme: no cache directory or kvstore configured")
+	ErrACMEInsecure  = errors.New("acme: can't be used with an insecure entrypoint")
+)
+
+// acmeChallengeTimeout is the timeout for reading and writing HTTP-01 challenges.
+const acmeChallengeTimeout = 10 * time.Second
+
+// KVStoreCache stores ACME certificates and the account key in a go-orb kvstore.
+type KVStoreCache struct {
+	store    kvstore.KVStore
+	database string
+	table    string
+}
+
+var _ autocert.Cache = (*KVStoreCache)(nil)
+
+// NewKVStoreCache creates a certificate cache in database and table of store,
+// leave them empty to use the defaults of the store.
+func NewKVStoreCache(store kvstore.KVStore, database, table string) *KVStoreCache {
+	return &KVStoreCache{store: store, database: database, table: table}
+}
+
+// Get returns the data of key, or autocert.ErrCacheMiss.
+func (c *KVStoreCache) Get(ctx context.Context, key string) ([]byte, error) {
+	records, err := c.store.Get(ctx, key, c.database, c.table)
+	if errors.Is(err, kvstore.ErrNotFound) || (err == nil && len(records) == 0) {
+		return nil, autocert.ErrCacheMiss
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return records[0].Value, nil
+}
+
+// Put stores data under key.
+func (c *KVStoreCache) Put(ctx context.Context, key string, data []byte) error {
+	return c.store.Set(ctx, key, c.database, c.table, data)
+}
+
+// Delete removes key.
+func (c *KVStoreCache) Delete(ctx context.Context, key string) error {
+	err := c.store.Purge(ctx, key, c.database, c.table)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// newACMEManager creates the certificate manager of the ACME config.
+func newACMEManager(cfg *ACMEConfig) (*autocert.Manager, error) {
+	if len(cfg.Domains) == 0 {
+		return nil, ErrACMENoDomains
+	}
+
+	var cache autocert.Cache
+
+	switch {
+	case cfg.OptKVStore != nil:
+		cache = NewKVStoreCache(cfg.OptKVStore, cfg.KVStoreDatabase, cfg.KVStoreTable)
+	case cfg.CacheDir != "":
+		cache = autocert.DirCache(cfg.CacheDir)
+	default:
+		return nil, ErrACMENoCache
+	}
+
+	client := &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	if client.DirectoryURL == "" {
+		client.DirectoryURL = autocert.DefaultACMEDirectory
+	}
+
+	if cfg.DirectoryCAFile != "" {
+		pem, err := os.ReadFile(cfg.DirectoryCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("acme: read directory CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("acme: no certificates in %s", cfg.DirectoryCAFile)
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:errcheck,forcetypeassert
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		client.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	return &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		Cache:       cache,
+		HostPolicy:  autocert.HostWhitelist(cfg.Domains...),
+		RenewBefore: time.Duration(cfg.RenewBefore),
+		Client:      client,
+		Email:       cfg.Email,
+	}, nil
+}
+
+// setupACME returns the TLS config of the ACME manager, it answers TLS-ALPN-01
+// challenges and renews the certificates before they expire.
+func (s *Server) setupACME() (*tls.Config, error) {
+	if s.config.Insecure {
+		return nil, ErrACMEInsecure
+	}
+
+	manager, err := newACMEManager(&s.config.ACME)
+	if err != nil {
+		return nil, err
+	}
+
+	s.acme = manager
+
+	return manager.TLSConfig(), nil
+}
+
+// startACMEChallenges serves HTTP-01 challenges, other requests get redirected to HTTPS.
+func (s *Server) startACMEChallenges() error {
+	if s.acme == nil || s.config.ACME.HTTPAddress == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.config.ACME.HTTPAddress)
+	if err != nil {
+		return fmt.Errorf("acme: listen for HTTP-01 challenges: %w", err)
+	}
+
+	s.acmeServer = &http.Server{
+		Handler:           s.acme.HTTPHandler(nil),
+		ReadHeaderTimeout: acmeChallengeTimeout,
+		ReadTimeout:       acmeChallengeTimeout,
+		WriteTimeout:      acmeChallengeTimeout,
+	}
+
+	go func(srv *http.Server) {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("failed to serve ACME HTTP-01 challenges", "error", err)
+		}
+	}(s.acmeServer)
+
+	return nil
+}
+
+// stopACMEChallenges stops the HTTP-01 challenge server.
+func (s *Server) stopACMEChallenges(ctx context.Context) {
+	if s.acmeServer == nil {
+		return
+	}
+
+	if err := s.acmeServer.Shutdown(ctx); err != nil {
+		_ = s.acmeServer.Close() //nolint:errcheck
+	}
+
+	s.acmeServer = nil
+}
@@ -2,11 +2,9 @@ package http
 
 import (
 	"bytes"
-	"compress/gzip"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 
 	"log/slog"
 
@@ -41,13 +39,24 @@ func (s *Server) decodeBody(resp http.ResponseWriter, request *http.Request, msg
 			return "", err
 		}
 
-		// Gzip decode if needed
-		eHeader := request.Header.Get(headers.ContentEncoding)
-		if strings.Contains(eHeader, headers.GzipContentEncoding) {
-			body, err = gzip.NewReader(request.Body)
+		// Decompress if needed.
+		if eHeader := request.Header.Get(headers.ContentEncoding); eHeader != "" && eHeader != "identity" {
+			compressor, ok := getCompressor(eHeader)
+			if !ok {
+				return "", ErrContentEncodingNotSupported
+			}
+
+			reader, err := compressor.NewReader(request.Body)
 			if err != nil {
 				return "", err
 			}
+			defer reader.Close() //nolint:errcheck
+
+			// The route limit applies to the decompressed body as well.
+			body = reader
+			if limit := bodyLimit(request.Context()); limit > 0 {
+				body = http.MaxBytesReader(resp, reader, limit)
+			}
 		} else {
 			body = request.Body
 		}
@@ -73,6 +82,9 @@ func (s *Server) decodeBody(resp http.ResponseWriter, request *http.Request, msg
 }
 
 // encodeBody takes the return proto type and encodes it into the response.
+//
+// Responses get compressed with the negotiated content encoding if they are
+// larger than the configured minimum size.
 func (s *Server) encodeBody(w http.ResponseWriter, r *http.Request, v any) error {
 	contentType := w.Header().Get(headers.ContentType)
 	if len(contentType) == 0 {
@@ -85,24 +97,46 @@ func (s *Server) encodeBody(w http.ResponseWriter, r *http.Request, v any) error
 		return ErrContentTypeNotSupported
 	}
 
-	nw := w.(io.Writer) //nolint:errcheck
+	var compressor Compressor
+
+	if s.compressionEnabled(r) {
+		w.Header().Add(headers.Vary, headers.AcceptEncoding)
 
-	// Gzip compress response if needed.
-	aeHeader := r.Header.Get(headers.AcceptEncoding)
-	reHeader := r.Header.Get(headers.ContentEncoding)
-	gzipEnabled := s.config.Gzip || strings.Contains(reHeader, headers.GzipContentEncoding)
+		compressor = s.responseCompressor(r)
+	}
 
-	if gzipEnabled && strings.Contains(aeHeader, headers.GzipContentEncoding) {
-		w.Header().Set(headers.ContentEncoding, headers.GzipContentEncoding)
+	if compressor == nil {
+		if err := codec.NewEncoder(w).Encode(v); err != nil {
+			s.logger.Debug("Request failed, failed to encode response", "error", err)
+			return err
+		}
 
-		nw = gzip.NewWriter(w)
-		defer nw.(io.Closer).Close() //nolint:errcheck
+		return nil
 	}
 
-	if err := codec.NewEncoder(nw).Encode(v); err != nil {
+	// Marshal first to know the size.
+	data, err := codec.Marshal(v)
+	if err != nil {
 		s.logger.Debug("Request failed, failed to encode response", "error", err)
 		return err
 	}
 
-	return nil
+	if len(data) < s.config.Compression.MinSize {
+		_, err := w.Write(data)
+		return err
+	}
+
+	cw, err := compressor.NewWriter(w)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set(headers.ContentEncoding, compressor.Name())
+
+	if _, err := cw.Write(data); err != nil {
+		cw.Close() //nolint:errcheck,gosec
+		return err
+	}
+
+	return cw.Close()
 }
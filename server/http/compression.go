@@ -0,0 +1,288 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/go-orb/plugins/server/http/headers"
+)
+
+// ErrContentEncodingNotSupported is returned for requests with an unknown Content-Encoding.
+var ErrContentEncodingNotSupported = orberrors.New(
+	http.StatusUnsupportedMediaType,
+	strings.ToLower(http.StatusText(http.StatusUnsupportedMediaType)),
+).WrapNew("content encoding not supported")
+
+// Compressor is a content encoding for request and response bodies.
+//
+// Implementations should pool their encoders and decoders, Close returns them.
+type Compressor interface {
+	// Name is the Content-Encoding token, e.g. "gzip".
+	Name() string
+	// NewReader decompresses r, Close doesn't close r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+	// NewWriter compresses into w, Close flushes the stream but doesn't close w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+//nolint:gochecknoglobals
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]Compressor{}
+)
+
+//nolint:gochecknoinits
+func init() {
+	RegisterCompressor(newGzipCompressor())
+	RegisterCompressor(newZstdCompressor())
+	RegisterCompressor(newBrotliCompressor())
+}
+
+// RegisterCompressor makes a content encoding available to all servers,
+// it replaces a compressor with the same name.
+func RegisterCompressor(c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+
+	compressors[strings.ToLower(c.Name())] = c
+}
+
+// getCompressor returns the compressor for a Content-Encoding token.
+func getCompressor(name string) (Compressor, bool) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+
+	c, ok := compressors[strings.ToLower(strings.TrimSpace(name))]
+
+	return c, ok
+}
+
+// compressionEnabled reports whether the response to r may get compressed.
+func (s *Server) compressionEnabled(r *http.Request) bool {
+	return s.config.Gzip || s.config.Compression.Enabled || r.Header.Get(headers.ContentEncoding) != ""
+}
+
+// responseCompressor negotiates the compressor of a response, it returns nil
+// if the client accepts none of the enabled encodings.
+func (s *Server) responseCompressor(r *http.Request) Compressor {
+	encodings := s.config.Compression.Encodings
+	if len(encodings) == 0 {
+		encodings = DefaultCompressionEncodings
+	}
+
+	name := negotiateEncoding(r.Header.Get(headers.AcceptEncoding), encodings)
+	if name == "" {
+		return nil
+	}
+
+	c, _ := getCompressor(name)
+
+	return c
+}
+
+// negotiateEncoding returns the encoding with the highest q-value in Accept-Encoding,
+// ties go to the earlier one in preferred. It returns "" if none is acceptable.
+func negotiateEncoding(accept string, preferred []string) string {
+	if accept == "" {
+		return ""
+	}
+
+	qValues := make(map[string]float64)
+
+	for _, part := range strings.Split(accept, ",") {
+		token, params, _ := strings.Cut(part, ";")
+
+		q := 1.0
+
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+
+			q = parsed
+		}
+
+		qValues[strings.ToLower(strings.TrimSpace(token))] = q
+	}
+
+	best := ""
+	bestQ := 0.0
+
+	for _, name := range preferred {
+		if _, ok := getCompressor(name); !ok {
+			continue
+		}
+
+		q, ok := qValues[name]
+		if !ok {
+			if q, ok = qValues["*"]; !ok {
+				continue
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+
+	return best
+}
+
+// pooledReader returns its decoder to the pool on Close.
+type pooledReader struct {
+	io.Reader
+
+	put func()
+}
+
+func (p *pooledReader) Close() error {
+	p.put()
+	return nil
+}
+
+// pooledWriter closes its encoder and returns it to the pool on Close.
+type pooledWriter struct {
+	io.WriteCloser
+
+	put func()
+}
+
+func (p *pooledWriter) Close() error {
+	err := p.WriteCloser.Close()
+	p.put()
+
+	return err
+}
+
+type gzipCompressor struct {
+	readers sync.Pool
+	writers sync.Pool
+}
+
+func newGzipCompressor() *gzipCompressor {
+	return &gzipCompressor{
+		writers: sync.Pool{New: func() any { return gzip.NewWriter(nil) }},
+	}
+}
+
+func (c *gzipCompressor) Name() string {
+	return headers.GzipContentEncoding
+}
+
+func (c *gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	if gr, ok := c.readers.Get().(*gzip.Reader); ok {
+		if err := gr.Reset(r); err != nil {
+			c.readers.Put(gr)
+			return nil, err
+		}
+
+		return &pooledReader{Reader: gr, put: func() { c.readers.Put(gr) }}, nil
+	}
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pooledReader{Reader: gr, put: func() { c.readers.Put(gr) }}, nil
+}
+
+func (c *gzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	gw := c.writers.Get().(*gzip.Writer) //nolint:errcheck
+	gw.Reset(w)
+
+	return &pooledWriter{WriteCloser: gw, put: func() { c.writers.Put(gw) }}, nil
+}
+
+type zstdCompressor struct {
+	readers sync.Pool
+	writers sync.Pool
+}
+
+func newZstdCompressor() *zstdCompressor {
+	return &zstdCompressor{}
+}
+
+func (c *zstdCompressor) Name() string {
+	return headers.ZstdContentEncoding
+}
+
+func (c *zstdCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	zr, ok := c.readers.Get().(*zstd.Decoder)
+	if !ok {
+		var err error
+
+		// One goroutine per decoder, there are many concurrent requests.
+		zr, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zr.Reset(r); err != nil {
+		c.readers.Put(zr)
+		return nil, err
+	}
+
+	// Decoders must not be closed to be reused.
+	return &pooledReader{Reader: zr, put: func() { c.readers.Put(zr) }}, nil
+}
+
+func (c *zstdCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	zw, ok := c.writers.Get().(*zstd.Encoder)
+	if !ok {
+		var err error
+
+		zw, err = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	zw.Reset(w)
+
+	return &pooledWriter{WriteCloser: zw, put: func() { c.writers.Put(zw) }}, nil
+}
+
+type brotliCompressor struct {
+	readers sync.Pool
+	writers sync.Pool
+}
+
+func newBrotliCompressor() *brotliCompressor {
+	return &brotliCompressor{
+		readers: sync.Pool{New: func() any { return brotli.NewReader(nil) }},
+		// Higher levels cost too much CPU for dynamic responses.
+		writers: sync.Pool{New: func() any { return brotli.NewWriterLevel(nil, brotli.DefaultCompression) }},
+	}
+}
+
+func (c *brotliCompressor) Name() string {
+	return headers.BrotliContentEncoding
+}
+
+func (c *brotliCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := c.readers.Get().(*brotli.Reader) //nolint:errcheck
+	if err := br.Reset(r); err != nil {
+		c.readers.Put(br)
+		return nil, err
+	}
+
+	return &pooledReader{Reader: br, put: func() { c.readers.Put(br) }}, nil
+}
+
+func (c *brotliCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	bw := c.writers.Get().(*brotli.Writer) //nolint:errcheck
+	bw.Reset(w)
+
+	return &pooledWriter{WriteCloser: bw, put: func() { c.writers.Put(bw) }}, nil
+}
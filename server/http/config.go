@@ -3,12 +3,19 @@ package http
 import (
 	"crypto/tls"
 	"errors"
+	"net/http"
+	"slices"
 	"time"
 
 	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/kvstore"
 	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/metrics"
 	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/types"
 	mtls "github.com/go-orb/go-orb/util/tls"
+
+	"github.com/go-orb/plugins/server/http/headers"
 )
 
 const (
@@ -57,6 +64,9 @@ const (
 	// will send back a gzip compressed respponse.
 	DefaultEnableGzip = false
 
+	// DefaultCompressionMinSize is the minimum size of a response to get compressed.
+	DefaultCompressionMinSize = 1024
+
 	// DefaultConfigSection is the section key used in config files used to
 	// configure the server options.
 	DefaultConfigSection = Plugin
@@ -64,11 +74,57 @@ const (
 	// DefaultMaxHeaderBytes is the maximum size to parse from a client's
 	// HTTP request headers.
 	DefaultMaxHeaderBytes = 1024 * 64
+
+	// DefaultOpenAPIPath is the path the OpenAPI document gets served at.
+	DefaultOpenAPIPath = "/openapi.json"
+
+	// DefaultOpenAPIUIPath is the path the OpenAPI viewer gets served at.
+	DefaultOpenAPIUIPath = "/docs"
+
+	// DefaultWebSocketMaxMessageSize is the maximum size of a message received over a WebSocket.
+	DefaultWebSocketMaxMessageSize = 4 * 1024 * 1024
+
+	// DefaultHealthPath is the path of the aggregated health of the service.
+	DefaultHealthPath = "/healthz"
+
+	// DefaultReadinessPath is the path of the readiness probe.
+	DefaultReadinessPath = "/readyz"
+
+	// DefaultLivenessPath is the path of the liveness probe.
+	DefaultLivenessPath = "/livez"
+
+	// DefaultHealthTimeout is the timeout of all checks of one health request.
+	DefaultHealthTimeout = 5 * time.Second
+
+	// DefaultTLSReloadInterval is the interval the TLS files get checked for changes in.
+	DefaultTLSReloadInterval = 10 * time.Second
 )
 
+// DefaultCORSAllowedMethods are the methods allowed for cross origin requests.
+//
+//nolint:gochecknoglobals
+var DefaultCORSAllowedMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// DefaultCORSAllowedHeaders are the headers allowed for cross origin requests.
+//
+//nolint:gochecknoglobals
+var DefaultCORSAllowedHeaders = []string{"Accept", "Authorization", "Content-Type", "Content-Encoding", "X-Requested-With"}
+
+// DefaultCompressionEncodings are the enabled content encodings in order of preference.
+//
+//nolint:gochecknoglobals
+var DefaultCompressionEncodings = []string{
+	headers.ZstdContentEncoding, headers.BrotliContentEncoding, headers.GzipContentEncoding,
+}
+
 // Errors.
 var (
 	ErrNoMatchingCodecs = errors.New("no matching codecs found, did you register the codec plugins?")
+
+	// ErrRouteConflict is returned by Router.Handle for routes which conflict with a registered route.
+	ErrRouteConflict = errors.New("route conflicts with a registered route")
 )
 
 // Config provides options to the entrypoint.
@@ -115,7 +171,18 @@ type Config struct {
 	// ```
 	TLS *mtls.Config `json:"tls,omitempty" yaml:"tls,omitempty"`
 
+	// TLSReload watches the certificate, key and CA files of TLS and swaps them
+	// without a restart when they change.
+	TLSReload TLSReloadConfig `json:"tlsReload" yaml:"tlsReload"`
+
+	// ACME gets and renews the certificates from an ACME CA like Let's Encrypt,
+	// it replaces TLS.
+	ACME ACMEConfig `json:"acme" yaml:"acme"`
+
 	// H2C allows h2c connections; HTTP2 without TLS.
+	//
+	// Insecure entrypoints with H2C enabled register with the "h2c" or
+	// "unix+h2c" scheme, unix sockets are always insecure.
 	H2C bool `json:"h2c" yaml:"h2c"`
 
 	// HTTP2 dicates whether to also allow HTTP/2 connections. Defaults to true.
@@ -130,8 +197,13 @@ type Config struct {
 	//
 	// Alternatively, you can send a gzip compressed request, and the server
 	// will send back a gzip compressed respponse.
+	//
+	// It's the same as Compression.Enabled, the encoding gets negotiated.
 	Gzip bool `json:"gzip" yaml:"gzip"`
 
+	// Compression configures the content encodings of responses.
+	Compression CompressionConfig `json:"compression" yaml:"compression"`
+
 	// MaxConcurrentStreams for HTTP2.
 	MaxConcurrentStreams int `json:"maxConcurrentStreams" yaml:"maxConcurrentStreams"`
 
@@ -157,11 +229,216 @@ type Config struct {
 	// zero, there is no timeout.
 	IdleTimeout config.Duration `json:"idleTimeout" yaml:"idleTimeout"`
 
+	// Region is published to the registry with this entrypoint,
+	// clients use it for locality-aware routing.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Zone is published to the registry as the node's "zone" metadata,
+	// clients use it for locality-aware routing.
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+
+	// OpenAPI serves the merged OpenAPI document of the registered services
+	// and a viewer for it.
+	OpenAPI OpenAPIConfig `json:"openapi" yaml:"openapi"`
+
+	// CORS allows browsers to call this entrypoint from other origins,
+	// it's disabled without allowed origins.
+	CORS CORSConfig `json:"cors" yaml:"cors"`
+
+	// WebSocket serves streaming RPCs over WebSockets.
+	WebSocket WebSocketConfig `json:"webSocket" yaml:"webSocket"`
+
+	// Limits are the request limits of all routes and of route patterns.
+	Limits LimitsConfig `json:"limits" yaml:"limits"`
+
+	// Health serves the health, readiness and liveness endpoints.
+	Health HealthConfig `json:"health" yaml:"health"`
+
+	// DrainDelay is the time between deregistering and failing the readiness
+	// probe, and closing the listeners on Stop. It gives the registry and load
+	// balancers time to stop sending requests. Defaults to 0.
+	DrainDelay config.Duration `json:"drainDelay,omitempty" yaml:"drainDelay,omitempty"`
+
+	// DrainTimeout is the maximum time Stop waits for in-flight requests and streams
+	// after closing the listeners, the remaining connections get closed afterwards.
+	// Defaults to 0, which waits until the stop context is done.
+	DrainTimeout config.Duration `json:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty"`
+
+	// Middlewares are the middlewares of this entrypoint, they get created from
+	// server.Middlewares and get appended to the global server middlewares.
+	Middlewares []server.MiddlewareConfig `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+
 	// Logger allows you to dynamically change the log level and plugin for a
 	// specific entrypoint.
 	Logger log.Config `json:"logger" yaml:"logger"`
 }
 
+// OpenAPIConfig configures serving of the OpenAPI document.
+type OpenAPIConfig struct {
+	// Enabled serves the document and the viewer. Defaults to false.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Path of the document. Defaults to "/openapi.json".
+	Path string `json:"path" yaml:"path"`
+
+	// UIPath of the viewer, an empty path disables the viewer. Defaults to "/docs".
+	UIPath string `json:"uiPath" yaml:"uiPath"`
+
+	// Title of the document, defaults to the service name.
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+
+	// Version of the document, defaults to the service version.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// ACMEConfig configures certificates from an ACME CA. Challenges get answered with
+// TLS-ALPN-01 on the entrypoint, and with HTTP-01 if HTTPAddress is set.
+type ACMEConfig struct {
+	// Enabled gets the certificates from the CA. Defaults to false.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Domains are the host names certificates get requested for.
+	Domains []string `json:"domains" yaml:"domains"`
+
+	// Email is the contact of the ACME account.
+	Email string `json:"email,omitempty" yaml:"email,omitempty"`
+
+	// DirectoryURL of the CA. Defaults to Let's Encrypt.
+	DirectoryURL string `json:"directoryUrl,omitempty" yaml:"directoryUrl,omitempty"`
+
+	// DirectoryCAFile is a PEM file with the root of the directory's certificate,
+	// e.g. for test servers like pebble.
+	DirectoryCAFile string `json:"directoryCaFile,omitempty" yaml:"directoryCaFile,omitempty"`
+
+	// CacheDir stores the certificates and the account key, unless OptKVStore is set.
+	CacheDir string `json:"cacheDir,omitempty" yaml:"cacheDir,omitempty"`
+
+	// KVStoreDatabase and KVStoreTable of OptKVStore, empty values use the defaults of the store.
+	KVStoreDatabase string `json:"kvstoreDatabase,omitempty" yaml:"kvstoreDatabase,omitempty"`
+	KVStoreTable    string `json:"kvstoreTable,omitempty" yaml:"kvstoreTable,omitempty"`
+
+	// HTTPAddress serves HTTP-01 challenges, e.g. ":80". Other requests get redirected
+	// to HTTPS. Empty only uses TLS-ALPN-01.
+	HTTPAddress string `json:"httpAddress,omitempty" yaml:"httpAddress,omitempty"`
+
+	// RenewBefore is the time before expiry certificates get renewed. Defaults to 30 days.
+	RenewBefore config.Duration `json:"renewBefore,omitempty" yaml:"renewBefore,omitempty"`
+
+	// OptKVStore stores the certificates and the account key.
+	OptKVStore kvstore.KVStore `json:"-" yaml:"-"`
+}
+
+// TLSReloadConfig configures reloading of the TLS files.
+type TLSReloadConfig struct {
+	// Enabled watches the files. Defaults to false.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Interval the files get checked for changes in. Defaults to 10s.
+	Interval config.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// OptMetrics reports the days until the certificates expire.
+	OptMetrics metrics.Metrics `json:"-" yaml:"-"`
+}
+
+// HealthConfig configures the health endpoints, an empty path disables its endpoint.
+type HealthConfig struct {
+	// Enabled serves the endpoints. Defaults to false.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Path of the aggregated health. Defaults to "/healthz".
+	Path string `json:"path" yaml:"path"`
+
+	// ReadinessPath of the readiness probe, it reports the same state as Path. Defaults to "/readyz".
+	ReadinessPath string `json:"readinessPath" yaml:"readinessPath"`
+
+	// LivenessPath of the liveness probe, it only reports the state of the entrypoint. Defaults to "/livez".
+	LivenessPath string `json:"livenessPath" yaml:"livenessPath"`
+
+	// Timeout of all checks of one request. Defaults to 5s.
+	Timeout config.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// OptComponents are the components whose state gets reported.
+	OptComponents *types.Components `json:"-" yaml:"-"`
+
+	// OptChecks are additional checks by name.
+	OptChecks map[string]HealthCheck `json:"-" yaml:"-"`
+}
+
+// CORSConfig configures Cross-Origin Resource Sharing.
+type CORSConfig struct {
+	// AllowedOrigins are the allowed origins, "*" allows all origins and
+	// "https://*.example.com" all subdomains. An empty list disables CORS.
+	AllowedOrigins []string `json:"allowedOrigins,omitempty" yaml:"allowedOrigins,omitempty"`
+
+	// AllowedMethods are the methods allowed in preflights.
+	AllowedMethods []string `json:"allowedMethods,omitempty" yaml:"allowedMethods,omitempty"`
+
+	// AllowedHeaders are the request headers allowed in preflights, "*" allows all.
+	AllowedHeaders []string `json:"allowedHeaders,omitempty" yaml:"allowedHeaders,omitempty"`
+
+	// ExposedHeaders are the response headers browsers expose to scripts.
+	ExposedHeaders []string `json:"exposedHeaders,omitempty" yaml:"exposedHeaders,omitempty"`
+
+	// AllowCredentials allows requests with cookies or authorization headers.
+	AllowCredentials bool `json:"allowCredentials,omitempty" yaml:"allowCredentials,omitempty"`
+
+	// MaxAge is the time browsers may cache a preflight response.
+	MaxAge config.Duration `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+}
+
+// WebSocketConfig configures streaming RPCs over WebSockets.
+type WebSocketConfig struct {
+	// Enabled upgrades requests on stream endpoints and publishes an additional
+	// "ws" or "wss" node to the registry. Defaults to false.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// MaxMessageSize is the maximum size of a received message in bytes. Defaults to 4MiB.
+	MaxMessageSize int64 `json:"maxMessageSize,omitempty" yaml:"maxMessageSize,omitempty"`
+}
+
+// CompressionConfig configures response compression.
+type CompressionConfig struct {
+	// Enabled compresses all responses the client accepts a compressed version of.
+	// Responses to compressed requests are always compressed. Defaults to false.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Encodings are the enabled content encodings in order of preference, the client's
+	// Accept-Encoding q-values take precedence. Defaults to zstd, br and gzip.
+	Encodings []string `json:"encodings,omitempty" yaml:"encodings,omitempty"`
+
+	// MinSize is the minimum size of a response in bytes to get compressed. Defaults to 1KiB.
+	MinSize int `json:"minSize" yaml:"minSize"`
+}
+
+// LimitsConfig limits the requests of every route, zero values mean no limit.
+type LimitsConfig struct {
+	// MaxBodySize is the maximum size of a request body in bytes, larger requests
+	// fail with 413. It's also the maximum message size of WebSockets.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+
+	// Timeout is the deadline of the handler context, handlers that return
+	// after it passed fail with 504.
+	Timeout config.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// MaxConcurrent is the maximum number of concurrent requests per route,
+	// requests above it fail with 503.
+	MaxConcurrent int `json:"maxConcurrent,omitempty" yaml:"maxConcurrent,omitempty"`
+
+	// Routes override the limits above for matching routes, the first match wins.
+	Routes []RouteLimitsConfig `json:"routes,omitempty" yaml:"routes,omitempty"`
+}
+
+// RouteLimitsConfig overrides the limits of the routes matching Route, zero values keep the defaults.
+type RouteLimitsConfig struct {
+	// Route is a path.Match pattern of the route path, optionally prefixed by a method,
+	// e.g. "/file.FileService/*" or "GET /v1/books/:name".
+	Route string `json:"route" yaml:"route"`
+
+	MaxBodySize   int64           `json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+	Timeout       config.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MaxConcurrent int             `json:"maxConcurrent,omitempty" yaml:"maxConcurrent,omitempty"`
+}
+
 // NewConfig will create a new default config for the entrypoint.
 func NewConfig(options ...server.Option) *Config {
 	cfg := &Config{
@@ -181,6 +458,29 @@ func NewConfig(options ...server.Option) *Config {
 		ReadTimeout:          config.Duration(DefaultReadTimeout),
 		WriteTimeout:         config.Duration(DefaultWriteTimeout),
 		IdleTimeout:          config.Duration(DefaultIdleTimeout),
+		OpenAPI: OpenAPIConfig{
+			Path:   DefaultOpenAPIPath,
+			UIPath: DefaultOpenAPIUIPath,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: slices.Clone(DefaultCORSAllowedMethods),
+			AllowedHeaders: slices.Clone(DefaultCORSAllowedHeaders),
+		},
+		Compression: CompressionConfig{
+			MinSize: DefaultCompressionMinSize,
+		},
+		WebSocket: WebSocketConfig{
+			MaxMessageSize: DefaultWebSocketMaxMessageSize,
+		},
+		TLSReload: TLSReloadConfig{
+			Interval: config.Duration(DefaultTLSReloadInterval),
+		},
+		Health: HealthConfig{
+			Path:          DefaultHealthPath,
+			ReadinessPath: DefaultReadinessPath,
+			LivenessPath:  DefaultLivenessPath,
+			Timeout:       config.Duration(DefaultHealthTimeout),
+		},
 	}
 
 	for _, option := range options {
@@ -217,7 +517,69 @@ func WithTLS(config *tls.Config) server.Option {
 	return func(c server.EntrypointConfigType) {
 		cfg, ok := c.(*Config)
 		if ok {
-			cfg.TLS = &mtls.Config{Config: config}
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.Config = config
+		}
+	}
+}
+
+// WithTLSFiles loads the certificate and key from files on start,
+// use WithTLSReload to swap them when they change.
+func WithTLSFiles(certFile, keyFile string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.ConfigFiles.Certificates = append(cfg.TLS.ConfigFiles.Certificates, struct {
+				CertFile string `json:"certFile" yaml:"certFile"`
+				KeyFile  string `json:"keyFile"  yaml:"keyFile"`
+			}{CertFile: certFile, KeyFile: keyFile})
+		}
+	}
+}
+
+// WithMTLS requires client certificates and verifies them against the CAs in
+// clientCAFiles, handlers get the identity of the client in the incoming metadata.
+func WithMTLS(clientCAFiles ...string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.TLS == nil {
+				cfg.TLS = &mtls.Config{}
+			}
+
+			cfg.TLS.ConfigFiles.ClientCAFiles = append(cfg.TLS.ConfigFiles.ClientCAFiles, clientCAFiles...)
+			cfg.TLS.ConfigFiles.ClientAuth = mtls.ClientAuth{ClientAuthType: tls.RequireAndVerifyClientCert}
+		}
+	}
+}
+
+// WithTLSReload swaps the TLS files when they change, they get checked every interval.
+func WithTLSReload(interval time.Duration) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.TLSReload.Enabled = true
+
+			if interval > 0 {
+				cfg.TLSReload.Interval = config.Duration(interval)
+			}
+		}
+	}
+}
+
+// WithTLSReloadMetrics reports the days until the TLS certificates expire to m.
+func WithTLSReloadMetrics(m metrics.Metrics) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.TLSReload.OptMetrics = m
 		}
 	}
 }
@@ -258,6 +620,31 @@ func WithDisableHTTP2() server.Option {
 	}
 }
 
+// WithCompression enables response compression, encodings are the enabled
+// content encodings in order of preference, none keeps the defaults.
+func WithCompression(encodings ...string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Compression.Enabled = true
+
+			if len(encodings) > 0 {
+				cfg.Compression.Encodings = encodings
+			}
+		}
+	}
+}
+
+// WithCompressionMinSize sets the minimum size of a response in bytes to get compressed.
+func WithCompressionMinSize(size int) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Compression.MinSize = size
+		}
+	}
+}
+
 // WithGzip enables gzip response compression server wide onall responses.
 // Only use this if your messages are sufficiently large. For small messages
 // the compute overhead is not worth the reduction in transport time.
@@ -359,3 +746,183 @@ func WithLogPlugin(plugin string) server.Option {
 		}
 	}
 }
+
+// WithRegion sets the region this entrypoint advertises in the registry.
+func WithRegion(region string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Region = region
+		}
+	}
+}
+
+// WithZone sets the zone this entrypoint advertises in the registry.
+func WithZone(zone string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Zone = zone
+		}
+	}
+}
+
+// WithOpenAPI serves the merged OpenAPI document of the registered services at
+// path and a viewer at uiPath. An empty path uses the default, an empty uiPath
+// disables the viewer.
+func WithOpenAPI(path, uiPath string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.OpenAPI.Enabled = true
+			cfg.OpenAPI.UIPath = uiPath
+
+			if path != "" {
+				cfg.OpenAPI.Path = path
+			}
+		}
+	}
+}
+
+// WithCORS enables CORS for the given origins, "*" allows all origins and
+// "https://*.example.com" all subdomains.
+func WithCORS(origins ...string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.CORS.AllowedOrigins = append(cfg.CORS.AllowedOrigins, origins...)
+		}
+	}
+}
+
+// WithCORSConfig sets the full CORS config.
+func WithCORSConfig(cors CORSConfig) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.CORS = cors
+		}
+	}
+}
+
+// WithWebSocket serves streaming RPCs over WebSockets.
+func WithWebSocket() server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.WebSocket.Enabled = true
+		}
+	}
+}
+
+// WithLimits sets the limits of all routes.
+func WithLimits(maxBodySize int64, timeout time.Duration, maxConcurrent int) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Limits.MaxBodySize = maxBodySize
+			cfg.Limits.Timeout = config.Duration(timeout)
+			cfg.Limits.MaxConcurrent = maxConcurrent
+		}
+	}
+}
+
+// WithRouteLimits overrides the limits of the routes matching route, see RouteLimitsConfig.
+func WithRouteLimits(route string, maxBodySize int64, timeout time.Duration, maxConcurrent int) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Limits.Routes = append(cfg.Limits.Routes, RouteLimitsConfig{
+				Route:         route,
+				MaxBodySize:   maxBodySize,
+				Timeout:       config.Duration(timeout),
+				MaxConcurrent: maxConcurrent,
+			})
+		}
+	}
+}
+
+// WithHealth serves the health endpoints with the state of the components,
+// components may be nil to only report the entrypoint and the checks.
+func WithHealth(components *types.Components) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Health.Enabled = true
+			cfg.Health.OptComponents = components
+		}
+	}
+}
+
+// WithHealthCheck adds a check to the health and readiness endpoints.
+func WithHealthCheck(name string, check HealthCheck) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			if cfg.Health.OptChecks == nil {
+				cfg.Health.OptChecks = make(map[string]HealthCheck)
+			}
+
+			cfg.Health.OptChecks[name] = check
+		}
+	}
+}
+
+// WithDrain sets the delay before the listeners get closed on Stop, and the
+// maximum time to wait for in-flight requests afterwards.
+func WithDrain(delay, timeout time.Duration) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.DrainDelay = config.Duration(delay)
+			cfg.DrainTimeout = config.Duration(timeout)
+		}
+	}
+}
+
+// WithACME gets the certificates of domains from an ACME CA, they get stored in cacheDir.
+func WithACME(email, cacheDir string, domains ...string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.ACME.Enabled = true
+			cfg.ACME.Email = email
+			cfg.ACME.CacheDir = cacheDir
+			cfg.ACME.Domains = append(cfg.ACME.Domains, domains...)
+		}
+	}
+}
+
+// WithACMEDirectory sets the directory of the ACME CA, caFile is the PEM root
+// of its certificate and may be empty.
+func WithACMEDirectory(url, caFile string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.ACME.DirectoryURL = url
+			cfg.ACME.DirectoryCAFile = caFile
+		}
+	}
+}
+
+// WithACMEKVStore stores the ACME certificates and the account key in a kvstore.
+func WithACMEKVStore(store kvstore.KVStore, database, table string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.ACME.OptKVStore = store
+			cfg.ACME.KVStoreDatabase = database
+			cfg.ACME.KVStoreTable = table
+		}
+	}
+}
+
+// WithACMEHTTPChallenge serves HTTP-01 challenges on address, e.g. ":80".
+func WithACMEHTTPChallenge(address string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.ACME.HTTPAddress = address
+		}
+	}
+}
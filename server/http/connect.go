@@ -0,0 +1,276 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/util/orberrors"
+
+	"github.com/go-orb/plugins/server/http/headers"
+)
+
+// Connect protocol headers and content types, see https://connectrpc.com/docs/protocol.
+const (
+	ConnectProtocolVersionHeader = "Connect-Protocol-Version"
+	ConnectTimeoutHeader         = "Connect-Timeout-Ms"
+
+	// ConnectStreamContentType gets a "+json" or "+proto" suffix for streaming requests.
+	ConnectStreamContentType = "application/connect"
+)
+
+// connectFlagEndStream marks the last envelope of a Connect stream.
+const connectFlagEndStream byte = 0x02
+
+// maxConnectTimeoutDigits is the maximum length of Connect-Timeout-Ms.
+const maxConnectTimeoutDigits = 10
+
+// connectCodes are the names of the gRPC codes in Connect errors.
+//
+//nolint:gochecknoglobals
+var connectCodes = map[int]string{
+	grpcCodeCanceled:          "canceled",
+	grpcCodeInvalidArgument:   "invalid_argument",
+	grpcCodeDeadlineExceeded:  "deadline_exceeded",
+	grpcCodeNotFound:          "not_found",
+	grpcCodeAlreadyExists:     "already_exists",
+	grpcCodePermissionDenied:  "permission_denied",
+	grpcCodeResourceExhausted: "resource_exhausted",
+	grpcCodeUnimplemented:     "unimplemented",
+	grpcCodeInternal:          "internal",
+	grpcCodeUnavailable:       "unavailable",
+	grpcCodeUnauthenticated:   "unauthenticated",
+}
+
+// connectHTTPStatus maps the Connect codes to the status of unary error responses.
+//
+//nolint:gochecknoglobals
+var connectHTTPStatus = map[string]int{
+	"canceled":           499,
+	"invalid_argument":   http.StatusBadRequest,
+	"deadline_exceeded":  http.StatusGatewayTimeout,
+	"not_found":          http.StatusNotFound,
+	"already_exists":     http.StatusConflict,
+	"permission_denied":  http.StatusForbidden,
+	"resource_exhausted": http.StatusTooManyRequests,
+	"unimplemented":      http.StatusNotImplemented,
+	"internal":           http.StatusInternalServerError,
+	"unavailable":        http.StatusServiceUnavailable,
+	"unauthenticated":    http.StatusUnauthorized,
+}
+
+// ErrConnectTimeout is returned for an invalid Connect-Timeout-Ms header.
+var ErrConnectTimeout = orberrors.ErrBadRequest.WrapNew("invalid " + ConnectTimeoutHeader + " header")
+
+// connectError is the JSON body of a failed unary call and the error of an end-stream message.
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// connectEndStream is the payload of the last envelope of a Connect stream.
+type connectEndStream struct {
+	Error    *connectError       `json:"error,omitempty"`
+	Metadata map[string][]string `json:"metadata,omitempty"`
+}
+
+func newConnectError(err error) *connectError {
+	orbe := orberrors.From(err)
+
+	code := connectCodes[grpcCode(orbe)]
+	if errors.Is(err, context.DeadlineExceeded) {
+		// orberrors turns them into a request timeout, which has no gRPC code.
+		code = "deadline_exceeded"
+	}
+
+	return &connectError{Code: code, Message: orbe.Error()}
+}
+
+// WriteConnectError writes err as Connect error with the status code of its Connect code.
+func WriteConnectError(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+
+	cErr := newConnectError(err)
+
+	w.Header().Set(headers.ContentType, headers.JSONContentType)
+	w.WriteHeader(connectHTTPStatus[cErr.Code])
+
+	_ = json.NewEncoder(w).Encode(cErr) //nolint:errcheck
+}
+
+// isConnectUnary reports whether the request is a unary Connect call,
+// their bodies use the regular codecs.
+func isConnectUnary(req *http.Request) bool {
+	return req.Method == http.MethodPost && req.Header.Get(ConnectProtocolVersionHeader) != ""
+}
+
+// connectStreamMode returns the codec of a Connect streaming request, ok is false for other requests.
+func connectStreamMode(req *http.Request) (contentType string, codec codecs.Marshaler, ok bool) {
+	if req.Method != http.MethodPost {
+		return "", nil, false
+	}
+
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(req.Header.Get(headers.ContentType), ";")[0]))
+
+	subtype, ok := strings.CutPrefix(contentType, ConnectStreamContentType+"+")
+	if !ok {
+		return "", nil, false
+	}
+
+	mime := codecs.MimeProto
+	if subtype == "json" {
+		mime = codecs.MimeJSON
+	}
+
+	codec, err := codecs.GetMime(mime)
+	if err != nil {
+		return contentType, nil, true
+	}
+
+	return contentType, codec, true
+}
+
+// withConnectTimeout applies the Connect-Timeout-Ms header to the context of the request.
+func withConnectTimeout(req *http.Request) (*http.Request, context.CancelFunc, error) {
+	value := req.Header.Get(ConnectTimeoutHeader)
+	if value == "" {
+		return req, func() {}, nil
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms < 0 || len(value) > maxConnectTimeoutDigits {
+		return req, func() {}, ErrConnectTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), time.Duration(ms)*time.Millisecond)
+
+	return req.WithContext(ctx), cancel, nil
+}
+
+// serveConnect serves a unary Connect call, the body is decoded and encoded like any other request.
+func serveConnect[Tin any, Tout any](
+	srv *Server,
+	resp http.ResponseWriter,
+	req *http.Request,
+	fHandler func(context.Context, *Tin) (*Tout, error),
+	service string,
+	method string,
+) {
+	req, cancel, err := withConnectTimeout(req)
+	if err != nil {
+		WriteConnectError(resp, err)
+		return
+	}
+	defer cancel()
+
+	inBody := new(Tin)
+
+	if _, err := srv.decodeBody(resp, req, inBody); err != nil {
+		srv.logger.Error("failed to decode request body", "error", err)
+		WriteConnectError(resp, requestError(err))
+
+		return
+	}
+
+	ctx, outMd := incomingContext(req, service, method)
+
+	out, err := callRPC(srv, ctx, inBody, fHandler)
+	if err != nil {
+		srv.logger.Error("RPC request failed", "error", err)
+		WriteConnectError(resp, err)
+
+		return
+	}
+
+	// Write outgoing metadata.
+	for k, v := range outMd {
+		resp.Header().Set(k, v)
+	}
+
+	if err := srv.encodeBody(resp, req, out); err != nil {
+		srv.logger.Error("failed to encode response body", "error", err)
+		WriteConnectError(resp, err)
+	}
+}
+
+// serveConnectServerStream serves a server-streaming Connect call.
+func serveConnectServerStream[Tin any, Tout any](
+	srv *Server,
+	resp http.ResponseWriter,
+	req *http.Request,
+	fHandler func(*Tin, ServerStream[Tout]) error,
+	service string,
+	method string,
+) {
+	contentType, codec, _ := connectStreamMode(req)
+
+	w := &grpcWebWriter{
+		resp:        resp,
+		rc:          http.NewResponseController(resp),
+		codec:       codec,
+		connect:     true,
+		contentType: contentType,
+	}
+
+	req, cancel, err := withConnectTimeout(req)
+	if err != nil {
+		w.finish(nil, err)
+		return
+	}
+	defer cancel()
+
+	inBody, ok := readEnvelopeRequest[Tin](srv, w, req)
+	if !ok {
+		return
+	}
+
+	ctx, outMd := incomingContext(req, service, method)
+
+	stream := &grpcWebStream[Tout]{ctx: ctx, w: w, outMd: outMd}
+
+	err = timeoutError(ctx, fHandler(inBody, stream))
+	if err != nil && ctx.Err() == nil {
+		srv.logger.Error("RPC request failed", "error", err)
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	w.setHeaders(outMd)
+	w.finish(outMd, err)
+}
+
+// finishConnect writes the end-stream envelope with err and the metadata not sent as headers.
+func (w *grpcWebWriter) finishConnect(md map[string]string, err error) {
+	end := connectEndStream{}
+
+	if err != nil {
+		end.Error = newConnectError(err)
+	}
+
+	for k, v := range md {
+		if w.sent[strings.ToLower(k)] {
+			continue
+		}
+
+		if end.Metadata == nil {
+			end.Metadata = make(map[string][]string)
+		}
+
+		end.Metadata[strings.ToLower(k)] = []string{v}
+	}
+
+	data, mErr := json.Marshal(end)
+	if mErr != nil {
+		data = []byte(`{"error":{"code":"internal"}}`)
+	}
+
+	_ = w.writeFrame(connectFlagEndStream, data) //nolint:errcheck
+}
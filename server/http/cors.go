@@ -0,0 +1,205 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS headers.
+const (
+	headerOrigin                        = "Origin"
+	headerVary                          = "Vary"
+	headerAccessControlRequestMethod    = "Access-Control-Request-Method"
+	headerAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	headerAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	headerAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	headerAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+// cors answers CORS preflights for all routes of the router and decorates its responses.
+type cors struct {
+	router *Router
+
+	allowAll bool
+	origins  []string
+	// wildcards are origins with a single "*", split into prefix and suffix.
+	wildcards [][2]string
+
+	methods        []string
+	allowHeaders   bool
+	headers        []string
+	exposedHeaders string
+	credentials    bool
+	maxAge         string
+}
+
+func newCORS(cfg CORSConfig, router *Router) *cors {
+	c := &cors{
+		router:         router,
+		methods:        make([]string, 0, len(cfg.AllowedMethods)),
+		headers:        make([]string, 0, len(cfg.AllowedHeaders)),
+		exposedHeaders: strings.Join(cfg.ExposedHeaders, ", "),
+		credentials:    cfg.AllowCredentials,
+	}
+
+	for _, o := range cfg.AllowedOrigins {
+		o = strings.ToLower(o)
+
+		switch {
+		case o == "*":
+			c.allowAll = true
+		case strings.Count(o, "*") == 1:
+			prefix, suffix, _ := strings.Cut(o, "*")
+			c.wildcards = append(c.wildcards, [2]string{prefix, suffix})
+		default:
+			c.origins = append(c.origins, o)
+		}
+	}
+
+	for _, m := range cfg.AllowedMethods {
+		c.methods = append(c.methods, strings.ToUpper(m))
+	}
+
+	for _, h := range cfg.AllowedHeaders {
+		if h == "*" {
+			c.allowHeaders = true
+			continue
+		}
+
+		c.headers = append(c.headers, http.CanonicalHeaderKey(h))
+	}
+
+	if cfg.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(time.Duration(cfg.MaxAge).Seconds()))
+	}
+
+	return c
+}
+
+func (c *cors) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get(headerOrigin)
+	if origin == "" {
+		c.router.ServeHTTP(w, r)
+		return
+	}
+
+	if r.Method == http.MethodOptions && r.Header.Get(headerAccessControlRequestMethod) != "" {
+		c.preflight(w, r, origin)
+		return
+	}
+
+	w.Header().Add(headerVary, headerOrigin)
+
+	if c.originAllowed(origin) {
+		c.setOrigin(w, origin)
+
+		if c.exposedHeaders != "" {
+			w.Header().Set(headerAccessControlExposeHeaders, c.exposedHeaders)
+		}
+	}
+
+	c.router.ServeHTTP(w, r)
+}
+
+// preflight answers a preflight request, routes that don't exist get a 404.
+func (c *cors) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	method := strings.ToUpper(r.Header.Get(headerAccessControlRequestMethod))
+
+	if h, _, _ := c.router.httprouter.Lookup(method, r.URL.Path); h == nil {
+		c.router.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Add(headerVary, headerOrigin)
+	w.Header().Add(headerVary, headerAccessControlRequestMethod)
+	w.Header().Add(headerVary, headerAccessControlRequestHeaders)
+
+	requested := parseHeaderList(r.Header.Get(headerAccessControlRequestHeaders))
+
+	if !c.originAllowed(origin) || !c.methodAllowed(method) || !c.headersAllowed(requested) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	c.setOrigin(w, origin)
+	w.Header().Set(headerAccessControlAllowMethods, method)
+
+	if len(requested) > 0 {
+		w.Header().Set(headerAccessControlAllowHeaders, strings.Join(requested, ", "))
+	}
+
+	if c.maxAge != "" {
+		w.Header().Set(headerAccessControlMaxAge, c.maxAge)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *cors) setOrigin(w http.ResponseWriter, origin string) {
+	if c.allowAll && !c.credentials {
+		w.Header().Set(headerAccessControlAllowOrigin, "*")
+	} else {
+		w.Header().Set(headerAccessControlAllowOrigin, origin)
+	}
+
+	if c.credentials {
+		w.Header().Set(headerAccessControlAllowCredentials, "true")
+	}
+}
+
+func (c *cors) originAllowed(origin string) bool {
+	if c.allowAll {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	if slices.Contains(c.origins, origin) {
+		return true
+	}
+
+	for _, w := range c.wildcards {
+		if len(origin) >= len(w[0])+len(w[1]) && strings.HasPrefix(origin, w[0]) && strings.HasSuffix(origin, w[1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *cors) methodAllowed(method string) bool {
+	return method == http.MethodOptions || slices.Contains(c.methods, method)
+}
+
+func (c *cors) headersAllowed(requested []string) bool {
+	if c.allowHeaders {
+		return true
+	}
+
+	for _, h := range requested {
+		if !slices.Contains(c.headers, h) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseHeaderList parses a comma separated list of header names into canonical keys.
+func parseHeaderList(value string) []string {
+	result := []string{}
+
+	for _, h := range strings.Split(value, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			result = append(result, http.CanonicalHeaderKey(h))
+		}
+	}
+
+	return result
+}
@@ -18,6 +18,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"log/slog"
 
@@ -29,9 +32,12 @@ import (
 	mtls "github.com/go-orb/go-orb/util/tls"
 
 	mtcp "github.com/go-orb/plugins/server/http/utils/tcp"
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
 	mudp "github.com/go-orb/plugins/server/http/utils/udp"
+	"github.com/go-orb/plugins/server/srvutil"
 
 	"github.com/lithammer/shortuuid/v4"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 const networkUnix = "unix"
@@ -63,15 +69,38 @@ type Server struct {
 	router  *Router
 	handler http.Handler
 
+	// acme manages the certificates when ACME is enabled, acmeServer answers HTTP-01 challenges.
+	acme       *autocert.Manager
+	acmeServer *http.Server
+
+	// tlsReloader serves the certificates of the TLS files.
+	tlsReloader *utls.Reloader
+
+	// cors is set when CORS is enabled, WebSocket upgrades use it to check origins.
+	cors *cors
+
+	// openapi is the merged OpenAPI document of the registered services.
+	openapi *openAPI
+
 	httpServer  *httpServer
 	http3Server *http3server
 
 	listenerUDP net.PacketConn
 	listenerTCP net.Listener
 
-	started bool
+	started atomic.Bool
+
+	// healthMu guards the health checks added after creation.
+	healthMu sync.RWMutex
+
+	// activeRequests are the requests and streams in-flight.
+	activeRequests atomic.Int64
+	// draining is set while the entrypoint stops, the readiness probe fails.
+	draining atomic.Bool
 
-	activeRequests int64 // accessed atomically
+	// baseCtx is the parent of all request contexts, cancelBase aborts them on stop.
+	baseCtx    context.Context //nolint:containedctx
+	cancelBase context.CancelFunc
 }
 
 // Provide creates a new entrypoint for a single address. You can create
@@ -93,6 +122,13 @@ func Provide(
 		return nil, err
 	}
 
+	mws, err := srvutil.Middlewares(cfg.Middlewares, configData, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.OptMiddlewares = append(cfg.OptMiddlewares, mws...)
+
 	return New(serviceName, serviceVersion, epName, cfg, logger, reg)
 }
 
@@ -111,6 +147,7 @@ func New(
 	}
 
 	router := NewRouter(logger)
+	router.limits = &cfg.Limits
 
 	entrypoint := Server{
 		id:             shortuuid.New(),
@@ -121,6 +158,7 @@ func New(
 		logger:         logger,
 		registry:       reg,
 		router:         router,
+		openapi:        newOpenAPI(),
 	}
 
 	return &entrypoint, nil
@@ -130,7 +168,7 @@ func New(
 //
 //nolint:gocyclo,funlen
 func (s *Server) Start(ctx context.Context) error {
-	if s.started {
+	if s.started.Load() {
 		return nil
 	}
 
@@ -143,6 +181,14 @@ func (s *Server) Start(ctx context.Context) error {
 		s.Register(f)
 	}
 
+	if err := s.registerOpenAPI(); err != nil {
+		return err
+	}
+
+	if err := s.registerHealth(); err != nil {
+		return err
+	}
+
 	if s.config.Network == networkUnix { //nolint:nestif
 		s.config.Insecure = true
 
@@ -181,8 +227,15 @@ func (s *Server) Start(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+
+		if err := s.startACMEChallenges(); err != nil {
+			_ = s.listenerTCP.Close() //nolint:errcheck
+			return err
+		}
 	}
 
+	s.baseCtx, s.cancelBase = context.WithCancel(context.Background())
+
 	s.httpServer, err = s.newHTTPServer(s.router)
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP server: %w", err)
@@ -203,7 +256,7 @@ func (s *Server) Start(ctx context.Context) error {
 			return fmt.Errorf("failed to register the HTTP server: %w", err)
 		}
 
-		s.started = true
+		s.started.Store(true)
 
 		return nil
 	}
@@ -226,32 +279,45 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to register the HTTP server: %w", err)
 	}
 
-	s.started = true
+	s.started.Store(true)
 
 	return nil
 }
 
-// Stop will stop the HTTP server(s).
+// Stop drains and stops the HTTP server(s).
+//
+// It deregisters the entrypoint, fails the readiness probe, waits DrainDelay for that
+// to propagate, stops accepting connections and waits for in-flight requests and
+// streams up to DrainTimeout before closing the remaining connections.
 func (s *Server) Stop(ctx context.Context) error {
-	if !s.started {
+	if !s.started.Load() {
 		return nil
 	}
 
-	errChan := make(chan error)
-	defer close(errChan)
-
 	s.logger.Debug("Stopping")
 
-	if err := s.registryDeregister(ctx); err != nil {
-		return err
+	err := s.registryDeregister(ctx)
+	if err != nil {
+		s.logger.Error("failed to deregister the HTTP server", "error", err)
 	}
 
+	s.draining.Store(true)
+	defer s.draining.Store(false)
+
+	srvutil.SleepContext(ctx, time.Duration(s.config.DrainDelay))
+
+	drainCtx, cancel := srvutil.DrainContext(ctx, time.Duration(s.config.DrainTimeout))
+	defer cancel()
+
+	errChan := make(chan error)
+	defer close(errChan)
+
 	c := 1
-	if s.config.HTTP3 {
+	if s.http3Server != nil {
 		c++
 
 		go func() {
-			errChan <- s.http3Server.Stop(ctx)
+			errChan <- s.http3Server.Stop(drainCtx)
 
 			// Listener most likely already closed, just as a double check.
 			_ = s.listenerUDP.Close() //nolint:errcheck
@@ -263,21 +329,39 @@ func (s *Server) Stop(ctx context.Context) error {
 	}
 
 	go func(srv stopper, l net.Listener) {
-		errChan <- srv.Stop(ctx)
+		errChan <- srv.Stop(drainCtx)
 
 		// Listener most likely already closed, just as a double check.
 		_ = l.Close() //nolint:errcheck
 	}(s.httpServer, s.listenerTCP)
 
-	var err error
+	forced := false
 
 	for i := 0; i < c; i++ {
 		if nerr := <-errChan; nerr != nil {
-			err = nerr
+			forced = true
 		}
 	}
 
-	s.started = false
+	// Hijacked connections like WebSockets aren't tracked by the servers.
+	if !srvutil.WaitIdle(drainCtx, &s.activeRequests) {
+		forced = true
+	}
+
+	// Aborts the handlers still running.
+	s.cancelBase()
+
+	s.stopACMEChallenges(ctx)
+
+	if s.tlsReloader != nil {
+		s.tlsReloader.Stop()
+	}
+
+	if forced {
+		s.logger.Warn("Closed the HTTP server before all requests finished", "active", s.activeRequests.Load())
+	}
+
+	s.started.Store(false)
 
 	return err
 }
@@ -314,12 +398,16 @@ func (s *Server) Address() string {
 // Transport returns the client transport to use.
 func (s *Server) Transport() string {
 	switch {
+	case s.config.Network == networkUnix && s.config.H2C:
+		return "unix+h2c"
 	case s.config.Network == networkUnix:
 		return "unix+http"
 	case s.config.HTTP3:
 		return "http3"
 	case !s.config.Insecure:
 		return "https"
+	case s.config.H2C:
+		return "h2c"
 	default:
 		return "http"
 	}
@@ -351,7 +439,27 @@ func (s *Server) Router() *Router {
 	return s.router
 }
 
+// Panics returns the number of panics recovered by the entrypoint, e.g. for alerting.
+func (s *Server) Panics() uint64 {
+	return s.router.Panics()
+}
+
 func (s *Server) setupTLS() (*mtls.Config, error) {
+	// Certificates from an ACME CA, the config is kept on restarts.
+	if s.config.ACME.Enabled && s.acme == nil {
+		config, err := s.setupACME()
+		if err != nil {
+			return nil, err
+		}
+
+		return &mtls.Config{Config: config}, nil
+	}
+
+	// Certificates and CAs from files, they get swapped when they change.
+	if s.tlsReloader != nil || s.tlsFromFiles() {
+		return s.setupTLSReload()
+	}
+
 	// TLS already provided or not needed.
 	if s.config.TLS != nil || s.config.Insecure {
 		return s.config.TLS, nil
@@ -373,22 +481,115 @@ func (s *Server) setupTLS() (*mtls.Config, error) {
 	return &mtls.Config{Config: config}, nil
 }
 
+// tlsFromFiles reports whether the TLS config gets served from its files.
+func (s *Server) tlsFromFiles() bool {
+	return !s.config.Insecure && s.config.TLS != nil && !utls.FilesFromConfig(s.config.TLS.ConfigFiles).Empty()
+}
+
+// setupTLSReload returns the TLS config of the reloader, the config is kept on restarts.
+func (s *Server) setupTLSReload() (*mtls.Config, error) {
+	if s.tlsReloader == nil {
+		files := s.config.TLS.ConfigFiles
+
+		var base *tls.Config
+
+		switch {
+		case s.config.TLS.Config != nil:
+			base = s.config.TLS.Config.Clone()
+		case len(files.Certificates) == 0:
+			// Only client CAs, generate a self signed certificate.
+			config, err := mtls.GenTLSConfig(s.config.Address)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate self signed certificate: %w", err)
+			}
+
+			base = config
+		default:
+			base = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+
+		if files.ClientAuth.ClientAuthType != tls.NoClientCert {
+			base.ClientAuth = files.ClientAuth.ClientAuthType
+		}
+
+		reloader, err := utls.NewReloader(
+			utls.FilesFromConfig(files),
+			utls.WithReloadInterval(time.Duration(s.config.TLSReload.Interval)),
+			utls.WithReloadLogger(s.logger),
+			utls.WithReloadMetrics(s.config.TLSReload.OptMetrics),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS files: %w", err)
+		}
+
+		s.tlsReloader = reloader
+		s.config.TLS = &mtls.Config{ConfigFiles: files, Config: reloader.ServerConfig(base)}
+	}
+
+	if s.config.TLSReload.Enabled {
+		s.tlsReloader.Start()
+	}
+
+	return s.config.TLS, nil
+}
+
 func (s *Server) registryService() registry.ServiceNode {
-	return registry.ServiceNode{
+	node := registry.ServiceNode{
 		Name:     s.serviceName,
 		Version:  s.serviceVersion,
 		Node:     s.id,
 		Address:  s.Address(),
 		Network:  s.Network(),
 		Scheme:   s.Transport(),
+		Region:   s.config.Region,
 		Metadata: make(map[string]string),
 	}
+
+	if s.config.Zone != "" {
+		node.Metadata[srvutil.MetadataZone] = s.config.Zone
+	}
+
+	return node
+}
+
+// registryWebSocketService returns the node for the "ws" or "wss" transport,
+// ok is false if WebSockets are disabled or not reachable by clients.
+func (s *Server) registryWebSocketService() (registry.ServiceNode, bool) {
+	if !s.config.WebSocket.Enabled || s.config.Network == networkUnix {
+		return registry.ServiceNode{}, false
+	}
+
+	node := s.registryService()
+	node.Scheme = "ws"
+
+	if !s.config.Insecure {
+		node.Scheme = "wss"
+	}
+
+	// Registries key nodes by their name, so it needs its own.
+	node.Node = s.id + "-" + node.Scheme
+
+	return node, true
 }
 
 func (s *Server) registryRegister(ctx context.Context) error {
-	return s.registry.Register(ctx, s.registryService())
+	if err := s.registry.Register(ctx, s.registryService()); err != nil {
+		return err
+	}
+
+	if node, ok := s.registryWebSocketService(); ok {
+		return s.registry.Register(ctx, node)
+	}
+
+	return nil
 }
 
 func (s *Server) registryDeregister(ctx context.Context) error {
+	if node, ok := s.registryWebSocketService(); ok {
+		if err := s.registry.Deregister(ctx, node); err != nil {
+			return err
+		}
+	}
+
 	return s.registry.Deregister(ctx, s.registryService())
 }
@@ -1,6 +1,16 @@
 package http
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/util/orberrors"
+
+	"github.com/go-orb/plugins/server/http/headers"
+	"github.com/go-orb/plugins/server/http/utils/header"
+	"github.com/go-orb/plugins/server/http/wire"
+)
 
 // Errors.
 var (
@@ -8,3 +18,77 @@ var (
 	ErrContentTypeNotSupported = errors.New("content type not supported")
 	ErrInvalidConfigType       = errors.New("http server: invalid config type provided, not of type http.Config")
 )
+
+// ErrorBody is the payload of error responses written by WriteError.
+type ErrorBody = wire.ErrorBody
+
+// ErrorDetail is a wrapped orberror of an ErrorBody.
+type ErrorDetail = wire.ErrorDetail
+
+// NewErrorBody converts err into an ErrorBody.
+func NewErrorBody(err error, requestID string) *ErrorBody {
+	return wire.NewErrorBody(err, requestID)
+}
+
+// WriteError returns an error response to the HTTP request.
+//
+// The ErrorBody is encoded with the codec negotiated from the Accept header of the
+// request, JSON is used when there is none or it can't encode the body, e.g. proto.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+
+	requestID := w.Header().Get(headers.RequestID)
+	contentType := headers.JSONContentType
+
+	if r != nil {
+		if requestID == "" {
+			requestID = r.Header.Get(headers.RequestID)
+		}
+
+		contentType = header.GetAcceptType(r.Header.Get(headers.Accept), r.Header.Get(headers.ContentType))
+	}
+
+	body := NewErrorBody(err, requestID)
+
+	codec, cErr := codecs.GetMime(contentType)
+	if cErr != nil || !codec.Marshals(body) {
+		contentType = headers.JSONContentType
+		codec, cErr = codecs.GetMime(contentType)
+	}
+
+	var data []byte
+	if cErr == nil {
+		data, cErr = codec.Marshal(body)
+	}
+
+	status := body.Code
+	if status < 100 || status > 999 {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Del(headers.ContentEncoding)
+
+	if cErr != nil {
+		// No codec for the body, fall back to the message.
+		w.Header().Set(headers.ContentType, "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		w.Write([]byte(orberrors.From(err).Error())) //nolint:errcheck,gosec
+
+		return
+	}
+
+	w.Header().Set(headers.ContentType, contentType)
+	w.WriteHeader(status)
+	w.Write(data) //nolint:errcheck,gosec
+}
+
+// setRequestID copies the X-Request-Id of the outgoing metadata or the request to the response.
+func setRequestID(resp http.ResponseWriter, req *http.Request, outMd map[string]string) {
+	if id, ok := outMd["x-request-id"]; ok {
+		resp.Header().Set(headers.RequestID, id)
+	} else if id := req.Header.Get(headers.RequestID); id != "" {
+		resp.Header().Set(headers.RequestID, id)
+	}
+}
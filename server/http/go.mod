@@ -3,6 +3,8 @@ module github.com/go-orb/plugins/server/http
 go 1.23.6
 
 require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/coder/websocket v1.8.13
 	github.com/go-orb/go-orb v0.4.1
 	github.com/go-orb/plugins/codecs/form v0.2.0
 	github.com/go-orb/plugins/codecs/json v0.2.0
@@ -11,11 +13,14 @@ require (
 	github.com/go-orb/plugins/config/source/file v0.2.0
 	github.com/go-orb/plugins/log/slog v0.2.0
 	github.com/go-orb/plugins/registry/mdns v0.1.1
+	github.com/go-orb/plugins/server/srvutil v0.1.0
 	github.com/julienschmidt/httprouter v1.3.0
+	github.com/klauspost/compress v1.18.0
 	github.com/lithammer/shortuuid/v4 v4.2.0
 	github.com/pkg/errors v0.9.1
 	github.com/quic-go/quic-go v0.50.0
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/crypto v0.36.0
 	golang.org/x/net v0.38.0
 	google.golang.org/protobuf v1.36.6
 )
@@ -37,7 +42,6 @@ require (
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/quic-go/qpack v0.5.1 // indirect
 	go.uber.org/mock v0.5.0 // indirect
-	golang.org/x/crypto v0.36.0 // indirect
 	golang.org/x/exp v0.0.0-20250305212735-054e65f0b394 // indirect
 	golang.org/x/mod v0.24.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
@@ -0,0 +1,479 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/util/orberrors"
+
+	"github.com/go-orb/plugins/server/http/headers"
+)
+
+// gRPC-Web content types, "+proto" and "+json" select the codec, proto is the default.
+const (
+	GRPCWebContentType     = "application/grpc-web"
+	GRPCWebTextContentType = "application/grpc-web-text"
+)
+
+// gRPC-Web frame flags.
+const (
+	grpcWebFlagData       byte = 0x00
+	grpcWebFlagCompressed byte = 0x01
+	grpcWebFlagTrailer    byte = 0x80
+)
+
+// grpcWebFrameHeaderLen is the length of the flag and the message length.
+const grpcWebFrameHeaderLen = 5
+
+// gRPC status codes, https://grpc.github.io/grpc/core/md_doc_statuscodes.html.
+const (
+	grpcCodeOK                = 0
+	grpcCodeCanceled          = 1
+	grpcCodeInvalidArgument   = 3
+	grpcCodeDeadlineExceeded  = 4
+	grpcCodeNotFound          = 5
+	grpcCodeAlreadyExists     = 6
+	grpcCodePermissionDenied  = 7
+	grpcCodeResourceExhausted = 8
+	grpcCodeUnimplemented     = 12
+	grpcCodeInternal          = 13
+	grpcCodeUnavailable       = 14
+	grpcCodeUnauthenticated   = 16
+)
+
+// httpStatusToGRPCCode is the same mapping as server/grpc's HTTPStatusToCode.
+//
+//nolint:gochecknoglobals
+var httpStatusToGRPCCode = map[int]int{
+	http.StatusOK:                  grpcCodeOK,
+	499:                            grpcCodeCanceled,
+	http.StatusBadRequest:          grpcCodeInvalidArgument,
+	http.StatusInternalServerError: grpcCodeInternal,
+	http.StatusGatewayTimeout:      grpcCodeDeadlineExceeded,
+	http.StatusNotFound:            grpcCodeNotFound,
+	http.StatusConflict:            grpcCodeAlreadyExists,
+	http.StatusForbidden:           grpcCodePermissionDenied,
+	http.StatusUnauthorized:        grpcCodeUnauthenticated,
+	http.StatusTooManyRequests:     grpcCodeResourceExhausted,
+	http.StatusNotImplemented:      grpcCodeUnimplemented,
+	http.StatusServiceUnavailable:  grpcCodeUnavailable,
+}
+
+// Errors.
+var (
+	ErrGRPCWebFrame      = orberrors.ErrBadRequest.WrapNew("invalid grpc-web frame")
+	ErrGRPCWebCompressed = orberrors.ErrNotImplemented.WrapNew("compressed grpc-web messages are not supported")
+)
+
+// grpcCode maps the HTTP status of err to a gRPC code, unknown ones are Internal.
+func grpcCode(err *orberrors.Error) int {
+	if code, ok := httpStatusToGRPCCode[err.Code]; ok {
+		return code
+	}
+
+	return grpcCodeInternal
+}
+
+// grpcWebMode returns the codec of a gRPC-Web request and whether it's base64 encoded,
+// ok is false for other requests.
+func grpcWebMode(req *http.Request) (contentType string, codec codecs.Marshaler, text bool, ok bool) {
+	if req.Method != http.MethodPost {
+		return "", nil, false, false
+	}
+
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(req.Header.Get(headers.ContentType), ";")[0]))
+
+	base, subtype, _ := strings.Cut(contentType, "+")
+
+	switch base {
+	case GRPCWebContentType:
+	case GRPCWebTextContentType:
+		text = true
+	default:
+		return "", nil, false, false
+	}
+
+	mime := codecs.MimeProto
+	if subtype == "json" {
+		mime = codecs.MimeJSON
+	}
+
+	codec, err := codecs.GetMime(mime)
+	if err != nil {
+		return contentType, nil, text, true
+	}
+
+	return contentType, codec, text, true
+}
+
+// serveGRPCWeb serves a unary RPC for a gRPC-Web client.
+func serveGRPCWeb[Tin any, Tout any](
+	srv *Server,
+	resp http.ResponseWriter,
+	req *http.Request,
+	fHandler func(context.Context, *Tin) (*Tout, error),
+	service string,
+	method string,
+) {
+	w, inBody, ok := startGRPCWeb[Tin](srv, resp, req)
+	if !ok {
+		return
+	}
+
+	ctx, outMd := incomingContext(req, service, method)
+
+	out, err := callRPC(srv, ctx, inBody, fHandler)
+	if err != nil {
+		srv.logger.Error("RPC request failed", "error", err)
+	}
+
+	w.setHeaders(outMd)
+
+	if err == nil {
+		err = w.writeMessage(out)
+	}
+
+	w.finish(outMd, err)
+}
+
+// serveGRPCWebServerStream serves a server-streaming RPC for a gRPC-Web client.
+func serveGRPCWebServerStream[Tin any, Tout any](
+	srv *Server,
+	resp http.ResponseWriter,
+	req *http.Request,
+	fHandler func(*Tin, ServerStream[Tout]) error,
+	service string,
+	method string,
+) {
+	w, inBody, ok := startGRPCWeb[Tin](srv, resp, req)
+	if !ok {
+		return
+	}
+
+	ctx, outMd := incomingContext(req, service, method)
+
+	stream := &grpcWebStream[Tout]{ctx: ctx, w: w, outMd: outMd}
+
+	err := timeoutError(ctx, fHandler(inBody, stream))
+	if err != nil && ctx.Err() == nil {
+		srv.logger.Error("RPC request failed", "error", err)
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	w.setHeaders(outMd)
+	w.finish(outMd, err)
+}
+
+// startGRPCWeb reads the request message, it answers with a status if that fails.
+func startGRPCWeb[Tin any](srv *Server, resp http.ResponseWriter, req *http.Request) (*grpcWebWriter, *Tin, bool) {
+	contentType, codec, text, _ := grpcWebMode(req)
+
+	w := &grpcWebWriter{
+		resp:        resp,
+		rc:          http.NewResponseController(resp),
+		codec:       codec,
+		text:        text,
+		contentType: contentType,
+	}
+
+	inBody, ok := readEnvelopeRequest[Tin](srv, w, req)
+
+	return w, inBody, ok
+}
+
+// readEnvelopeRequest reads the request message of a gRPC-Web or Connect stream,
+// it answers with the final frame if that fails.
+func readEnvelopeRequest[Tin any](srv *Server, w *grpcWebWriter, req *http.Request) (*Tin, bool) {
+	if w.codec == nil {
+		w.finish(nil, orberrors.ErrNotImplemented.Wrap(ErrContentTypeNotSupported))
+		return nil, false
+	}
+
+	var (
+		body io.Reader = req.Body
+		err  error
+	)
+
+	if w.text {
+		body, err = decodeGRPCWebText(req.Body)
+	}
+
+	inBody := new(Tin)
+
+	if err == nil {
+		err = readGRPCWebMessage(body, w.codec, inBody)
+	}
+
+	if err != nil {
+		srv.logger.Error("failed to decode request body", "error", err)
+		w.finish(nil, err)
+
+		return nil, false
+	}
+
+	return inBody, true
+}
+
+// readGRPCWebMessage reads the first frame of the body into msg.
+func readGRPCWebMessage(body io.Reader, codec codecs.Marshaler, msg any) error {
+	header := make([]byte, grpcWebFrameHeaderLen)
+	if _, err := io.ReadFull(body, header); err != nil {
+		return grpcWebReadError(err)
+	}
+
+	switch header[0] {
+	case grpcWebFlagData:
+	case grpcWebFlagCompressed:
+		return ErrGRPCWebCompressed
+	default:
+		return ErrGRPCWebFrame.WrapF("unknown flag %d", header[0])
+	}
+
+	length := int64(binary.BigEndian.Uint32(header[1:]))
+
+	// Don't trust the length for the allocation.
+	data, err := io.ReadAll(io.LimitReader(body, length))
+	if err != nil {
+		return grpcWebReadError(err)
+	}
+
+	if int64(len(data)) != length {
+		return ErrGRPCWebFrame.Wrap(io.ErrUnexpectedEOF)
+	}
+
+	if err := codec.Unmarshal(data, msg); err != nil {
+		return orberrors.ErrBadRequest.Wrap(err)
+	}
+
+	return nil
+}
+
+// grpcWebReadError converts an error while reading the request body into an orberror.
+func grpcWebReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrBodyTooLarge
+	}
+
+	return ErrGRPCWebFrame.Wrap(err)
+}
+
+// grpcWebWriter writes gRPC-Web frames, base64 encoded in text mode.
+// With connect set it writes Connect streaming envelopes instead.
+type grpcWebWriter struct {
+	resp        http.ResponseWriter
+	rc          *http.ResponseController
+	codec       codecs.Marshaler
+	text        bool
+	connect     bool
+	contentType string
+
+	started bool
+	// sent are the metadata keys sent as headers, the others go into the trailer.
+	sent map[string]bool
+}
+
+// setHeaders sets the metadata as headers, unless the response has started already.
+func (w *grpcWebWriter) setHeaders(md map[string]string) {
+	if w.started {
+		return
+	}
+
+	w.sent = make(map[string]bool, len(md))
+
+	for k, v := range md {
+		w.resp.Header().Set(k, v)
+		w.sent[strings.ToLower(k)] = true
+	}
+}
+
+func (w *grpcWebWriter) start() {
+	if w.started {
+		return
+	}
+
+	w.started = true
+
+	contentType := w.contentType
+	if contentType == "" {
+		contentType = GRPCWebContentType + "+proto"
+	}
+
+	w.resp.Header().Set(headers.ContentType, contentType)
+	w.resp.WriteHeader(http.StatusOK)
+}
+
+func (w *grpcWebWriter) writeMessage(msg any) error {
+	data, err := w.codec.Marshal(msg)
+	if err != nil {
+		return orberrors.From(err)
+	}
+
+	return w.writeFrame(grpcWebFlagData, data)
+}
+
+func (w *grpcWebWriter) writeFrame(flag byte, data []byte) error {
+	w.start()
+
+	frame := make([]byte, grpcWebFrameHeaderLen, grpcWebFrameHeaderLen+len(data))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data))) //nolint:gosec
+	frame = append(frame, data...)
+
+	if w.text {
+		frame = []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+
+	if _, err := w.resp.Write(frame); err != nil {
+		return err
+	}
+
+	// Not all writers can flush, e.g. in tests.
+	if err := w.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+// finish writes the trailer frame with the status of err and the metadata not sent as headers.
+func (w *grpcWebWriter) finish(md map[string]string, err error) {
+	if w.connect {
+		w.finishConnect(md, err)
+		return
+	}
+
+	code := grpcCodeOK
+	message := ""
+
+	if err != nil {
+		orbe := orberrors.From(err)
+
+		code = grpcCode(orbe)
+		message = orbe.Error()
+	}
+
+	buf := bytes.Buffer{}
+	fmt.Fprintf(&buf, "grpc-status: %d\r\n", code)
+
+	if message != "" {
+		fmt.Fprintf(&buf, "grpc-message: %s\r\n", percentEncode(message))
+	}
+
+	for k, v := range md {
+		if !w.sent[strings.ToLower(k)] {
+			fmt.Fprintf(&buf, "%s: %s\r\n", strings.ToLower(k), v)
+		}
+	}
+
+	_ = w.writeFrame(grpcWebFlagTrailer, buf.Bytes()) //nolint:errcheck
+}
+
+// percentEncode encodes a grpc-message as described in the gRPC HTTP/2 protocol.
+func percentEncode(msg string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&sb, "%%%02X", c)
+			continue
+		}
+
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
+
+// grpcWebStream writes the messages of a server-streaming RPC as data frames.
+type grpcWebStream[T any] struct {
+	ctx   context.Context
+	w     *grpcWebWriter
+	outMd map[string]string
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (s *grpcWebStream[T]) Context() context.Context {
+	return s.ctx
+}
+
+// Send writes and flushes a message, the metadata is sent as headers before the first one.
+func (s *grpcWebStream[T]) Send(msg *T) error {
+	if err := s.ctx.Err(); err != nil {
+		return orberrors.From(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+
+	s.w.setHeaders(s.outMd)
+
+	return s.w.writeMessage(msg)
+}
+
+// CloseSend sends a last message and closes the stream.
+func (s *grpcWebStream[T]) CloseSend(msg *T) error {
+	if err := s.Send(msg); err != nil {
+		return err
+	}
+
+	return s.Close()
+}
+
+// Close closes the stream, further sends fail. The trailer gets sent when the handler returns.
+func (s *grpcWebStream[T]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	return nil
+}
+
+// decodeGRPCWebText decodes a grpc-web-text body, clients may send it as
+// concatenated base64 chunks, each with its own padding.
+func decodeGRPCWebText(body io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, grpcWebReadError(err)
+	}
+
+	data = bytes.Join(bytes.Fields(data), nil)
+	out := make([]byte, 0, base64.StdEncoding.DecodedLen(len(data)))
+
+	for len(data) > 0 {
+		// A segment ends after the quantum with the first padding character.
+		end := len(data)
+		if idx := bytes.IndexByte(data, '='); idx >= 0 {
+			end = min((idx/4+1)*4, len(data))
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(string(data[:end]))
+		if err != nil {
+			return nil, ErrGRPCWebFrame.Wrap(err)
+		}
+
+		out = append(out, decoded...)
+		data = data[end:]
+	}
+
+	return bytes.NewReader(out), nil
+}
@@ -3,14 +3,14 @@ package http
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
 	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/go-orb/go-orb/util/metadata"
-	"github.com/go-orb/go-orb/util/orberrors"
+
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
 )
 
 var stdHeaders = []string{"Accept", "Accept-Encoding", "Content-Length", "Content-Type", "User-Agent"} //nolint:gochecknoglobals
@@ -21,6 +21,8 @@ var (
 )
 
 // NewGRPCHandler will wrap a gRPC function with a HTTP handler.
+//
+// It also serves gRPC-Web clients, in binary and in text mode, and unary Connect calls.
 func NewGRPCHandler[Tin any, Tout any](
 	srv *Server,
 	fHandler func(context.Context, *Tin) (*Tout, error),
@@ -28,79 +30,111 @@ func NewGRPCHandler[Tin any, Tout any](
 	method string,
 ) http.HandlerFunc {
 	return func(resp http.ResponseWriter, req *http.Request) {
+		if _, _, _, ok := grpcWebMode(req); ok {
+			serveGRPCWeb(srv, resp, req, fHandler, service, method)
+			return
+		}
+
+		if isConnectUnary(req) {
+			serveConnect(srv, resp, req, fHandler, service, method)
+			return
+		}
+
 		inBody := new(Tin)
 
 		if _, err := srv.decodeBody(resp, req, inBody); err != nil {
 			srv.logger.Error("failed to decode request body", "error", err)
-			WriteError(resp, orberrors.ErrBadRequest.Wrap(err))
+			WriteError(resp, req, requestError(err))
 
 			return
 		}
 
-		// Copy metadata from req Headers into the req.Context.
-		ctx, reqMd := metadata.WithIncoming(req.Context())
-		ctx, outMd := metadata.WithOutgoing(ctx)
+		serveRPC(srv, resp, req, inBody, fHandler, service, method)
+	}
+}
 
-		for k, v := range req.Header {
-			if slices.Contains(stdHeaders, k) {
-				continue
-			}
+// serveRPC runs fHandler with the middlewares of the server on the already decoded request message
+// and writes its result to the response.
+func serveRPC[Tin any, Tout any](
+	srv *Server,
+	resp http.ResponseWriter,
+	req *http.Request,
+	inBody *Tin,
+	fHandler func(context.Context, *Tin) (*Tout, error),
+	service string,
+	method string,
+) {
+	ctx, outMd := incomingContext(req, service, method)
 
-			if len(v) == 1 {
-				reqMd[strings.ToLower(k)] = v[0]
-			} else {
-				reqMd[strings.ToLower(k)] = v[0]
-				for i := 1; i < len(v); i++ {
-					reqMd[strings.ToLower(k)+"-"+strconv.Itoa(i)] = v[i]
-				}
-			}
-		}
+	out, err := callRPC(srv, ctx, inBody, fHandler)
+	if err != nil {
+		srv.logger.Error("RPC request failed", "error", err)
+		setRequestID(resp, req, outMd)
+		WriteError(resp, req, err)
 
-		reqMd[metadata.Service] = service
-		reqMd[metadata.Method] = method
+		return
+	}
 
-		// Apply middleware.
-		h := func(ctx context.Context, req any) (any, error) {
-			return fHandler(ctx, req.(*Tin)) //nolint:errcheck
-		}
-		for _, m := range srv.config.OptMiddlewares {
-			h = m.Call(h)
-		}
+	// Write outgoing metadata.
+	for k, v := range outMd {
+		resp.Header().Set(k, v)
+	}
 
-		// The actual call.
-		out, err := h(ctx, inBody)
-		if err != nil {
-			srv.logger.Error("RPC request failed", "error", err)
-			WriteError(resp, err)
+	if err := srv.encodeBody(resp, req, out); err != nil {
+		srv.logger.Error("failed to encode response body", "error", err)
+		WriteError(resp, req, err)
 
-			return
-		}
+		return
+	}
+}
 
-		// Write outgoing metadata.
-		for k, v := range outMd {
-			resp.Header().Set(k, v)
-		}
+// callRPC runs fHandler with the middlewares of the server.
+func callRPC[Tin any, Tout any](
+	srv *Server,
+	ctx context.Context,
+	inBody *Tin,
+	fHandler func(context.Context, *Tin) (*Tout, error),
+) (any, error) {
+	// Apply middleware.
+	h := func(ctx context.Context, req any) (any, error) {
+		return fHandler(ctx, req.(*Tin)) //nolint:errcheck
+	}
+	for _, m := range srv.config.OptMiddlewares {
+		h = m.Call(h)
+	}
 
-		if err := srv.encodeBody(resp, req, out); err != nil {
-			srv.logger.Error("failed to encode response body", "error", err)
-			WriteError(resp, err)
+	// The actual call.
+	out, err := h(ctx, inBody)
 
-			return
-		}
-	}
+	return out, timeoutError(ctx, err)
 }
 
-// WriteError returns an error response to the HTTP request.
-func WriteError(w http.ResponseWriter, err error) {
-	if err == nil {
-		return
-	}
+// incomingContext copies metadata from the request headers and the client certificate
+// into the request context, it returns the context and the outgoing metadata of the response.
+func incomingContext(req *http.Request, service, method string) (context.Context, map[string]string) {
+	ctx, reqMd := metadata.WithIncoming(req.Context())
+	ctx, outMd := metadata.WithOutgoing(ctx)
+
+	for k, v := range req.Header {
+		if slices.Contains(stdHeaders, k) {
+			continue
+		}
 
-	if orbe, ok := orberrors.As(err); ok {
-		w.WriteHeader(orbe.Code)
-		fmt.Fprint(w, orbe.Error()) //nolint:errcheck
-	} else {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, err.Error()) //nolint:errcheck
+		if len(v) == 1 {
+			reqMd[strings.ToLower(k)] = v[0]
+		} else {
+			reqMd[strings.ToLower(k)] = v[0]
+			for i := 1; i < len(v); i++ {
+				reqMd[strings.ToLower(k)+"-"+strconv.Itoa(i)] = v[i]
+			}
+		}
 	}
+
+	// The identity of a verified client certificate, never from headers.
+	utls.SetPeerMetadata(reqMd, req.TLS)
+
+	reqMd[metadata.Service] = service
+	reqMd[metadata.Method] = method
+
+	return ctx, outMd
 }
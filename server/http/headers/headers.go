@@ -5,8 +5,10 @@ package headers
 const (
 	ContentType     = "Content-Type"
 	ContentEncoding = "Content-Encoding"
+	Vary            = "Vary"
 	AcceptEncoding  = "Accept-Encoding"
 	Accept          = "Accept"
+	RequestID       = "X-Request-Id"
 )
 
 // Content Type values.
@@ -17,5 +19,7 @@ const (
 
 // Encoding types.
 const (
-	GzipContentEncoding = "gzip"
+	GzipContentEncoding   = "gzip"
+	ZstdContentEncoding   = "zstd"
+	BrotliContentEncoding = "br"
 )
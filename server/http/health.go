@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/types"
+
+	"github.com/go-orb/plugins/server/http/headers"
+	"github.com/go-orb/plugins/server/srvutil"
+)
+
+// Health states in the JSON output.
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusFailing = "failing"
+	// HealthStatusUnknown is the state of components srvutil.ComponentHealth has no check for,
+	// they don't fail the aggregated state.
+	HealthStatusUnknown = "unknown"
+)
+
+// HealthCheck is a user-registered check, a nil error means healthy.
+type HealthCheck = srvutil.HealthCheck
+
+// HealthChecker is implemented by components that report their state,
+// a nil error means the component is started and healthy.
+type HealthChecker = srvutil.HealthChecker
+
+// HealthReport is the JSON body of the health endpoints.
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the state of a single component or check.
+type HealthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Healthy reports whether no check has failed.
+func (r *HealthReport) Healthy() bool {
+	return r.Status == HealthStatusOK
+}
+
+// CheckHealth aggregates the state of the components and the checks.
+// Components get checked by srvutil.ComponentHealth, go-orb servers are
+// expanded into their entrypoints.
+func CheckHealth(ctx context.Context, components *types.Components, checks map[string]HealthCheck) *HealthReport {
+	report := &HealthReport{Status: HealthStatusOK, Checks: make(map[string]HealthCheckResult)}
+
+	add := func(name string, err error, checked bool) {
+		result := HealthCheckResult{Status: HealthStatusOK}
+
+		switch {
+		case !checked:
+			result.Status = HealthStatusUnknown
+		case err != nil:
+			result.Status = HealthStatusFailing
+			result.Error = err.Error()
+			report.Status = HealthStatusFailing
+		}
+
+		report.Checks[name] = result
+	}
+
+	var check func(name string, c any)
+
+	check = func(name string, c any) {
+		switch comp := c.(type) {
+		case *server.Server:
+			for epName, ep := range comp.GetEntrypoints().All() {
+				check(server.EntrypointType+"/"+epName, ep)
+			}
+		default:
+			if hc, ok := srvutil.ComponentHealth(c); ok {
+				add(name, hc(ctx), true)
+			} else {
+				add(name, nil, false)
+			}
+		}
+	}
+
+	if components != nil {
+		for _, c := range components.Iterate(false) {
+			check(srvutil.ComponentName(c), c)
+		}
+	}
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		add(name, checks[name](ctx), true)
+	}
+
+	return report
+}
+
+// Health returns srvutil.ErrNotStarted while the entrypoint isn't serving and srvutil.ErrDraining while it stops.
+func (s *Server) Health(_ context.Context) error {
+	return srvutil.Health(s.started.Load(), s.draining.Load())
+}
+
+// AddHealthCheck adds a check to the readiness and health endpoints.
+func (s *Server) AddHealthCheck(name string, check HealthCheck) {
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	if s.config.Health.OptChecks == nil {
+		s.config.Health.OptChecks = make(map[string]HealthCheck)
+	}
+
+	s.config.Health.OptChecks[name] = check
+}
+
+// healthReport aggregates the state of the entrypoint, the components and the checks.
+func (s *Server) healthReport(ctx context.Context) *HealthReport {
+	timeout := time.Duration(s.config.Health.Timeout)
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	s.healthMu.RLock()
+	checks := make(map[string]HealthCheck, len(s.config.Health.OptChecks)+1)
+
+	for name, check := range s.config.Health.OptChecks {
+		checks[name] = check
+	}
+	s.healthMu.RUnlock()
+
+	// The entrypoint itself is part of the components if it has been created by a server,
+	// it's always checked in case it isn't.
+	if _, ok := checks[server.EntrypointType+"/"+s.epName]; !ok {
+		checks[server.EntrypointType+"/"+s.epName] = s.Health
+	}
+
+	// Without components, e.g. for entrypoints from a config file, the registry is checked.
+	if s.config.Health.OptComponents == nil {
+		if check, ok := srvutil.ComponentHealth(s.registry); ok {
+			checks[srvutil.ComponentName(s.registry)] = check
+		}
+	}
+
+	return CheckHealth(ctx, s.config.Health.OptComponents, checks)
+}
+
+// registerHealth mounts the health endpoints on the router.
+func (s *Server) registerHealth() error {
+	cfg := &s.config.Health
+	if !cfg.Enabled {
+		return nil
+	}
+
+	ready := func(w http.ResponseWriter, r *http.Request) {
+		writeHealthReport(w, s.healthReport(r.Context()))
+	}
+
+	if cfg.Path != "" {
+		if err := s.router.Handle(http.MethodGet, cfg.Path, ready); err != nil {
+			return err
+		}
+	}
+
+	if cfg.ReadinessPath != "" && cfg.ReadinessPath != cfg.Path {
+		if err := s.router.Handle(http.MethodGet, cfg.ReadinessPath, ready); err != nil {
+			return err
+		}
+	}
+
+	if cfg.LivenessPath != "" {
+		// Liveness only depends on the entrypoint, failing dependencies must not restart the process.
+		return s.router.Handle(http.MethodGet, cfg.LivenessPath, func(w http.ResponseWriter, r *http.Request) {
+			writeHealthReport(w, CheckHealth(r.Context(), nil, map[string]HealthCheck{
+				server.EntrypointType + "/" + s.epName: s.Health,
+			}))
+		})
+	}
+
+	return nil
+}
+
+// writeHealthReport writes the report as JSON, failing reports get a 503.
+func writeHealthReport(w http.ResponseWriter, report *HealthReport) {
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set(headers.ContentType, headers.JSONContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(report) //nolint:errcheck
+}
@@ -8,10 +8,12 @@ import (
 	"errors"
 	"net"
 	"net/http"
-	"sync/atomic"
 	"time"
 
 	"log/slog"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // Errors.
@@ -28,17 +30,31 @@ type httpServer struct {
 func (s *Server) newHTTPServer(router *Router) (*httpServer, error) {
 	s.handler = router
 
+	if len(s.config.CORS.AllowedOrigins) > 0 {
+		s.cors = newCORS(s.config.CORS, router)
+		s.handler = s.cors
+	}
+
 	server := http.Server{
 		Handler:           s,
 		ReadTimeout:       time.Duration(s.config.ReadTimeout),
 		WriteTimeout:      time.Duration(s.config.WriteTimeout),
 		IdleTimeout:       time.Duration(s.config.IdleTimeout),
 		ReadHeaderTimeout: time.Second * 4,
+		BaseContext:       func(net.Listener) context.Context { return s.baseCtx },
 
 		// TODO(davincible): do we need to set this? would be nice but doesn't take interface
 		// ErrorLog:          httpServerLogger,
 	}
 
+	// Serve HTTP/2 without TLS next to HTTP/1.
+	if s.config.Insecure && s.config.H2C {
+		server.Handler = h2c.NewHandler(s, &http2.Server{
+			MaxConcurrentStreams: uint32(s.config.MaxConcurrentStreams), //nolint:gosec
+			IdleTimeout:          time.Duration(s.config.IdleTimeout),
+		})
+	}
+
 	if !s.config.Insecure && s.config.TLS != nil {
 		server.TLSConfig = s.config.TLS.Config
 	} else if !s.config.Insecure && s.config.TLS == nil {
@@ -56,8 +72,10 @@ func (s *httpServer) Start(l net.Listener) error {
 	return nil
 }
 
+// Stop waits for the active connections until the context is done, then closes them.
 func (s *httpServer) Stop(ctx context.Context) error {
-	if err := s.Server.Shutdown(ctx); err != nil && errors.Is(err, context.DeadlineExceeded) {
+	if err := s.Server.Shutdown(ctx); err != nil {
+		_ = s.Server.Close() //nolint:errcheck
 		return err
 	}
 
@@ -67,12 +85,17 @@ func (s *httpServer) Stop(ctx context.Context) error {
 func (s *Server) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	resp.Header().Set("Server", "go-orb")
 
+	// keep track of active requests for draining
+	s.activeRequests.Add(1)
+	defer s.activeRequests.Add(-1)
+
+	// ask HTTP/1 clients to reconnect elsewhere while draining
+	if s.draining.Load() && req.ProtoMajor == 1 {
+		resp.Header().Set("Connection", "close")
+	}
+
 	// advertise HTTP/3, if enabled
 	if s.http3Server != nil {
-		// keep track of active requests for QUIC transport purposes
-		atomic.AddInt64(&s.activeRequests, 1)
-		defer atomic.AddInt64(&s.activeRequests, -1)
-
 		if req.ProtoMajor < 3 {
 			err := s.http3Server.SetQUICHeaders(resp.Header())
 			if err != nil {
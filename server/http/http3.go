@@ -10,6 +10,8 @@ import (
 
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
+
+	"github.com/go-orb/plugins/server/srvutil"
 )
 
 // Errors returned by the HTTP3 server.
@@ -59,7 +61,19 @@ func (h3 *http3server) Start() error {
 	return nil
 }
 
-func (h3 *http3server) Stop(_ context.Context) error {
-	// TODO(davincible): use h3.CloseGracefully() when available.
-	return h3.Close()
+// Stop sends a GOAWAY and waits for the running requests until the context is done,
+// then closes the connections.
+func (h3 *http3server) Stop(ctx context.Context) error {
+	// Clients keep idle connections open after the GOAWAY, Shutdown would wait for them.
+	idleCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		srvutil.WaitIdle(idleCtx, &h3.s.activeRequests)
+		cancel()
+	}()
+
+	_ = h3.Shutdown(idleCtx) //nolint:errcheck
+
+	return ctx.Err()
 }
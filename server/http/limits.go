@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Errors returned by the route limits.
+var (
+	ErrBodyTooLarge = orberrors.HTTP(http.StatusRequestEntityTooLarge).WrapNew("request body too large")
+	ErrRouteTimeout = orberrors.HTTP(http.StatusGatewayTimeout).WrapNew("handler timeout")
+	ErrRouteBusy    = orberrors.ErrUnavailable.WrapNew("too many concurrent requests")
+)
+
+// routeLimitsKey is the context key of the limits of the current route.
+type routeLimitsKey struct{}
+
+// routeLimits are the effective limits of a route.
+type routeLimits struct {
+	maxBodySize int64
+	timeout     time.Duration
+	// sem caps the concurrent requests, nil means no cap.
+	sem chan struct{}
+}
+
+// limitsFor merges the defaults with the first matching route rule.
+func (c *LimitsConfig) limitsFor(method, routePath string) *routeLimits {
+	limits := &routeLimits{
+		maxBodySize: c.MaxBodySize,
+		timeout:     time.Duration(c.Timeout),
+	}
+	maxConcurrent := c.MaxConcurrent
+
+	for _, rule := range c.Routes {
+		if !rule.matches(method, routePath) {
+			continue
+		}
+
+		if rule.MaxBodySize != 0 {
+			limits.maxBodySize = rule.MaxBodySize
+		}
+
+		if rule.Timeout != 0 {
+			limits.timeout = time.Duration(rule.Timeout)
+		}
+
+		if rule.MaxConcurrent != 0 {
+			maxConcurrent = rule.MaxConcurrent
+		}
+
+		break
+	}
+
+	if maxConcurrent > 0 {
+		limits.sem = make(chan struct{}, maxConcurrent)
+	}
+
+	if limits.maxBodySize <= 0 && limits.timeout <= 0 && limits.sem == nil {
+		return nil
+	}
+
+	return limits
+}
+
+// matches reports whether the rule matches the route, the pattern is matched with path.Match
+// and may be prefixed by a method.
+func (r *RouteLimitsConfig) matches(method, routePath string) bool {
+	pattern := r.Route
+
+	if m, p, ok := strings.Cut(pattern, " "); ok {
+		if !strings.EqualFold(m, method) {
+			return false
+		}
+
+		pattern = p
+	}
+
+	ok, err := path.Match(pattern, routePath)
+
+	return err == nil && ok
+}
+
+// limit wraps the handler of a route with its limits.
+func (r *Router) limit(method, routePath string, handler http.HandlerFunc) http.HandlerFunc {
+	if r.limits == nil {
+		return handler
+	}
+
+	limits := r.limits.limitsFor(method, routePath)
+	if limits == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		if limits.sem != nil {
+			select {
+			case limits.sem <- struct{}{}:
+				defer func() { <-limits.sem }()
+			default:
+				WriteError(w, req, ErrRouteBusy)
+				return
+			}
+		}
+
+		if limits.maxBodySize > 0 {
+			if req.ContentLength > limits.maxBodySize {
+				WriteError(w, req, ErrBodyTooLarge)
+				return
+			}
+
+			req.Body = http.MaxBytesReader(w, req.Body, limits.maxBodySize)
+		}
+
+		ctx := context.WithValue(req.Context(), routeLimitsKey{}, limits)
+
+		if limits.timeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, limits.timeout)
+			defer cancel()
+		}
+
+		handler(w, req.WithContext(ctx))
+	}
+}
+
+// bodyLimit returns the maximum body size of the current route, 0 means no limit.
+func bodyLimit(ctx context.Context) int64 {
+	limits, ok := ctx.Value(routeLimitsKey{}).(*routeLimits)
+	if !ok {
+		return 0
+	}
+
+	return limits.maxBodySize
+}
+
+// maxMessageSize returns the body limit of the route as maximum message size of streams.
+func maxMessageSize(ctx context.Context, size int64) int64 {
+	if limit := bodyLimit(ctx); limit > 0 {
+		return min(size, limit)
+	}
+
+	return size
+}
+
+// requestError converts an error while reading the request into an orberror.
+func requestError(err error) *orberrors.Error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrBodyTooLarge
+	}
+
+	if errors.Is(err, ErrContentEncodingNotSupported) {
+		return ErrContentEncodingNotSupported
+	}
+
+	return orberrors.ErrBadRequest.Wrap(err)
+}
+
+// timeoutError replaces err with ErrRouteTimeout if the route timeout has passed.
+func timeoutError(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	if limits, ok := ctx.Value(routeLimitsKey{}).(*routeLimits); ok && limits.timeout > 0 {
+		return ErrRouteTimeout
+	}
+
+	return err
+}
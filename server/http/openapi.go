@@ -0,0 +1,175 @@
+package http
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sync"
+
+	"github.com/go-orb/plugins/server/http/headers"
+)
+
+// OpenAPIVersion is the version of the served OpenAPI documents.
+const OpenAPIVersion = "3.0.3"
+
+//go:embed openapi.html
+var openAPIViewer string
+
+var openAPIViewerTemplate = template.Must(template.New("openapi").Parse(openAPIViewer)) //nolint:gochecknoglobals
+
+// openAPITag is a tag of an OpenAPI document.
+type openAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// openAPIDocument holds the parts of an OpenAPI document that get merged.
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas   map[string]json.RawMessage `json:"schemas,omitempty"`
+		Responses map[string]json.RawMessage `json:"responses,omitempty"`
+	} `json:"components"`
+	Tags []openAPITag `json:"tags,omitempty"`
+}
+
+// openAPI merges the OpenAPI documents of the registered services.
+type openAPI struct {
+	mu  sync.RWMutex
+	doc openAPIDocument
+}
+
+func newOpenAPI() *openAPI {
+	o := &openAPI{}
+	o.doc.Paths = map[string]map[string]json.RawMessage{}
+	o.doc.Components.Schemas = map[string]json.RawMessage{}
+	o.doc.Components.Responses = map[string]json.RawMessage{}
+
+	return o
+}
+
+// add merges a JSON document into the document, later operations and schemas
+// with the same name replace earlier ones.
+func (o *openAPI) add(data []byte) error {
+	var doc openAPIDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for path, ops := range doc.Paths {
+		if o.doc.Paths[path] == nil {
+			o.doc.Paths[path] = map[string]json.RawMessage{}
+		}
+
+		for method, op := range ops {
+			o.doc.Paths[path][method] = op
+		}
+	}
+
+	for name, schema := range doc.Components.Schemas {
+		o.doc.Components.Schemas[name] = schema
+	}
+
+	for name, resp := range doc.Components.Responses {
+		o.doc.Components.Responses[name] = resp
+	}
+
+outer:
+	for _, tag := range doc.Tags {
+		for _, t := range o.doc.Tags {
+			if t.Name == tag.Name {
+				continue outer
+			}
+		}
+
+		o.doc.Tags = append(o.doc.Tags, tag)
+	}
+
+	return nil
+}
+
+// marshal returns the merged document with the given info.
+func (o *openAPI) marshal(title, version string) ([]byte, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return json.Marshal(struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title   string `json:"title"`
+			Version string `json:"version"`
+		} `json:"info"`
+		openAPIDocument
+	}{
+		OpenAPI: OpenAPIVersion,
+		Info: struct {
+			Title   string `json:"title"`
+			Version string `json:"version"`
+		}{Title: title, Version: version},
+		openAPIDocument: o.doc,
+	})
+}
+
+// AddOpenAPI merges an OpenAPI v3 document into the document served by this entrypoint,
+// protoc-gen-go-orb generates one for each service.
+func (s *Server) AddOpenAPI(doc string) error {
+	return s.openapi.add([]byte(doc))
+}
+
+// OpenAPI returns the merged OpenAPI document of this entrypoint.
+func (s *Server) OpenAPI() ([]byte, error) {
+	title := s.config.OpenAPI.Title
+	if title == "" {
+		title = s.serviceName
+	}
+
+	version := s.config.OpenAPI.Version
+	if version == "" {
+		version = s.serviceVersion
+	}
+
+	return s.openapi.marshal(title, version)
+}
+
+// registerOpenAPI registers the routes of the document and the viewer.
+func (s *Server) registerOpenAPI() error {
+	if !s.config.OpenAPI.Enabled {
+		return nil
+	}
+
+	if err := s.router.Handle(http.MethodGet, s.config.OpenAPI.Path, s.serveOpenAPI); err != nil {
+		return err
+	}
+
+	if s.config.OpenAPI.UIPath != "" {
+		return s.router.Handle(http.MethodGet, s.config.OpenAPI.UIPath, s.serveOpenAPIViewer)
+	}
+
+	return nil
+}
+
+func (s *Server) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := s.OpenAPI()
+	if err != nil {
+		s.logger.Error("failed to encode the OpenAPI document", "error", err)
+		WriteError(w, r, err)
+
+		return
+	}
+
+	w.Header().Set(headers.ContentType, headers.JSONContentType)
+	w.Write(data) //nolint:errcheck
+}
+
+func (s *Server) serveOpenAPIViewer(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set(headers.ContentType, "text/html; charset=utf-8")
+
+	if err := openAPIViewerTemplate.Execute(w, map[string]string{"Path": s.config.OpenAPI.Path}); err != nil {
+		s.logger.Error("failed to render the OpenAPI viewer", "error", err)
+	}
+}
@@ -0,0 +1,313 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/julienschmidt/httprouter"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/go-orb/plugins/server/http/headers"
+	"github.com/go-orb/plugins/server/http/utils/header"
+)
+
+// REST binding errors.
+var (
+	ErrNotProtoMessage  = errors.New("request is not a proto message")
+	ErrUnknownField     = errors.New("unknown field")
+	ErrUnsupportedField = errors.New("unsupported field type")
+)
+
+// BodyAll selects the whole request message as the body of a REST binding.
+const BodyAll = "*"
+
+// NewRESTHandler will wrap a gRPC function with a HTTP handler for a
+// google.api.http binding.
+//
+// The request message gets filled from the query parameters, the request body
+// and the path parameters of the route, in that order. body is the body
+// selector of the binding, "" for no body, "*" for the whole message or the
+// name of a field.
+func NewRESTHandler[Tin any, Tout any](
+	srv *Server,
+	fHandler func(context.Context, *Tin) (*Tout, error),
+	service string,
+	method string,
+	body string,
+) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		inBody := new(Tin)
+
+		msg, ok := any(inBody).(proto.Message)
+		if !ok {
+			WriteError(resp, req, orberrors.ErrInternalServerError.Wrap(ErrNotProtoMessage))
+
+			return
+		}
+
+		if err := srv.bindREST(resp, req, msg.ProtoReflect(), body); err != nil {
+			srv.logger.Error("failed to bind the request", "error", err)
+			WriteError(resp, req, requestError(err))
+
+			return
+		}
+
+		serveRPC(srv, resp, req, inBody, fHandler, service, method)
+	}
+}
+
+// bindREST fills msg from the query, the body and the path parameters of the request.
+func (s *Server) bindREST(resp http.ResponseWriter, req *http.Request, msg protoreflect.Message, body string) error {
+	if body != BodyAll {
+		for key, values := range req.URL.Query() {
+			// Fields selected by the body can't be set by query parameters.
+			if body != "" && (key == body || strings.HasPrefix(key, body+".")) {
+				continue
+			}
+
+			if err := setField(msg, key, values); err != nil {
+				return fmt.Errorf("query parameter '%s': %w", key, err)
+			}
+		}
+	}
+
+	if err := s.bindBody(resp, req, msg, body); err != nil {
+		return err
+	}
+
+	for _, p := range httprouter.ParamsFromContext(req.Context()) {
+		// Catch-all parameters include the leading slash.
+		if err := setField(msg, p.Key, []string{strings.TrimPrefix(p.Value, "/")}); err != nil {
+			return fmt.Errorf("path parameter '%s': %w", p.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// bindBody decodes the request body into the field of msg selected by body.
+func (s *Server) bindBody(resp http.ResponseWriter, req *http.Request, msg protoreflect.Message, body string) error {
+	if body == "" || req.ContentLength == 0 || req.Body == nil || req.Body == http.NoBody {
+		resp.Header().Set(headers.ContentType, header.GetAcceptType(req.Header.Get(headers.Accept), headers.JSONContentType))
+		return nil
+	}
+
+	if body == BodyAll {
+		_, err := s.decodeBody(resp, req, msg.Interface())
+		return err
+	}
+
+	fd := findField(msg.Descriptor(), body)
+	if fd == nil {
+		return fmt.Errorf("body '%s': %w", body, ErrUnknownField)
+	}
+
+	if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+		_, err := s.decodeBody(resp, req, msg.Mutable(fd).Message().Interface())
+		return err
+	}
+
+	resp.Header().Set(headers.ContentType, header.GetAcceptType(req.Header.Get(headers.Accept), headers.JSONContentType))
+
+	// Scalar fields get the raw body or a JSON value.
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+
+	values := []string{string(data)}
+
+	ct, _ := header.GetContentType(req.Header.Get(headers.ContentType)) //nolint:errcheck
+	if ct == headers.JSONContentType {
+		values, err = jsonValues(data)
+		if err != nil {
+			return fmt.Errorf("body '%s': %w", body, err)
+		}
+	}
+
+	return setField(msg, body, values)
+}
+
+// jsonValues returns the string representation of a JSON value or of the items of a JSON array.
+func jsonValues(data []byte) ([]string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		raw = []json.RawMessage{data}
+	}
+
+	result := make([]string, 0, len(raw))
+
+	for _, r := range raw {
+		var str string
+		if err := json.Unmarshal(r, &str); err == nil {
+			result = append(result, str)
+			continue
+		}
+
+		if !json.Valid(r) {
+			return nil, fmt.Errorf("invalid JSON value '%s'", string(r))
+		}
+
+		result = append(result, strings.TrimSpace(string(r)))
+	}
+
+	return result, nil
+}
+
+// findField returns the field with the given proto or JSON name.
+func findField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+
+	return md.Fields().ByJSONName(name)
+}
+
+// setField sets the field at the dotted path to values,
+// repeated fields get all values, others only the last one.
+func setField(msg protoreflect.Message, path string, values []string) error {
+	names := strings.Split(path, ".")
+
+	for i, name := range names {
+		fd := findField(msg.Descriptor(), name)
+		if fd == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownField, path)
+		}
+
+		if fd.IsMap() {
+			return fmt.Errorf("%w: %s is a map", ErrUnsupportedField, path)
+		}
+
+		if i < len(names)-1 {
+			if fd.Message() == nil || fd.IsList() {
+				return fmt.Errorf("%w: %s is not a message", ErrUnsupportedField, strings.Join(names[:i+1], "."))
+			}
+
+			msg = msg.Mutable(fd).Message()
+
+			continue
+		}
+
+		if fd.IsList() {
+			list := msg.Mutable(fd).List()
+
+			for _, v := range values {
+				if fd.Message() != nil {
+					item := list.NewElement()
+					if err := parseMessage(item.Message(), v); err != nil {
+						return fmt.Errorf("%s: %w", path, err)
+					}
+
+					list.Append(item)
+
+					continue
+				}
+
+				pv, err := parseValue(fd, v)
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+
+				list.Append(pv)
+			}
+
+			return nil
+		}
+
+		if len(values) == 0 {
+			return nil
+		}
+
+		value := values[len(values)-1]
+
+		if fd.Message() != nil {
+			if err := parseMessage(msg.Mutable(fd).Message(), value); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			return nil
+		}
+
+		pv, err := parseValue(fd, value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		msg.Set(fd, pv)
+	}
+
+	return nil
+}
+
+// parseMessage parses well known types like google.protobuf.Timestamp from their JSON representation.
+func parseMessage(msg protoreflect.Message, value string) error {
+	if err := protojson.Unmarshal([]byte(strconv.Quote(value)), msg.Interface()); err == nil {
+		return nil
+	}
+
+	if err := protojson.Unmarshal([]byte(value), msg.Interface()); err != nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedField, msg.Descriptor().FullName())
+	}
+
+	return nil
+}
+
+// parseValue parses a scalar or enum value.
+//
+//nolint:gocyclo,cyclop
+func parseValue(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(value)
+		}
+
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+
+		v, err := strconv.ParseInt(value, 10, 32)
+
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return protoreflect.Value{}, fmt.Errorf("%w: %s", ErrUnsupportedField, fd.FullName())
+	}
+
+	return protoreflect.Value{}, fmt.Errorf("%w: %s", ErrUnsupportedField, fd.FullName())
+}
@@ -5,9 +5,12 @@ import (
 	"log/slog"
 	"maps"
 	"net/http"
+	"runtime/debug"
 	"slices"
+	"sync/atomic"
 
 	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/orberrors"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -16,6 +19,10 @@ type Router struct {
 	logger     log.Logger
 	routes     map[string]http.HandlerFunc
 	httprouter *httprouter.Router
+	limits     *LimitsConfig
+
+	// panics is the number of recovered panics.
+	panics atomic.Uint64
 }
 
 // NewRouter creates a new router.
@@ -26,9 +33,17 @@ func NewRouter(logger log.Logger) *Router {
 		httprouter: httprouter.New(),
 	}
 
-	r.httprouter.PanicHandler = func(w http.ResponseWriter, _ *http.Request, i interface{}) {
-		r.logger.Error("Panic", slog.String("error", fmt.Sprint(i)))
-		http.Error(w, fmt.Sprint(i), http.StatusInternalServerError)
+	r.httprouter.PanicHandler = func(w http.ResponseWriter, req *http.Request, i interface{}) {
+		r.panics.Add(1)
+
+		r.logger.ErrorContext(
+			req.Context(),
+			"Recovered from a panic",
+			slog.String("panic", fmt.Sprint(i)),
+			slog.String("path", req.URL.Path),
+			slog.String("stack", string(debug.Stack())),
+		)
+		WriteError(w, req, orberrors.ErrInternalServerError)
 	}
 
 	// Performance optimizations for httprouter
@@ -40,17 +55,49 @@ func NewRouter(logger log.Logger) *Router {
 	return r
 }
 
-// Routes returns the list of routes.
+// Panics returns the number of panics recovered by the router.
+func (r *Router) Panics() uint64 {
+	return r.panics.Load()
+}
+
+// Routes returns the list of routes, routes of other methods than POST are prefixed with their method.
 func (r *Router) Routes() []string {
 	return slices.Collect(maps.Keys(r.routes))
 }
 
 // Post registers a new route for POST requests.
 func (r *Router) Post(path string, handler http.HandlerFunc) {
+	handler = r.limit(http.MethodPost, path, handler)
+
 	r.routes[path] = handler
 	r.httprouter.HandlerFunc(http.MethodPost, path, handler)
 }
 
+// Handle registers a new route for the given method, path may contain
+// httprouter parameters like "/v1/books/:name" or a trailing catch-all "*name".
+//
+// Routes that conflict with an already registered route don't get registered,
+// Handle returns ErrRouteConflict for them.
+func (r *Router) Handle(method, path string, handler http.HandlerFunc) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%w: %s %s: %v", ErrRouteConflict, method, path, rec)
+		}
+	}()
+
+	handler = r.limit(method, path, handler)
+
+	r.httprouter.HandlerFunc(method, path, handler)
+
+	if method == http.MethodPost {
+		r.routes[path] = handler
+	} else {
+		r.routes[method+" "+path] = handler
+	}
+
+	return nil
+}
+
 // ServeHTTP implements the http.Handler interface.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	r.httprouter.ServeHTTP(w, req)
@@ -0,0 +1,330 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/go-orb/plugins/server/http/headers"
+	"github.com/go-orb/plugins/server/http/utils/header"
+)
+
+// Content types of server-streaming responses.
+const (
+	EventStreamContentType = "text/event-stream"
+	NDJSONContentType      = "application/x-ndjson"
+)
+
+// ErrStreamClosed is returned when sending on a closed stream.
+var ErrStreamClosed = errors.New("stream is closed")
+
+// ServerStream is the stream of a server-streaming RPC served over HTTP,
+// it implements the stream interfaces generated by protoc-gen-go-orb.
+type ServerStream[T any] interface {
+	Send(msg *T) error
+	Context() context.Context
+	Close() error
+	CloseSend(msg *T) error
+}
+
+// NewServerStreamHandler will wrap a server-streaming gRPC function with a HTTP handler.
+//
+// Responses are sent as server-sent events if the client accepts "text/event-stream",
+// otherwise as newline delimited JSON. Each message gets flushed on its own, errors
+// are sent as a final "error" event or line.
+//
+// WebSocket upgrades get served as described in NewWebSocketHandler,
+// the first message of the client is the request. gRPC-Web clients get
+// their messages as data frames followed by a trailer frame, Connect clients
+// as envelopes followed by an end-stream message.
+func NewServerStreamHandler[Tin any, Tout any](
+	srv *Server,
+	fHandler func(*Tin, ServerStream[Tout]) error,
+	service string,
+	method string,
+) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if isWebSocketUpgrade(req) {
+			serveWebSocketServerStream(srv, resp, req, fHandler, service, method)
+			return
+		}
+
+		if _, _, _, ok := grpcWebMode(req); ok {
+			serveGRPCWebServerStream(srv, resp, req, fHandler, service, method)
+			return
+		}
+
+		if _, _, ok := connectStreamMode(req); ok {
+			serveConnectServerStream(srv, resp, req, fHandler, service, method)
+			return
+		}
+
+		inBody := new(Tin)
+
+		if _, err := srv.decodeBody(resp, req, inBody); err != nil {
+			srv.logger.Error("failed to decode request body", "error", err)
+			WriteError(resp, req, requestError(err))
+
+			return
+		}
+
+		serveStream(srv, resp, req, inBody, fHandler, service, method)
+	}
+}
+
+// NewRESTServerStreamHandler is NewServerStreamHandler for a google.api.http binding,
+// see NewRESTHandler for how the request message gets filled.
+func NewRESTServerStreamHandler[Tin any, Tout any](
+	srv *Server,
+	fHandler func(*Tin, ServerStream[Tout]) error,
+	service string,
+	method string,
+	body string,
+) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		inBody := new(Tin)
+
+		msg, ok := any(inBody).(proto.Message)
+		if !ok {
+			WriteError(resp, req, orberrors.ErrInternalServerError.Wrap(ErrNotProtoMessage))
+
+			return
+		}
+
+		if err := srv.bindREST(resp, req, msg.ProtoReflect(), body); err != nil {
+			srv.logger.Error("failed to bind the request", "error", err)
+			WriteError(resp, req, requestError(err))
+
+			return
+		}
+
+		serveStream(srv, resp, req, inBody, fHandler, service, method)
+	}
+}
+
+// serveStream runs fHandler with a stream that writes to the response.
+func serveStream[Tin any, Tout any](
+	srv *Server,
+	resp http.ResponseWriter,
+	req *http.Request,
+	inBody *Tin,
+	fHandler func(*Tin, ServerStream[Tout]) error,
+	service string,
+	method string,
+) {
+	ctx, outMd := incomingContext(req, service, method)
+
+	stream, err := newServerStream[Tout](ctx, resp, req, outMd)
+	if err != nil {
+		srv.logger.Error("failed to create the response stream", "error", err)
+		WriteError(resp, req, err)
+
+		return
+	}
+
+	if err := timeoutError(ctx, fHandler(inBody, stream)); err != nil {
+		if ctx.Err() == nil {
+			srv.logger.Error("RPC request failed", "error", err)
+		}
+
+		stream.sendError(err)
+
+		return
+	}
+
+	if err := stream.Close(); err != nil {
+		srv.logger.Error("failed to close the response stream", "error", err)
+	}
+}
+
+// serverStream writes messages as server-sent events or newline delimited JSON.
+type serverStream[T any] struct {
+	ctx   context.Context
+	w     http.ResponseWriter
+	rc    *http.ResponseController
+	codec codecs.Marshaler
+	sse   bool
+	outMd map[string]string
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+}
+
+func newServerStream[T any](
+	ctx context.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	outMd map[string]string,
+) (*serverStream[T], error) {
+	accept := req.Header.Get(headers.Accept)
+	s := &serverStream[T]{
+		ctx:   ctx,
+		w:     w,
+		rc:    http.NewResponseController(w),
+		sse:   acceptsEventStream(accept),
+		outMd: outMd,
+	}
+
+	// NDJSON is JSON by definition, events get the negotiated codec.
+	contentType := headers.JSONContentType
+
+	if s.sse {
+		reqCT, err := header.GetContentType(req.Header.Get(headers.ContentType))
+		if err != nil || reqCT == "" {
+			reqCT = headers.JSONContentType
+		}
+
+		contentType = header.GetAcceptType(accept, reqCT)
+	}
+
+	codec, err := codecs.GetMime(contentType)
+	if err != nil {
+		return nil, ErrContentTypeNotSupported
+	}
+
+	s.codec = codec
+
+	return s, nil
+}
+
+// acceptsEventStream reports whether the Accept header asks for server-sent events.
+func acceptsEventStream(accept string) bool {
+	for _, a := range strings.Split(accept, ",") {
+		if ct, _, err := mime.ParseMediaType(a); err == nil && ct == EventStreamContentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *serverStream[T]) Context() context.Context {
+	return s.ctx
+}
+
+// Send writes and flushes a message.
+func (s *serverStream[T]) Send(msg *T) error {
+	data, err := s.codec.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return s.write("", data)
+}
+
+// CloseSend sends a last message and closes the stream.
+func (s *serverStream[T]) CloseSend(msg *T) error {
+	if err := s.Send(msg); err != nil {
+		return err
+	}
+
+	return s.Close()
+}
+
+// Close closes the stream, further sends fail.
+func (s *serverStream[T]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+
+	if !s.started {
+		s.start()
+		return s.rc.Flush()
+	}
+
+	return nil
+}
+
+// sendError writes err as a final event.
+func (s *serverStream[T]) sendError(err error) {
+	orbe := orberrors.From(err)
+
+	data, mErr := json.Marshal(map[string]any{"code": orbe.Code, "message": orbe.Error()})
+	if mErr != nil {
+		return
+	}
+
+	if s.sse {
+		s.write("error", data) //nolint:errcheck
+	} else {
+		s.write("", append(append([]byte(`{"error":`), data...), '}')) //nolint:errcheck
+	}
+
+	s.Close() //nolint:errcheck
+}
+
+// start writes the response headers, the caller has to hold the lock.
+func (s *serverStream[T]) start() {
+	s.started = true
+
+	for k, v := range s.outMd {
+		s.w.Header().Set(k, v)
+	}
+
+	if s.sse {
+		s.w.Header().Set(headers.ContentType, EventStreamContentType)
+	} else {
+		s.w.Header().Set(headers.ContentType, NDJSONContentType)
+	}
+
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+}
+
+func (s *serverStream[T]) write(event string, data []byte) error {
+	if err := s.ctx.Err(); err != nil {
+		return orberrors.From(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+
+	if !s.started {
+		s.start()
+	}
+
+	buf := bytes.Buffer{}
+
+	if s.sse {
+		if event != "" {
+			buf.WriteString("event: " + event + "\n")
+		}
+
+		for _, line := range bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n")) {
+			buf.WriteString("data: ")
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+	} else {
+		buf.Write(bytes.TrimRight(data, "\n"))
+	}
+
+	buf.WriteByte('\n')
+
+	if _, err := io.Copy(s.w, &buf); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
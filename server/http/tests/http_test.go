@@ -3,31 +3,57 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"mime"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/andybalholm/brotli"
+	"github.com/coder/websocket"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+	gproto "google.golang.org/protobuf/proto"
 
 	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/kvstore"
 	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/metrics"
 	"github.com/go-orb/go-orb/registry"
 	"github.com/go-orb/go-orb/server"
 	"github.com/go-orb/go-orb/types"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
 
 	mhttp "github.com/go-orb/plugins/server/http"
 
 	"github.com/go-orb/plugins/server/http/tests/handler"
 	"github.com/go-orb/plugins/server/http/tests/proto"
 	thttp "github.com/go-orb/plugins/server/http/tests/util/http"
+	utls "github.com/go-orb/plugins/server/http/utils/tls"
 
 	_ "github.com/go-orb/plugins/codecs/form"
 	_ "github.com/go-orb/plugins/codecs/json"
@@ -112,6 +138,94 @@ func TestServerHTTP3Twice(t *testing.T) {
 	cleanup()
 }
 
+func TestServerCompression(t *testing.T) {
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithCompression(), mhttp.WithCompressionMinSize(64))
+	defer cleanup()
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	name := strings.Repeat("a", 128)
+
+	do := func(body []byte, contentEncoding, acceptEncoding string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodPost, "http://"+srv.Address()+"/echo.Streams/Call", bytes.NewReader(body)) //nolint:noctx
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", contentEncoding)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, data
+	}
+
+	decompress := func(encoding string, data []byte) string {
+		var r io.Reader
+
+		switch encoding {
+		case "br":
+			r = brotli.NewReader(bytes.NewReader(data))
+		case "zstd":
+			zr, err := zstd.NewReader(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			defer zr.Close()
+
+			r = zr
+		case "gzip":
+			gr, err := gzip.NewReader(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			r = gr
+		default:
+			return string(data)
+		}
+
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return string(out)
+	}
+
+	msg := []byte(`{"name": "` + name + `"}`)
+
+	for accept, expected := range map[string]string{
+		"gzip;q=0.5, br;q=0.8, zstd;q=0.1": "br",
+		"gzip, zstd":                       "zstd",
+		"*;q=0.5, zstd;q=0":                "br",
+		"gzip":                             "gzip",
+		"identity":                         "",
+	} {
+		resp, data := do(msg, "", accept)
+		require.Equal(t, http.StatusOK, resp.StatusCode, accept)
+		require.Equal(t, expected, resp.Header.Get("Content-Encoding"), accept)
+		require.Equal(t, "Accept-Encoding", resp.Header.Get("Vary"), accept)
+		require.JSONEq(t, `{"msg": "Hello `+name+`"}`, decompress(expected, data), accept)
+	}
+
+	// Small responses stay uncompressed.
+	resp, data := do([]byte(`{"name": "Alex"}`), "", "zstd")
+	require.Equal(t, "", resp.Header.Get("Content-Encoding"))
+	require.JSONEq(t, `{"msg": "Hello Alex"}`, string(data))
+
+	// Compressed requests.
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+
+	resp, data = do(zw.EncodeAll(msg, nil), "zstd", "zstd")
+	require.NoError(t, zw.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `{"msg": "Hello `+name+`"}`, decompress("zstd", data))
+
+	resp, _ = do(msg, "lz4", "")
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+}
+
 func TestServerEntrypointsStarts(t *testing.T) {
 	addr := "localhost:45451"
 	server, cleanup, err := setupServer(t, false, mhttp.WithAddress(addr))
@@ -120,176 +234,1376 @@ func TestServerEntrypointsStarts(t *testing.T) {
 	}
 	defer cleanup()
 
-	require.NoError(t, server.Start(context.Background()), "start server 1")
-	require.NoError(t, server.Start(context.Background()), "start server 2")
-	require.NoError(t, server.Start(context.Background()), "start server 3")
+	require.NoError(t, server.Start(context.Background()), "start server 1")
+	require.NoError(t, server.Start(context.Background()), "start server 2")
+	require.NoError(t, server.Start(context.Background()), "start server 3")
+
+	addr = "https://" + addr
+
+	makeRequests(t, addr, thttp.TypeHTTP1)
+}
+
+func TestServerGzip(t *testing.T) {
+	srv, cleanup, err := setupServer(t, false, mhttp.WithGzip())
+	defer cleanup()
+	if err != nil {
+		require.NoError(t, err)
+	}
+
+	addr := "https://" + srv.Address()
+
+	makeRequests(t, addr, thttp.TypeHTTP1)
+}
+
+func TestServerInvalidContentType(t *testing.T) {
+	srv, cleanup, err := setupServer(t, false)
+	if err != nil {
+		require.NoError(t, err)
+	}
+	defer cleanup()
+
+	addr := "https://" + srv.Address()
+
+	require.ErrorContains(
+		t,
+		thttp.TestPostRequestProto(t, addr, "application/abcdef", thttp.TypeHTTP1),
+		"Post request failed",
+		"POST Proto",
+	)
+	require.ErrorContains(
+		t,
+		thttp.TestPostRequestProto(t, addr, "yadayadayada", thttp.TypeHTTP1),
+		"Post request failed",
+		"POST Proto",
+	)
+}
+
+func TestServerNoTLS(t *testing.T) {
+	_, cleanup, err := setupServer(t, false, mhttp.WithTLS(&tls.Config{}))
+	defer cleanup()
+	require.ErrorContains(
+		t,
+		err,
+		"tls: neither Certificates, GetCertificate, nor GetConfigForClient set in Config",
+		"setting an empty TLS config should return an error",
+	)
+}
+
+func TestServerInvalidMessage(t *testing.T) {
+	srv, cleanup, err := setupServer(t, false)
+	defer cleanup()
+	if err != nil {
+		require.NoError(t, err)
+	}
+
+	thttp.RefreshClients()
+
+	addr := fmt.Sprintf("https://%s/echo.Streams/Call", srv.Address())
+
+	// Broken json.
+	msg := `{"name": "Alex}`
+
+	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader([]byte(msg)))
+	if err != nil {
+		t.Fatalf("create POST request failed: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := thttp.HTTP2Client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	t.Logf("expected error: %v", string(body))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, string(body))
+	require.NoError(t, err)
+
+	// Close connection
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestServerErrorRPC(t *testing.T) {
+	srv, cleanup, err := setupServer(t, false)
+	defer cleanup()
+	if err != nil {
+		require.NoError(t, err)
+	}
+
+	thttp.RefreshClients()
+
+	addr := fmt.Sprintf("https://%s/echo.Streams/Call", srv.Address())
+
+	msg := `{"name": "error"}`
+
+	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader([]byte(msg))) //nolint:noctx
+	if err != nil {
+		t.Fatalf("create POST request failed: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "abc")
+
+	resp, err := thttp.HTTP2Client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// Close connection
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode, string(body))
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.Equal(t, "abc", resp.Header.Get("X-Request-Id"))
+
+	errBody := mhttp.ErrorBody{}
+	require.NoError(t, json.Unmarshal(body, &errBody))
+	require.Equal(t, mhttp.ErrorBody{
+		Code:      http.StatusInternalServerError,
+		Message:   "internal server error",
+		Wrapped:   "you asked for an error, here you go",
+		RequestID: "abc",
+	}, errBody)
+}
+
+func TestServerWriteError(t *testing.T) {
+	sent := orberrors.ErrNotFound.Wrap(errors.New("no such user"))
+
+	// Codecs of the Accept header which can't encode the body fall back to JSON.
+	for _, accept := range []string{"", "application/json", "text/plain, */*", "application/yaml", "application/x-protobuf"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("X-Request-Id", "abc")
+
+		rec := httptest.NewRecorder()
+		mhttp.WriteError(rec, req, sent)
+
+		resp := rec.Result()
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, accept)
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"), accept)
+
+		body := mhttp.ErrorBody{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), accept)
+		require.Equal(t, sent.Error(), body.Err().Error(), accept)
+		require.Equal(t, "abc", body.RequestID, accept)
+	}
+}
+
+func TestServerRequestSpecificContentType(t *testing.T) {
+	srv, cleanup, err := setupServer(t, false)
+	defer cleanup()
+	if err != nil {
+		require.NoError(t, err)
+	}
+
+	thttp.RefreshClients()
+
+	addr := fmt.Sprintf("https://%s/echo.Streams/Call", srv.Address())
+
+	msg := `{"name": "Alex"}`
+
+	testCt := func(expectedCt string) {
+		req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader([]byte(msg))) //nolint:noctx
+		if err != nil {
+			t.Fatalf("create POST request failed: %v", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", expectedCt)
+
+		resp, err := thttp.HTTP2Client.Do(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		// Validate conent type received
+		assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		ct, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, expectedCt, ct, string(body))
+
+		// Close connection
+		_, err = io.Copy(io.Discard, resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	testCt("application/proto")
+	testCt("application/x-protobuf")
+	testCt("application/x-proto")
+	testCt("application/x-protobuf")
+	testCt("application/json")
+	testCt("application/x-www-form-urlencoded")
+}
+
+func TestServerREST(t *testing.T) {
+	h := new(handler.EchoHandler)
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithHandlers(func(s any) {
+		srv := s.(*mhttp.Server) //nolint:errcheck
+		srv.Router().Handle(http.MethodGet, "/v1/hello/:name", mhttp.NewRESTHandler(srv, h.Call, proto.HandlerStreams, "Call", ""))
+		srv.Router().Handle(http.MethodPut, "/v1/greet/*name", mhttp.NewRESTHandler(srv, h.Call, proto.HandlerStreams, "Call", "*"))
+		srv.Router().Handle(http.MethodPatch, "/v1/hello", mhttp.NewRESTHandler(srv, h.Call, proto.HandlerStreams, "Call", "name"))
+		srv.Router().Handle(http.MethodDelete, "/v1/hello", mhttp.NewRESTHandler(srv, h.Call, proto.HandlerStreams, "Call", ""))
+	}))
+	defer cleanup()
+	require.NoError(t, err)
+
+	addr := "http://" + srv.Address()
+
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, addr+path, strings.NewReader(body)) //nolint:noctx
+		require.NoError(t, err)
+
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(data)
+	}
+
+	code, body := do(http.MethodGet, "/v1/hello/Alex", "")
+	require.Equal(t, http.StatusOK, code, body)
+	require.Contains(t, body, "Hello Alex")
+
+	// Path parameters win over the body.
+	code, body = do(http.MethodPut, "/v1/greet/Alex/Bob", `{"name": "Ignored"}`)
+	require.Equal(t, http.StatusOK, code, body)
+	require.Contains(t, body, "Hello Alex/Bob")
+
+	code, body = do(http.MethodPatch, "/v1/hello", `"Carl"`)
+	require.Equal(t, http.StatusOK, code, body)
+	require.Contains(t, body, "Hello Carl")
+
+	code, body = do(http.MethodDelete, "/v1/hello?name=Dora", "")
+	require.Equal(t, http.StatusOK, code, body)
+	require.Contains(t, body, "Hello Dora")
+
+	code, body = do(http.MethodGet, "/v1/hello/Alex?unknown=1", "")
+	require.Equal(t, http.StatusBadRequest, code, body)
+}
+
+func TestServerRouteConflict(t *testing.T) {
+	h := new(handler.EchoHandler)
+
+	var conflictErr error
+
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithHandlers(func(s any) {
+		srv := s.(*mhttp.Server) //nolint:errcheck
+		require.NoError(t, srv.Router().Handle(http.MethodGet, "/v1/hello/:name", mhttp.NewRESTHandler(srv, h.Call, proto.HandlerStreams, "Call", "")))
+		conflictErr = srv.Router().Handle(http.MethodGet, "/v1/hello/:other", mhttp.NewRESTHandler(srv, h.Call, proto.HandlerStreams, "Call", ""))
+	}))
+	defer cleanup()
+	require.NoError(t, err)
+
+	require.ErrorIs(t, conflictErr, mhttp.ErrRouteConflict)
+	require.Contains(t, srv.Router().Routes(), "GET /v1/hello/:name")
+	require.NotContains(t, srv.Router().Routes(), "GET /v1/hello/:other")
+
+	// A health path which conflicts with a handler route fails the start.
+	_, cleanup, err = setupServer(t, false, mhttp.WithInsecure(), mhttp.WithHealth(types.NewComponents()), mhttp.WithHandlers(func(s any) {
+		srv := s.(*mhttp.Server) //nolint:errcheck
+		require.NoError(t, srv.Router().Handle(http.MethodGet, "/:name", mhttp.NewRESTHandler(srv, h.Call, proto.HandlerStreams, "Call", "")))
+	}))
+	defer cleanup()
+	require.ErrorIs(t, err, mhttp.ErrRouteConflict)
+}
+
+func TestServerOpenAPI(t *testing.T) {
+	docs := []string{
+		`{"paths": {"/echo.Streams/Call": {"post": {"operationId": "Streams_Call"}}}, "components": {"schemas": {"echo.CallRequest": {"type": "object"}}}}`,
+		`{"paths": {"/v1/hello/{name}": {"get": {"operationId": "Streams_Call_0"}}}, "tags": [{"name": "echo.Streams"}]}`,
+	}
+
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithOpenAPI("", mhttp.DefaultOpenAPIUIPath), mhttp.WithHandlers(func(s any) {
+		for _, doc := range docs {
+			require.NoError(t, s.(*mhttp.Server).AddOpenAPI(doc)) //nolint:errcheck
+		}
+	}))
+	defer cleanup()
+	require.NoError(t, err)
+
+	require.Error(t, srv.AddOpenAPI("{"), "adding an invalid document should fail")
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get("http://" + srv.Address() + path) //nolint:noctx
+		require.NoError(t, err)
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, string(body)
+	}
+
+	resp, body := get(mhttp.DefaultOpenAPIPath)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Info    map[string]string         `json:"info"`
+		Paths   map[string]map[string]any `json:"paths"`
+		Tags    []map[string]string       `json:"tags"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(body), &doc))
+	require.Equal(t, mhttp.OpenAPIVersion, doc.OpenAPI)
+	require.Equal(t, "test-server", doc.Info["title"])
+	require.Equal(t, "v1.0.0", doc.Info["version"])
+	require.Len(t, doc.Paths, 2)
+	require.Len(t, doc.Tags, 1)
+
+	resp, body = get(mhttp.DefaultOpenAPIUIPath)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	require.Contains(t, body, `const docPath = "/openapi.json";`)
+}
+
+func TestServerCORS(t *testing.T) {
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithCORSConfig(mhttp.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{http.MethodPost},
+		AllowedHeaders:   []string{"Content-Type"},
+		ExposedHeaders:   []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           config.Duration(time.Minute),
+	}))
+	defer cleanup()
+	require.NoError(t, err)
+
+	addr := "http://" + srv.Address() + "/echo.Streams/Call"
+
+	preflight := func(path, origin, method, headers string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, "http://"+srv.Address()+path, nil) //nolint:noctx
+		require.NoError(t, err)
+
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		req.Header.Set("Access-Control-Request-Headers", headers)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		return resp
+	}
+
+	resp := preflight("/echo.Streams/Call", "https://app.example.com", http.MethodPost, "content-type")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, http.MethodPost, resp.Header.Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "Content-Type", resp.Header.Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+	require.Equal(t, "60", resp.Header.Get("Access-Control-Max-Age"))
+
+	resp = preflight("/echo.Streams/Call", "https://api.example.org", http.MethodPost, "")
+	require.Equal(t, "https://api.example.org", resp.Header.Get("Access-Control-Allow-Origin"), "wildcard origin")
+
+	resp = preflight("/echo.Streams/Call", "https://evil.com", http.MethodPost, "")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"), "unknown origin")
+
+	resp = preflight("/echo.Streams/Call", "https://app.example.com", http.MethodPost, "X-Custom")
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"), "disallowed header")
+
+	resp = preflight("/unknown", "https://app.example.com", http.MethodPost, "")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "unknown route")
+
+	req, err := http.NewRequest(http.MethodPost, addr, strings.NewReader(`{"name": "Alex"}`)) //nolint:noctx
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", "https://app.example.com")
+
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "X-Request-Id", resp.Header.Get("Access-Control-Expose-Headers"))
+	require.Contains(t, resp.Header.Values("Vary"), "Origin")
+}
+
+func TestServerStream(t *testing.T) {
+	watch := func(req *proto.CallRequest, stream mhttp.ServerStream[proto.CallResponse]) error {
+		for i := range 3 {
+			if err := stream.Send(&proto.CallResponse{Msg: fmt.Sprintf("Hello %s %d", req.GetName(), i)}); err != nil {
+				return err
+			}
+		}
+
+		return orberrors.ErrBadRequest.Wrap(errors.New("done"))
+	}
+
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithHandlers(func(s any) {
+		srv := s.(*mhttp.Server) //nolint:errcheck
+		srv.Router().Post("/echo.Streams/Watch", mhttp.NewServerStreamHandler(srv, watch, proto.HandlerStreams, "Watch"))
+		srv.Router().Handle(http.MethodGet, "/v1/watch/:name", mhttp.NewRESTServerStreamHandler(srv, watch, proto.HandlerStreams, "Watch", ""))
+	}))
+	defer cleanup()
+	require.NoError(t, err)
+
+	do := func(method, path, body, accept string) (*http.Response, string) {
+		req, err := http.NewRequest(method, "http://"+srv.Address()+path, strings.NewReader(body)) //nolint:noctx
+		require.NoError(t, err)
+
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		req.Header.Set("Accept", accept)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, string(data)
+	}
+
+	resp, body := do(http.MethodPost, "/echo.Streams/Watch", `{"name": "Alex"}`, mhttp.EventStreamContentType)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	require.Equal(t, mhttp.EventStreamContentType, resp.Header.Get("Content-Type"))
+
+	events := strings.Split(strings.TrimSpace(body), "\n\n")
+	require.Len(t, events, 4, body)
+	require.Equal(t, `data: {"msg":"Hello Alex 0"}`, events[0])
+	require.True(t, strings.HasPrefix(events[3], "event: error\ndata: "), events[3])
+	require.Contains(t, events[3], `"code":400`)
+
+	resp, body = do(http.MethodGet, "/v1/watch/Bob", "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	require.Equal(t, mhttp.NDJSONContentType, resp.Header.Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	require.Len(t, lines, 4, body)
+
+	for i, line := range lines[:3] {
+		msg := map[string]string{}
+		require.NoError(t, json.Unmarshal([]byte(line), &msg))
+		require.Equal(t, fmt.Sprintf("Hello Bob %d", i), msg["msg"])
+	}
+
+	require.True(t, strings.HasPrefix(lines[3], `{"error":{`), lines[3])
+}
+
+func TestServerWebSocket(t *testing.T) {
+	chat := func(stream mhttp.WebSocketStream[proto.CallRequest, proto.CallResponse]) error {
+		_, outMd := metadata.WithOutgoing(stream.Context())
+		outMd["x-chat"] = "yes"
+
+		for {
+			req, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if req.GetName() == "error" {
+				return orberrors.ErrUnauthorized
+			}
+
+			if err := stream.Send(&proto.CallResponse{Msg: "Hello " + req.GetName()}); err != nil {
+				return err
+			}
+		}
+	}
+
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithWebSocket(), mhttp.WithHandlers(func(s any) {
+		srv := s.(*mhttp.Server) //nolint:errcheck
+		srv.Router().Handle(http.MethodGet, "/echo.Streams/Chat", mhttp.NewWebSocketHandler(srv, chat, proto.HandlerStreams, "Chat"))
+	}))
+	defer cleanup()
+	require.NoError(t, err)
+
+	addr := srv.Address() + "/echo.Streams/Chat"
+
+	resp, err := http.Get("http://" + addr) //nolint:noctx
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+
+	ctx := context.Background()
+
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.Dial(ctx, "ws://"+addr, nil)
+		require.NoError(t, err)
+
+		return conn
+	}
+
+	send := func(conn *websocket.Conn, flag byte, data string) {
+		require.NoError(t, conn.Write(ctx, websocket.MessageBinary, append([]byte{flag}, data...)))
+	}
+
+	recv := func(conn *websocket.Conn) (byte, string) {
+		_, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, data)
+
+		return data[0], string(data[1:])
+	}
+
+	conn := dial()
+	send(conn, mhttp.WebSocketFlagMessage, `{"name": "Alex"}`)
+
+	flag, data := recv(conn)
+	require.Equal(t, mhttp.WebSocketFlagMetadata, flag)
+	require.JSONEq(t, `{"x-chat": "yes"}`, data)
+
+	flag, data = recv(conn)
+	require.Equal(t, mhttp.WebSocketFlagMessage, flag)
+	require.JSONEq(t, `{"msg": "Hello Alex"}`, data)
+
+	send(conn, mhttp.WebSocketFlagCloseSend, "")
+
+	_, _, err = conn.Read(ctx)
+	require.Equal(t, websocket.StatusNormalClosure, websocket.CloseStatus(err))
+
+	conn = dial()
+	send(conn, mhttp.WebSocketFlagMessage, `{"name": "error"}`)
+
+	_, _, err = conn.Read(ctx) // Metadata
+	require.NoError(t, err)
+
+	_, _, err = conn.Read(ctx)
+	require.Equal(t, websocket.StatusCode(mhttp.WebSocketCloseCodeOffset+http.StatusUnauthorized), websocket.CloseStatus(err))
+}
+
+func TestServerGRPCWeb(t *testing.T) {
+	watch := func(req *proto.CallRequest, stream mhttp.ServerStream[proto.CallResponse]) error {
+		for i := range 2 {
+			if err := stream.Send(&proto.CallResponse{Msg: fmt.Sprintf("Hello %s %d", req.GetName(), i)}); err != nil {
+				return err
+			}
+		}
+
+		return orberrors.ErrNotFound
+	}
+
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithHandlers(func(s any) {
+		srv := s.(*mhttp.Server) //nolint:errcheck
+		srv.Router().Post("/echo.Streams/Watch", mhttp.NewServerStreamHandler(srv, watch, proto.HandlerStreams, "Watch"))
+	}))
+	defer cleanup()
+	require.NoError(t, err)
+
+	frame := func(flag byte, data []byte) []byte {
+		return append([]byte{flag, 0, 0, 0, byte(len(data))}, data...)
+	}
+
+	// do sends a single message and returns the frames of the response.
+	do := func(path, contentType string, msg []byte) (*http.Response, [][]byte) {
+		body := frame(0x00, msg)
+		if strings.HasPrefix(contentType, mhttp.GRPCWebTextContentType) {
+			body = []byte(base64.StdEncoding.EncodeToString(body))
+		}
+
+		req, err := http.NewRequest(http.MethodPost, "http://"+srv.Address()+path, bytes.NewReader(body)) //nolint:noctx
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		if strings.HasPrefix(contentType, mhttp.GRPCWebTextContentType) {
+			decoded := []byte{}
+
+			// Each frame is a base64 chunk with its own padding.
+			for len(data) > 0 {
+				end := len(data)
+				if idx := bytes.IndexByte(data, '='); idx >= 0 {
+					end = min((idx/4+1)*4, len(data))
+				}
+
+				chunk, err := base64.StdEncoding.DecodeString(string(data[:end]))
+				require.NoError(t, err)
+
+				decoded = append(decoded, chunk...)
+				data = data[end:]
+			}
+
+			data = decoded
+		}
+
+		frames := [][]byte{}
+
+		for len(data) > 0 {
+			require.GreaterOrEqual(t, len(data), 5)
+			length := int(binary.BigEndian.Uint32(data[1:5]))
+			require.GreaterOrEqual(t, len(data), 5+length)
+
+			frames = append(frames, data[:5+length])
+			data = data[5+length:]
+		}
+
+		return resp, frames
+	}
+
+	in, err := gproto.Marshal(&proto.CallRequest{Name: "Alex"})
+	require.NoError(t, err)
+
+	for _, contentType := range []string{mhttp.GRPCWebContentType, mhttp.GRPCWebTextContentType + "+proto"} {
+		resp, frames := do("/echo.Streams/Call", contentType, in)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, frames, 2, contentType)
+
+		require.Equal(t, byte(0x00), frames[0][0])
+
+		out := &proto.CallResponse{}
+		require.NoError(t, gproto.Unmarshal(frames[0][5:], out))
+		require.Equal(t, "Hello Alex", out.GetMsg())
+
+		require.Equal(t, byte(0x80), frames[1][0])
+		require.Equal(t, "grpc-status: 0\r\n", string(frames[1][5:]))
+	}
+
+	in, err = gproto.Marshal(&proto.CallRequest{Name: "error"})
+	require.NoError(t, err)
+
+	resp, frames := do("/echo.Streams/Call", mhttp.GRPCWebContentType, in)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, frames, 1)
+	require.Contains(t, string(frames[0][5:]), "grpc-status: 13\r\n")
+	require.Contains(t, string(frames[0][5:]), "grpc-message: internal server error: you asked for an error, here you go\r\n")
+
+	resp, frames = do("/echo.Streams/Call", mhttp.GRPCWebContentType, []byte{0xff})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, frames, 1)
+	require.Contains(t, string(frames[0][5:]), "grpc-status: 3\r\n")
+
+	resp, frames = do("/echo.Streams/Watch", mhttp.GRPCWebContentType+"+json", []byte(`{"name": "Bob"}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, mhttp.GRPCWebContentType+"+json", resp.Header.Get("Content-Type"))
+	require.Len(t, frames, 3)
+	require.JSONEq(t, `{"msg": "Hello Bob 0"}`, string(frames[0][5:]))
+	require.JSONEq(t, `{"msg": "Hello Bob 1"}`, string(frames[1][5:]))
+	require.Contains(t, string(frames[2][5:]), "grpc-status: 5\r\n")
+}
+
+func TestServerConnect(t *testing.T) {
+	slow := func(ctx context.Context, _ *proto.CallRequest) (*proto.CallResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	watch := func(req *proto.CallRequest, stream mhttp.ServerStream[proto.CallResponse]) error {
+		_, hasDeadline := stream.Context().Deadline()
+
+		if err := stream.Send(&proto.CallResponse{Msg: fmt.Sprintf("Hello %s %t", req.GetName(), hasDeadline)}); err != nil {
+			return err
+		}
+
+		// Sent in the end-stream message, the headers are out already.
+		_, outMd := metadata.WithOutgoing(stream.Context())
+		outMd["x-trailer"] = "yes"
+
+		return orberrors.ErrNotFound
+	}
+
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithHandlers(func(s any) {
+		srv := s.(*mhttp.Server) //nolint:errcheck
+		srv.Router().Post("/echo.Streams/Slow", mhttp.NewGRPCHandler(srv, slow, proto.HandlerStreams, "Slow"))
+		srv.Router().Post("/echo.Streams/Watch", mhttp.NewServerStreamHandler(srv, watch, proto.HandlerStreams, "Watch"))
+	}))
+	defer cleanup()
+	require.NoError(t, err)
+
+	do := func(path, contentType string, body []byte, hdrs map[string]string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodPost, "http://"+srv.Address()+path, bytes.NewReader(body)) //nolint:noctx
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set(mhttp.ConnectProtocolVersionHeader, "1")
+
+		for k, v := range hdrs {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, data
+	}
+
+	resp, body := do("/echo.Streams/Call", "application/json", []byte(`{"name": "Alex"}`), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	require.JSONEq(t, `{"msg": "Hello Alex"}`, string(body))
+
+	resp, body = do("/echo.Streams/Call", "application/json", []byte(`{"name": "error"}`), nil)
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.JSONEq(t, `{"code": "internal", "message": "internal server error: you asked for an error, here you go"}`, string(body))
+
+	resp, body = do("/echo.Streams/Slow", "application/json", []byte(`{}`), map[string]string{mhttp.ConnectTimeoutHeader: "10"})
+	require.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	require.Contains(t, string(body), `"code":"deadline_exceeded"`)
+
+	resp, body = do("/echo.Streams/Call", "application/json", []byte(`{}`), map[string]string{mhttp.ConnectTimeoutHeader: "soon"})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Contains(t, string(body), `"code":"invalid_argument"`)
+
+	in := []byte(`{"name": "Bob"}`)
+	envelope := append([]byte{0x00, 0, 0, 0, byte(len(in))}, in...)
+
+	resp, body = do("/echo.Streams/Watch", "application/connect+json", envelope, map[string]string{mhttp.ConnectTimeoutHeader: "5000"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/connect+json", resp.Header.Get("Content-Type"))
+
+	envelopes := [][]byte{}
+
+	for len(body) > 0 {
+		require.GreaterOrEqual(t, len(body), 5)
+		length := int(binary.BigEndian.Uint32(body[1:5]))
+		require.GreaterOrEqual(t, len(body), 5+length)
+
+		envelopes = append(envelopes, body[:5+length])
+		body = body[5+length:]
+	}
+
+	require.Len(t, envelopes, 2)
+	require.Equal(t, byte(0x00), envelopes[0][0])
+	require.JSONEq(t, `{"msg": "Hello Bob true"}`, string(envelopes[0][5:]))
+	require.Equal(t, byte(0x02), envelopes[1][0])
+	require.JSONEq(t,
+		`{"error": {"code": "not_found", "message": "not found"}, "metadata": {"x-trailer": ["yes"]}}`,
+		string(envelopes[1][5:]),
+	)
+}
+
+func TestServerLimits(t *testing.T) {
+	slow := func(ctx context.Context, _ *proto.CallRequest) (*proto.CallResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	block := func(_ context.Context, _ *proto.CallRequest) (*proto.CallResponse, error) {
+		close(started)
+		<-release
+
+		return &proto.CallResponse{Msg: "released"}, nil
+	}
+
+	srv, cleanup, err := setupServer(t, false,
+		mhttp.WithInsecure(),
+		mhttp.WithLimits(1024, time.Minute, 0),
+		mhttp.WithRouteLimits("/echo.Streams/C*", 32, 0, 0),
+		mhttp.WithRouteLimits("/echo.Streams/Slow", 0, 20*time.Millisecond, 0),
+		mhttp.WithRouteLimits("/echo.Streams/Block", 0, 0, 1),
+		mhttp.WithHandlers(func(s any) {
+			srv := s.(*mhttp.Server) //nolint:errcheck
+			srv.Router().Post("/echo.Streams/Slow", mhttp.NewGRPCHandler(srv, slow, proto.HandlerStreams, "Slow"))
+			srv.Router().Post("/echo.Streams/Block", mhttp.NewGRPCHandler(srv, block, proto.HandlerStreams, "Block"))
+		}),
+	)
+	defer cleanup()
+	require.NoError(t, err)
+
+	do := func(path string, body io.Reader) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodPost, "http://"+srv.Address()+path, body) //nolint:noctx
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, string(data)
+	}
+
+	resp, body := do("/echo.Streams/Call", strings.NewReader(`{"name": "Alex"}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+
+	// Known and unknown content lengths.
+	big := `{"name": "` + strings.Repeat("a", 64) + `"}`
+
+	resp, body = do("/echo.Streams/Call", strings.NewReader(big))
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode, body)
+
+	resp, body = do("/echo.Streams/Call", io.MultiReader(strings.NewReader(big)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode, body)
+	require.Contains(t, body, "request body too large")
+
+	resp, body = do("/echo.Streams/Slow", strings.NewReader(`{}`))
+	require.Equal(t, http.StatusGatewayTimeout, resp.StatusCode, body)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		resp, body := do("/echo.Streams/Block", strings.NewReader(`{}`))
+		assert.Equal(t, http.StatusOK, resp.StatusCode, body)
+	}()
+
+	<-started
+
+	resp, body = do("/echo.Streams/Block", strings.NewReader(`{}`))
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, body)
+
+	close(release)
+	<-done
+}
+
+type healthComponent struct {
+	name string
+	err  error
+}
+
+func (c *healthComponent) Start(context.Context) error { return nil }
+func (c *healthComponent) Stop(context.Context) error  { return nil }
+func (c *healthComponent) Type() string                { return "kvstore" }
+func (c *healthComponent) String() string              { return c.name }
+
+func (c *healthComponent) Health(context.Context) error { return c.err }
+
+type plainComponent struct{}
+
+func (plainComponent) Start(context.Context) error { return nil }
+func (plainComponent) Stop(context.Context) error  { return nil }
+func (plainComponent) Type() string                { return "event" }
+func (plainComponent) String() string              { return "natsjs" }
+
+func TestServerHealth(t *testing.T) {
+	kv := &healthComponent{name: "redis"}
+
+	components := types.NewComponents()
+	require.NoError(t, components.Add(kv, types.PriorityKVStore))
+	require.NoError(t, components.Add(plainComponent{}, types.PriorityEvent))
+
+	var checkErr error
+
+	srv, cleanup, err := setupServer(t, false,
+		mhttp.WithInsecure(),
+		mhttp.WithHealth(components),
+		mhttp.WithHealthCheck("database", func(context.Context) error { return checkErr }),
+	)
+	defer cleanup()
+	require.NoError(t, err)
+
+	get := func(path string) (int, mhttp.HealthReport) {
+		resp, err := http.Get("http://" + srv.Address() + path) //nolint:noctx
+		require.NoError(t, err)
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		var report mhttp.HealthReport
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+		return resp.StatusCode, report
+	}
+
+	for _, path := range []string{mhttp.DefaultHealthPath, mhttp.DefaultReadinessPath} {
+		status, report := get(path)
+		require.Equal(t, http.StatusOK, status, path)
+		require.Equal(t, mhttp.HealthStatusOK, report.Status)
+		require.Equal(t, mhttp.HealthStatusOK, report.Checks["kvstore/redis"].Status)
+		require.Equal(t, mhttp.HealthStatusUnknown, report.Checks["event/natsjs"].Status)
+		require.Equal(t, mhttp.HealthStatusOK, report.Checks["database"].Status)
+		require.Equal(t, mhttp.HealthStatusOK, report.Checks[server.EntrypointType+"/"+srv.Name()].Status)
+	}
+
+	// Failing components and checks make the service unready, but not dead.
+	kv.err = errors.New("connection refused")
+
+	status, report := get(mhttp.DefaultReadinessPath)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, mhttp.HealthStatusFailing, report.Status)
+	require.Equal(t, "connection refused", report.Checks["kvstore/redis"].Error)
+
+	status, report = get(mhttp.DefaultLivenessPath)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, report.Checks, 1)
+
+	kv.err = nil
+	checkErr = errors.New("migrations pending")
+	srv.AddHealthCheck("cache", func(context.Context) error { return nil })
+
+	status, report = get(mhttp.DefaultHealthPath)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, mhttp.HealthStatusFailing, report.Checks["database"].Status)
+	require.Equal(t, mhttp.HealthStatusOK, report.Checks["cache"].Status)
+}
+
+func TestServerHealthRegistry(t *testing.T) {
+	// Without components the registry of the entrypoint gets checked.
+	srv, cleanup, err := setupServer(t, false, mhttp.WithInsecure(), mhttp.WithHealth(nil))
+	defer cleanup()
+	require.NoError(t, err)
+
+	resp, err := http.Get("http://" + srv.Address() + mhttp.DefaultReadinessPath) //nolint:noctx
+	require.NoError(t, err)
 
-	addr = "https://" + addr
+	defer resp.Body.Close() //nolint:errcheck
 
-	makeRequests(t, addr, thttp.TypeHTTP1)
+	var report mhttp.HealthReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, mhttp.HealthStatusOK, report.Checks["registry/mdns"].Status)
+	require.Equal(t, mhttp.HealthStatusOK, report.Checks[server.EntrypointType+"/"+srv.Name()].Status)
 }
 
-func TestServerGzip(t *testing.T) {
-	srv, cleanup, err := setupServer(t, false, mhttp.WithGzip())
+func TestServerDrain(t *testing.T) {
+	started := make(chan struct{}, 1)
+	slow := func(ctx context.Context, _ *proto.CallRequest) (*proto.CallResponse, error) {
+		started <- struct{}{}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(300 * time.Millisecond):
+			return &proto.CallResponse{Msg: "done"}, nil
+		}
+	}
+
+	srv, cleanup, err := setupServer(t, false,
+		mhttp.WithInsecure(),
+		mhttp.WithHealth(nil),
+		mhttp.WithDrain(100*time.Millisecond, 2*time.Second),
+		mhttp.WithHandlers(func(s any) {
+			srv := s.(*mhttp.Server) //nolint:errcheck
+			srv.Router().Post("/echo.Streams/Slow", mhttp.NewGRPCHandler(srv, slow, proto.HandlerStreams, "Slow"))
+		}),
+	)
 	defer cleanup()
-	if err != nil {
-		require.NoError(t, err)
+	require.NoError(t, err)
+
+	addr := "http://" + srv.Address()
+
+	type result struct {
+		status int
+		body   string
+		err    error
 	}
 
-	addr := "https://" + srv.Address()
+	results := make(chan result, 1)
 
-	makeRequests(t, addr, thttp.TypeHTTP1)
+	go func() {
+		resp, err := http.Post(addr+"/echo.Streams/Slow", "application/json", strings.NewReader(`{}`)) //nolint:noctx
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+
+		defer resp.Body.Close() //nolint:errcheck
+
+		data, err := io.ReadAll(resp.Body)
+		results <- result{status: resp.StatusCode, body: string(data), err: err}
+	}()
+
+	<-started
+
+	stopped := make(chan error, 1)
+
+	go func() { stopped <- srv.Stop(context.Background()) }()
+
+	// The readiness probe fails during the drain delay, the listener is still open.
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(addr + mhttp.DefaultReadinessPath) //nolint:noctx
+		if err != nil {
+			return false
+		}
+
+		_ = resp.Body.Close() //nolint:errcheck
+
+		return resp.StatusCode == http.StatusServiceUnavailable
+	}, time.Second, 5*time.Millisecond)
+
+	// In-flight requests finish.
+	res := <-results
+	require.NoError(t, res.err)
+	require.Equal(t, http.StatusOK, res.status, res.body)
+	require.Contains(t, res.body, "done")
+
+	require.NoError(t, <-stopped)
+
+	_, err = http.Get(addr + mhttp.DefaultLivenessPath) //nolint:noctx
+	require.Error(t, err, "listener should be closed")
 }
 
-func TestServerInvalidContentType(t *testing.T) {
-	srv, cleanup, err := setupServer(t, false)
-	if err != nil {
-		require.NoError(t, err)
+func TestServerDrainTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	canceled := make(chan struct{})
+	block := func(ctx context.Context, _ *proto.CallRequest) (*proto.CallResponse, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		close(canceled)
+
+		return nil, ctx.Err()
 	}
+
+	srv, cleanup, err := setupServer(t, false,
+		mhttp.WithInsecure(),
+		mhttp.WithDrain(0, 100*time.Millisecond),
+		mhttp.WithHandlers(func(s any) {
+			srv := s.(*mhttp.Server) //nolint:errcheck
+			srv.Router().Post("/echo.Streams/Block", mhttp.NewGRPCHandler(srv, block, proto.HandlerStreams, "Block"))
+		}),
+	)
 	defer cleanup()
+	require.NoError(t, err)
 
-	addr := "https://" + srv.Address()
+	go func() {
+		resp, err := http.Post("http://"+srv.Address()+"/echo.Streams/Block", "application/json", strings.NewReader(`{}`)) //nolint:noctx
+		if err == nil {
+			_ = resp.Body.Close() //nolint:errcheck
+		}
+	}()
 
-	require.ErrorContains(
-		t,
-		thttp.TestPostRequestProto(t, addr, "application/abcdef", thttp.TypeHTTP1),
-		"Post request failed",
-		"POST Proto",
-	)
-	require.ErrorContains(
-		t,
-		thttp.TestPostRequestProto(t, addr, "yadayadayada", thttp.TypeHTTP1),
-		"Post request failed",
-		"POST Proto",
-	)
+	<-started
+
+	start := time.Now()
+
+	require.NoError(t, srv.Stop(context.Background()))
+	require.Less(t, time.Since(start), time.Second)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("handler context has not been canceled")
+	}
 }
 
-func TestServerNoTLS(t *testing.T) {
-	_, cleanup, err := setupServer(t, false, mhttp.WithTLS(&tls.Config{}))
-	defer cleanup()
-	require.ErrorContains(
-		t,
-		err,
-		"tls: neither Certificates, GetCertificate, nor GetConfigForClient set in Config",
-		"setting an empty TLS config should return an error",
-	)
+type memoryKVStore struct {
+	kvstore.KVStore
+
+	data map[string][]byte
 }
 
-func TestServerInvalidMessage(t *testing.T) {
-	srv, cleanup, err := setupServer(t, false)
-	defer cleanup()
-	if err != nil {
-		require.NoError(t, err)
+func (m *memoryKVStore) Get(_ context.Context, key, database, table string, _ ...kvstore.GetOption) ([]kvstore.Record, error) {
+	value, ok := m.data[database+"/"+table+"/"+key]
+	if !ok {
+		return nil, kvstore.ErrNotFound
 	}
 
-	thttp.RefreshClients()
+	return []kvstore.Record{{Key: key, Value: value}}, nil
+}
 
-	addr := fmt.Sprintf("https://%s/echo.Streams/Call", srv.Address())
+func (m *memoryKVStore) Set(_ context.Context, key, database, table string, data []byte, _ ...kvstore.SetOption) error {
+	m.data[database+"/"+table+"/"+key] = data
+	return nil
+}
 
-	// Broken json.
-	msg := `{"name": "Alex}`
+func (m *memoryKVStore) Purge(_ context.Context, key, database, table string) error {
+	delete(m.data, database+"/"+table+"/"+key)
+	return nil
+}
 
-	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader([]byte(msg)))
-	if err != nil {
-		t.Fatalf("create POST request failed: %v", err)
-	}
+func TestServerACMECache(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryKVStore{data: make(map[string][]byte)}
+	cache := mhttp.NewKVStoreCache(store, "orb", "acme")
 
-	req.Header.Set("Content-Type", "application/json")
+	_, err := cache.Get(ctx, "example.com")
+	require.ErrorIs(t, err, autocert.ErrCacheMiss)
 
-	resp, err := thttp.HTTP2Client.Do(req)
+	require.NoError(t, cache.Put(ctx, "example.com", []byte("cert")))
+	require.Contains(t, store.data, "orb/acme/example.com")
+
+	data, err := cache.Get(ctx, "example.com")
 	require.NoError(t, err)
-	body, err := io.ReadAll(resp.Body)
+	require.Equal(t, []byte("cert"), data)
+
+	require.NoError(t, cache.Delete(ctx, "example.com"))
+	require.NoError(t, cache.Delete(ctx, "example.com"))
+
+	_, err = cache.Get(ctx, "example.com")
+	require.ErrorIs(t, err, autocert.ErrCacheMiss)
+}
+
+func TestServerACMEConfig(t *testing.T) {
+	_, cleanup, err := setupServer(t, true, mhttp.WithACME("", t.TempDir()))
+	defer cleanup()
+	require.ErrorIs(t, err, mhttp.ErrACMENoDomains)
+
+	challengeAddr := "127.0.0.1:" + freePort(t)
+
+	srv, cleanup, err := setupServer(t, true,
+		mhttp.WithACME("ops@example.com", t.TempDir(), "example.com"),
+		mhttp.WithACMEHTTPChallenge(challengeAddr),
+	)
+	defer cleanup()
 	require.NoError(t, err)
+	require.Equal(t, "https", srv.Transport())
 
-	t.Logf("expected error: %v", string(body))
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, string(body))
+	// Requests other than HTTP-01 challenges get redirected to HTTPS.
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+challengeAddr+"/path", nil) //nolint:noctx
 	require.NoError(t, err)
+	req.Host = "example.com"
 
-	// Close connection
-	_, err = io.Copy(io.Discard, resp.Body)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	require.Equal(t, "https://example.com/path", resp.Header.Get("Location"))
+
+	// Unknown challenge tokens.
+	req.URL.Path = "/.well-known/acme-challenge/unknown"
+
+	resp, err = client.Do(req)
 	require.NoError(t, err)
 	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
 
-func TestServerErrorRPC(t *testing.T) {
-	srv, cleanup, err := setupServer(t, false)
-	defer cleanup()
-	if err != nil {
-		require.NoError(t, err)
+// TestServerACMEPebble gets a certificate from a local pebble server, e.g.:
+//
+//	PEBBLE_VA_ALWAYS_VALID=1 pebble -config test/config/pebble-config.json
+//	PEBBLE_DIRECTORY=https://127.0.0.1:14000/dir PEBBLE_CA=test/certs/pebble.minica.pem go test -run ACMEPebble ./tests/
+func TestServerACMEPebble(t *testing.T) {
+	directory := os.Getenv("PEBBLE_DIRECTORY")
+	if directory == "" {
+		t.Skip("PEBBLE_DIRECTORY not set")
 	}
 
-	thttp.RefreshClients()
+	srv, cleanup, err := setupServer(t, false,
+		mhttp.WithHTTP3(),
+		mhttp.WithACME("ops@example.com", t.TempDir(), "localhost"),
+		mhttp.WithACMEDirectory(directory, os.Getenv("PEBBLE_CA")),
+	)
+	defer cleanup()
+	require.NoError(t, err)
 
-	addr := fmt.Sprintf("https://%s/echo.Streams/Call", srv.Address())
+	conn, err := tls.Dial("tcp", srv.Address(), &tls.Config{
+		ServerName:         "localhost",
+		InsecureSkipVerify: true, //nolint:gosec
+	})
+	require.NoError(t, err)
 
-	msg := `{"name": "error"}`
+	defer conn.Close() //nolint:errcheck
 
-	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader([]byte(msg))) //nolint:noctx
-	if err != nil {
-		t.Fatalf("create POST request failed: %v", err)
-	}
+	certs := conn.ConnectionState().PeerCertificates
+	require.NotEmpty(t, certs)
+	require.Contains(t, certs[0].Issuer.CommonName, "Pebble")
+	require.Equal(t, []string{"localhost"}, certs[0].DNSNames)
+}
 
-	req.Header.Set("Content-Type", "application/json")
+type gaugeMetrics struct {
+	metrics.Metrics
 
-	resp, err := thttp.HTTP2Client.Do(req)
-	require.NoError(t, err)
-	body, err := io.ReadAll(resp.Body)
+	mu     sync.Mutex
+	gauges map[string]float64
+}
+
+func (m *gaugeMetrics) SetPrecisionGaugeWithLabels(key []string, val float64, labels []metrics.Label) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gauges[strings.Join(key, ".")+"{"+labels[0].Value+"}"] = val
+}
+
+func (m *gaugeMetrics) gauge(key string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.gauges[key]
+}
+
+// writeCertificate writes a self signed certificate with commonName that expires in validFor,
+// opts change the template.
+func writeCertificate(
+	t *testing.T,
+	certFile, keyFile, commonName string,
+	validFor time.Duration,
+	opts ...func(*x509.Certificate),
+) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	require.NoError(t, err)
 
-	// Close connection
-	_, err = io.Copy(io.Discard, resp.Body)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		DNSNames:     []string{"localhost"},
+	}
+
+	for _, o := range opts {
+		o(template)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
 	require.NoError(t, err)
-	require.NoError(t, resp.Body.Close())
 
-	require.Equal(t, http.StatusInternalServerError, resp.StatusCode, string(body))
+	keyDER, err := x509.MarshalECPrivateKey(key)
 	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
 }
 
-func TestServerRequestSpecificContentType(t *testing.T) {
-	srv, cleanup, err := setupServer(t, false)
+func TestServerTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	writeCertificate(t, certFile, keyFile, "first", 30*24*time.Hour)
+
+	m := &gaugeMetrics{gauges: make(map[string]float64)}
+
+	srv, cleanup, err := setupServer(t, true,
+		mhttp.WithTLSFiles(certFile, keyFile),
+		mhttp.WithTLSReload(20*time.Millisecond),
+		mhttp.WithTLSReloadMetrics(m),
+	)
 	defer cleanup()
-	if err != nil {
+	require.NoError(t, err)
+
+	commonName := func() string {
+		conn, err := tls.Dial("tcp", srv.Address(), &tls.Config{InsecureSkipVerify: true}) //nolint:gosec
 		require.NoError(t, err)
+
+		defer conn.Close() //nolint:errcheck
+
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
 	}
 
-	thttp.RefreshClients()
+	gauge := "tls.certificate.expiry_days{" + certFile + "}"
 
-	addr := fmt.Sprintf("https://%s/echo.Streams/Call", srv.Address())
+	require.Equal(t, "first", commonName())
+	require.InDelta(t, 30, m.gauge(gauge), 0.1)
 
-	msg := `{"name": "Alex"}`
+	// Rotated certificates get served without a restart.
+	writeCertificate(t, certFile, keyFile, "second", 60*24*time.Hour)
 
-	testCt := func(expectedCt string) {
-		req, err := http.NewRequest(http.MethodPost, addr, bytes.NewReader([]byte(msg))) //nolint:noctx
-		if err != nil {
-			t.Fatalf("create POST request failed: %v", err)
-		}
+	require.Eventually(t, func() bool { return commonName() == "second" }, 5*time.Second, 20*time.Millisecond)
+	require.InDelta(t, 60, m.gauge(gauge), 0.1)
 
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Accept", expectedCt)
+	// Broken files keep the previous certificate.
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, "second", commonName())
 
-		resp, err := thttp.HTTP2Client.Do(req)
-		require.NoError(t, err)
-		body, err := io.ReadAll(resp.Body)
-		require.NoError(t, err)
+	// The certificates are served over HTTP requests too.
+	require.NoError(t, thttp.TestPostRequestJSON(t, "https://"+srv.Address(), thttp.TypeHTTP1))
+}
 
-		// Validate conent type received
-		assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
-		ct, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
-		require.NoError(t, err)
-		assert.Equal(t, expectedCt, ct, string(body))
+func TestServerMTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
 
-		// Close connection
-		_, err = io.Copy(io.Discard, resp.Body)
+	spiffeID, err := url.Parse("spiffe://example.org/ns/default/sa/billing")
+	require.NoError(t, err)
+
+	// The client certificate is its own CA.
+	writeCertificate(t, certFile, keyFile, "billing", time.Hour, func(c *x509.Certificate) {
+		c.IsCA = true
+		c.BasicConstraintsValid = true
+		c.KeyUsage |= x509.KeyUsageCertSign
+		c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		c.DNSNames = []string{"billing.example.org"}
+		c.IPAddresses = nil
+		c.URIs = []*url.URL{spiffeID}
+	})
+
+	clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+
+	peer := make(chan map[string]string, 1)
+	call := func(ctx context.Context, _ *proto.CallRequest) (*proto.CallResponse, error) {
+		md, _ := metadata.Incoming(ctx)
+		peer <- md
+
+		return &proto.CallResponse{Msg: "ok"}, nil
+	}
+
+	srv, cleanup, err := setupServer(t, true,
+		mhttp.WithMTLS(certFile),
+		mhttp.WithHandlers(func(s any) {
+			srv := s.(*mhttp.Server) //nolint:errcheck
+			srv.Router().Post("/echo.Streams/Peer", mhttp.NewGRPCHandler(srv, call, proto.HandlerStreams, "Peer"))
+		}),
+	)
+	defer cleanup()
+	require.NoError(t, err)
+
+	do := func(certs []tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true, Certificates: certs}, //nolint:gosec
+		}}
+		defer client.CloseIdleConnections()
+
+		req, err := http.NewRequest(http.MethodPost, "https://"+srv.Address()+"/echo.Streams/Peer", //nolint:noctx
+			strings.NewReader(`{"name": "Alex"}`))
 		require.NoError(t, err)
-		require.NoError(t, resp.Body.Close())
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Peer-Subject", "CN=admin")
+
+		resp, err := client.Do(req)
+		if err == nil {
+			require.NoError(t, resp.Body.Close())
+		}
+
+		return resp, err
 	}
 
-	testCt("application/proto")
-	testCt("application/x-protobuf")
-	testCt("application/x-proto")
-	testCt("application/x-protobuf")
-	testCt("application/json")
-	testCt("application/x-www-form-urlencoded")
+	// Clients without a certificate get rejected.
+	_, err = do(nil)
+	require.Error(t, err)
+
+	resp, err := do([]tls.Certificate{clientCert})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	md := <-peer
+	require.Equal(t, "CN=billing", md[utls.MetadataPeerSubject], "the header must not override the certificate")
+	require.Equal(t, "billing", md[utls.MetadataPeerCommonName])
+	require.Equal(t, "billing.example.org", md[utls.MetadataPeerDNSNames])
+	require.Equal(t, spiffeID.String(), md[utls.MetadataPeerSPIFFEID])
+}
+
+func TestServerH2CUnix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "h2c.sock")
+
+	// Unix sockets are insecure without WithInsecure.
+	srv, cleanup, err := setupServer(t, true, mhttp.WithNetwork("unix"), mhttp.WithAddress(socket), mhttp.WithAllowH2C())
+	defer cleanup()
+	require.NoError(t, err)
+	require.Equal(t, "unix+h2c", srv.Transport())
 }
 
 func TestServerIntegration(t *testing.T) {
@@ -624,3 +1938,17 @@ func makeRequests(t *testing.T, addr string, reqType thttp.ReqType) {
 	require.NoError(t, thttp.TestPostRequestProto(t, addr, "application/x-protobuf", reqType), addr+": POST Proto")
 	require.NoError(t, thttp.TestPostRequestProto(t, addr, "application/x-protobuf", reqType), addr+": POST Proto")
 }
+
+// freePort returns a port that was free a moment ago.
+func freePort(tb testing.TB) string {
+	tb.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(tb, err)
+	require.NoError(tb, l.Close())
+
+	return port
+}
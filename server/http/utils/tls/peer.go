@@ -0,0 +1,102 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+)
+
+// Incoming metadata keys of the verified client certificate, lists are comma separated.
+const (
+	MetadataPeerSubject    = "peer-subject"
+	MetadataPeerCommonName = "peer-common-name"
+	MetadataPeerDNSNames   = "peer-dns-names"
+	MetadataPeerEmails     = "peer-emails"
+	MetadataPeerIPs        = "peer-ips"
+	MetadataPeerURIs       = "peer-uris"
+	MetadataPeerSPIFFEID   = "peer-spiffe-id"
+)
+
+//nolint:gochecknoglobals
+var peerMetadataKeys = []string{
+	MetadataPeerSubject,
+	MetadataPeerCommonName,
+	MetadataPeerDNSNames,
+	MetadataPeerEmails,
+	MetadataPeerIPs,
+	MetadataPeerURIs,
+	MetadataPeerSPIFFEID,
+}
+
+// PeerIdentity is the identity of a verified client certificate.
+type PeerIdentity struct {
+	Subject        string
+	CommonName     string
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []string
+	URIs           []string
+	// SPIFFEID is the first spiffe:// URI SAN.
+	SPIFFEID string
+}
+
+// PeerIdentityFromState returns the identity of the client certificate if it has been
+// verified, certificates of clients that weren't verified against the client CAs have none.
+func PeerIdentityFromState(state *tls.ConnectionState) (*PeerIdentity, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return peerIdentity(state.VerifiedChains[0][0]), true
+}
+
+func peerIdentity(cert *x509.Certificate) *PeerIdentity {
+	identity := &PeerIdentity{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+	}
+
+	for _, ip := range cert.IPAddresses {
+		identity.IPAddresses = append(identity.IPAddresses, ip.String())
+	}
+
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+
+		if identity.SPIFFEID == "" && uri.Scheme == "spiffe" {
+			identity.SPIFFEID = uri.String()
+		}
+	}
+
+	return identity
+}
+
+// SetPeerMetadata sets the identity of the verified client certificate in md.
+//
+// The keys get removed first, so clients can't set them with headers or metadata.
+func SetPeerMetadata(md map[string]string, state *tls.ConnectionState) {
+	for _, key := range peerMetadataKeys {
+		delete(md, key)
+	}
+
+	identity, ok := PeerIdentityFromState(state)
+	if !ok {
+		return
+	}
+
+	set := func(key, value string) {
+		if value != "" {
+			md[key] = value
+		}
+	}
+
+	set(MetadataPeerSubject, identity.Subject)
+	set(MetadataPeerCommonName, identity.CommonName)
+	set(MetadataPeerDNSNames, strings.Join(identity.DNSNames, ","))
+	set(MetadataPeerEmails, strings.Join(identity.EmailAddresses, ","))
+	set(MetadataPeerIPs, strings.Join(identity.IPAddresses, ","))
+	set(MetadataPeerURIs, strings.Join(identity.URIs, ","))
+	set(MetadataPeerSPIFFEID, identity.SPIFFEID)
+}
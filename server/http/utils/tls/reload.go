@@ -0,0 +1,454 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/metrics"
+	mtls "github.com/go-orb/go-orb/util/tls"
+)
+
+// DefaultReloadInterval is the interval the files get checked for changes in.
+const DefaultReloadInterval = 10 * time.Second
+
+// ExpiryMetric is the gauge with the days until a certificate expires, labeled with its file.
+//
+//nolint:gochecknoglobals
+var ExpiryMetric = []string{"tls", "certificate", "expiry_days"}
+
+// ErrNoCertificates is returned for a reloader without certificates or CAs.
+var ErrNoCertificates = errors.New("tls: no certificate or CA files to watch")
+
+// KeyPairFiles is a certificate and its key.
+type KeyPairFiles struct {
+	CertFile string
+	KeyFile  string
+}
+
+// ReloaderFiles are the files a Reloader watches.
+type ReloaderFiles struct {
+	Certificates  []KeyPairFiles
+	RootCAFiles   []string
+	ClientCAFiles []string
+}
+
+// FilesFromConfig returns the files of a TLS config loaded from a config file.
+func FilesFromConfig(files mtls.ConfigFiles) ReloaderFiles {
+	result := ReloaderFiles{
+		RootCAFiles:   files.RootCAFiles,
+		ClientCAFiles: files.ClientCAFiles,
+	}
+
+	for _, kp := range files.Certificates {
+		result.Certificates = append(result.Certificates, KeyPairFiles{CertFile: kp.CertFile, KeyFile: kp.KeyFile})
+	}
+
+	return result
+}
+
+// Empty returns true if there are no files.
+func (f ReloaderFiles) Empty() bool {
+	return len(f.Certificates) == 0 && len(f.RootCAFiles) == 0 && len(f.ClientCAFiles) == 0
+}
+
+// ReloaderOption configures a Reloader.
+type ReloaderOption func(*Reloader)
+
+// WithReloadInterval sets the interval the files get checked for changes in.
+func WithReloadInterval(interval time.Duration) ReloaderOption {
+	return func(r *Reloader) {
+		if interval > 0 {
+			r.interval = interval
+		}
+	}
+}
+
+// WithReloadLogger logs reloads and their errors.
+func WithReloadLogger(logger log.Logger) ReloaderOption {
+	return func(r *Reloader) {
+		r.logger = &logger
+	}
+}
+
+// WithReloadMetrics reports the days until the certificates expire as ExpiryMetric.
+func WithReloadMetrics(m metrics.Metrics) ReloaderOption {
+	return func(r *Reloader) {
+		r.metrics = m
+	}
+}
+
+// reloadState is one generation of loaded files.
+type reloadState struct {
+	certificates []tls.Certificate
+	rootCAs      *x509.CertPool
+	clientCAs    *x509.CertPool
+}
+
+// Reloader watches certificate, key and CA files and swaps them atomically when
+// they change, TLS configs created from it always use the latest files.
+//
+// Files that fail to load, e.g. while being written, keep the previous generation
+// and get retried on the next check.
+type Reloader struct {
+	files    ReloaderFiles
+	interval time.Duration
+	logger   *log.Logger
+	metrics  metrics.Metrics
+
+	state atomic.Pointer[reloadState]
+
+	// reloadMu serializes reloads, modTimes are the times of the last successful reload.
+	reloadMu sync.Mutex
+	modTimes map[string]time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReloader loads the files, Start watches them for changes.
+func NewReloader(files ReloaderFiles, opts ...ReloaderOption) (*Reloader, error) {
+	if files.Empty() {
+		return nil, ErrNoCertificates
+	}
+
+	r := &Reloader{files: files, interval: DefaultReloadInterval}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Start checks the files for changes until Stop.
+func (r *Reloader) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.watch(ctx, r.done)
+}
+
+// Stop stops checking the files for changes.
+func (r *Reloader) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+
+	r.cancel = nil
+}
+
+func (r *Reloader) watch(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if r.changed() {
+			if err := r.Reload(); err != nil && r.logger != nil {
+				r.logger.Error("Failed to reload the TLS files, keeping the previous ones", "error", err)
+			}
+		}
+
+		r.reportExpiry()
+	}
+}
+
+// changed reports whether a file has been modified since the last successful reload.
+func (r *Reloader) changed() bool {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	for file, modTime := range r.modTimes {
+		if !fileModTime(file).Equal(modTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reload loads all files and swaps them in if they are valid.
+func (r *Reloader) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	state := &reloadState{}
+
+	// The previous times are kept on failure, so changed retries the load.
+	modTimes := make(map[string]time.Time)
+
+	stat := func(file string) {
+		modTimes[file] = fileModTime(file)
+	}
+
+	for _, kp := range r.files.Certificates {
+		// Stat before reading, a change in between gets picked up by the next check.
+		stat(kp.CertFile)
+		stat(kp.KeyFile)
+
+		cert, err := tls.LoadX509KeyPair(kp.CertFile, kp.KeyFile)
+		if err != nil {
+			return fmt.Errorf("tls: load %s: %w", kp.CertFile, err)
+		}
+
+		if cert.Leaf == nil {
+			if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+				return fmt.Errorf("tls: parse %s: %w", kp.CertFile, err)
+			}
+		}
+
+		state.certificates = append(state.certificates, cert)
+	}
+
+	var err error
+
+	if state.rootCAs, err = r.loadPool(r.files.RootCAFiles, stat); err != nil {
+		return err
+	}
+
+	if state.clientCAs, err = r.loadPool(r.files.ClientCAFiles, stat); err != nil {
+		return err
+	}
+
+	r.state.Store(state)
+	r.modTimes = modTimes
+
+	if r.logger != nil {
+		for i, cert := range state.certificates {
+			r.logger.Info("Loaded TLS certificate",
+				"file", r.files.Certificates[i].CertFile,
+				"notAfter", cert.Leaf.NotAfter,
+				"days", daysUntil(cert.Leaf.NotAfter),
+			)
+		}
+	}
+
+	r.reportExpiry()
+
+	return nil
+}
+
+func (r *Reloader) loadPool(files []string, stat func(string)) (*x509.CertPool, error) {
+	if len(files) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	pool := x509.NewCertPool()
+
+	for _, file := range files {
+		stat(file)
+
+		pem, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, fmt.Errorf("tls: load %s: %w", file, err)
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates in %s", file)
+		}
+	}
+
+	return pool, nil
+}
+
+// reportExpiry sets ExpiryMetric for each certificate.
+func (r *Reloader) reportExpiry() {
+	if r.metrics == nil {
+		return
+	}
+
+	for i, cert := range r.state.Load().certificates {
+		r.metrics.SetPrecisionGaugeWithLabels(ExpiryMetric, daysUntil(cert.Leaf.NotAfter), []metrics.Label{
+			{Name: "file", Value: r.files.Certificates[i].CertFile},
+		})
+	}
+}
+
+// NotAfter returns the earliest expiry of the certificates.
+func (r *Reloader) NotAfter() time.Time {
+	var notAfter time.Time
+
+	for _, cert := range r.state.Load().certificates {
+		if notAfter.IsZero() || cert.Leaf.NotAfter.Before(notAfter) {
+			notAfter = cert.Leaf.NotAfter
+		}
+	}
+
+	return notAfter
+}
+
+// ServerConfig returns a copy of base which serves the latest certificates and
+// verifies clients with the latest client CAs.
+func (r *Reloader) ServerConfig(base *tls.Config) *tls.Config {
+	if base == nil {
+		base = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	config := base.Clone()
+
+	next := base.GetConfigForClient
+
+	// The returned config is cloned on each handshake, changes like NextProtos made
+	// by the servers are kept.
+	config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		if next != nil {
+			if c, err := next(hello); c != nil || err != nil {
+				return c, err
+			}
+		}
+
+		state := r.state.Load()
+
+		c := config.Clone()
+		c.GetConfigForClient = nil
+
+		if len(state.certificates) > 0 {
+			c.GetCertificate = nil
+			c.Certificates = state.certificates
+		}
+
+		if state.clientCAs != nil {
+			c.ClientCAs = state.clientCAs
+		}
+
+		return c, nil
+	}
+
+	if len(r.files.Certificates) > 0 {
+		// Used by listeners that don't call GetConfigForClient.
+		config.Certificates = nil
+		config.GetCertificate = r.certificate
+	}
+
+	return config
+}
+
+// ClientConfig returns a copy of base which presents the latest certificate and
+// verifies servers with the latest root CAs.
+func (r *Reloader) ClientConfig(base *tls.Config) *tls.Config {
+	if base == nil {
+		base = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	config := base.Clone()
+
+	if len(r.files.Certificates) > 0 {
+		config.Certificates = nil
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certs := r.state.Load().certificates
+			if len(certs) == 0 {
+				return nil, ErrNoCertificates
+			}
+
+			return &certs[0], nil
+		}
+	}
+
+	if len(r.files.RootCAFiles) > 0 && !base.InsecureSkipVerify {
+		// RootCAs can't change, the chain gets verified against the latest pool instead.
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			if err := verifyServer(cs, r.state.Load().rootCAs, base.ServerName); err != nil {
+				return err
+			}
+
+			if base.VerifyConnection != nil {
+				return base.VerifyConnection(cs)
+			}
+
+			return nil
+		}
+	}
+
+	return config
+}
+
+// certificate returns the latest certificate matching the hello.
+func (r *Reloader) certificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certs := r.state.Load().certificates
+	if len(certs) == 0 {
+		return nil, ErrNoCertificates
+	}
+
+	for i := range certs {
+		if hello.SupportsCertificate(&certs[i]) == nil {
+			return &certs[i], nil
+		}
+	}
+
+	return &certs[0], nil
+}
+
+// verifyServer verifies the chain of the server like crypto/tls does without InsecureSkipVerify.
+func verifyServer(cs tls.ConnectionState, roots *x509.CertPool, serverName string) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: server presented no certificate")
+	}
+
+	if serverName == "" {
+		serverName = cs.ServerName
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+
+	return err
+}
+
+// fileModTime returns the modification time of file, or the zero time if it doesn't exist.
+func fileModTime(file string) time.Time {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+func daysUntil(t time.Time) float64 {
+	return time.Until(t).Hours() / 24 //nolint:mnd
+}
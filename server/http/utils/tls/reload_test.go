@@ -0,0 +1,161 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate writes a self signed certificate for localhost to dir/name.crt and dir/name.key.
+func writeCertificate(t *testing.T, dir, name string, validFor time.Duration) KeyPairFiles {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	files := KeyPairFiles{CertFile: filepath.Join(dir, name+".crt"), KeyFile: filepath.Join(dir, name+".key")}
+
+	require.NoError(t, os.WriteFile(files.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(files.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	return files
+}
+
+// handshake runs a TLS handshake over loopback TCP and returns the errors of both sides.
+func handshake(t *testing.T, server, client *tls.Config) error {
+	t.Helper()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	require.NoError(t, err)
+
+	defer ln.Close() //nolint:errcheck
+
+	deadline := time.Now().Add(5 * time.Second)
+	errChan := make(chan error, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		// Closing on errors unblocks the client.
+		defer conn.Close() //nolint:errcheck
+
+		_ = conn.SetDeadline(deadline) //nolint:errcheck
+
+		errChan <- conn.(*tls.Conn).Handshake() //nolint:forcetypeassert
+	}()
+
+	conn, err := net.DialTimeout("tcp", ln.Addr().String(), time.Until(deadline))
+	require.NoError(t, err)
+
+	_ = conn.SetDeadline(deadline) //nolint:errcheck
+
+	err = tls.Client(conn, client).Handshake()
+
+	// Closing on errors unblocks the server.
+	_ = conn.Close() //nolint:errcheck
+
+	return errors.Join(err, <-errChan)
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+
+	serverFiles := writeCertificate(t, dir, "server", 30*24*time.Hour)
+	clientFiles := writeCertificate(t, dir, "client", 60*24*time.Hour)
+
+	_, err := NewReloader(ReloaderFiles{})
+	require.ErrorIs(t, err, ErrNoCertificates)
+
+	serverReloader, err := NewReloader(ReloaderFiles{
+		Certificates:  []KeyPairFiles{serverFiles},
+		ClientCAFiles: []string{clientFiles.CertFile},
+	})
+	require.NoError(t, err)
+
+	clientReloader, err := NewReloader(ReloaderFiles{
+		Certificates: []KeyPairFiles{clientFiles},
+		RootCAFiles:  []string{serverFiles.CertFile},
+	})
+	require.NoError(t, err)
+
+	require.WithinDuration(t, time.Now().Add(30*24*time.Hour), serverReloader.NotAfter(), time.Minute)
+
+	serverConfig := serverReloader.ServerConfig(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	})
+	clientConfig := clientReloader.ClientConfig(&tls.Config{MinVersion: tls.VersionTLS12, ServerName: "localhost"})
+
+	require.NoError(t, handshake(t, serverConfig, clientConfig))
+
+	// A new server certificate isn't trusted by the client until its root gets reloaded.
+	writeCertificate(t, dir, "server", 90*24*time.Hour)
+	require.NoError(t, serverReloader.Reload())
+	require.WithinDuration(t, time.Now().Add(90*24*time.Hour), serverReloader.NotAfter(), time.Minute)
+	require.Error(t, handshake(t, serverConfig, clientConfig))
+
+	require.NoError(t, clientReloader.Reload())
+	require.NoError(t, handshake(t, serverConfig, clientConfig))
+
+	// A new client certificate isn't trusted by the server until its client CA gets reloaded.
+	writeCertificate(t, dir, "client", 60*24*time.Hour)
+	require.NoError(t, clientReloader.Reload())
+	require.Error(t, handshake(t, serverConfig, clientConfig))
+
+	require.NoError(t, serverReloader.Reload())
+	require.NoError(t, handshake(t, serverConfig, clientConfig))
+
+	// Broken files keep the previous generation and get retried.
+	require.NoError(t, os.WriteFile(serverFiles.KeyFile, []byte("invalid"), 0o600))
+	require.True(t, serverReloader.changed())
+	require.Error(t, serverReloader.Reload())
+	require.True(t, serverReloader.changed())
+	require.NoError(t, handshake(t, serverConfig, clientConfig))
+
+	writeCertificate(t, dir, "server", 90*24*time.Hour)
+	require.NoError(t, serverReloader.Reload())
+	require.False(t, serverReloader.changed())
+}
+
+func TestReloaderClientConfigNoCertificates(t *testing.T) {
+	r := &Reloader{files: ReloaderFiles{Certificates: []KeyPairFiles{{CertFile: "client.crt", KeyFile: "client.key"}}}}
+	r.state.Store(&reloadState{})
+
+	_, err := r.ClientConfig(nil).GetClientCertificate(&tls.CertificateRequestInfo{})
+	require.ErrorIs(t, err, ErrNoCertificates)
+}
@@ -0,0 +1,357 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/coder/websocket"
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/util/orberrors"
+
+	"github.com/go-orb/plugins/server/http/headers"
+	"github.com/go-orb/plugins/server/http/utils/header"
+	"github.com/go-orb/plugins/server/http/wire"
+)
+
+// WebSocket frames are binary, their first byte is one of these flags.
+const (
+	// WebSocketFlagMessage is followed by a message encoded with the codec of the Content-Type header.
+	WebSocketFlagMessage = wire.WebSocketFlagMessage
+	// WebSocketFlagMetadata is followed by the outgoing metadata of the server as JSON object,
+	// it's sent before the first message or before closing the connection.
+	WebSocketFlagMetadata = wire.WebSocketFlagMetadata
+	// WebSocketFlagCloseSend is sent by the client after its last message.
+	WebSocketFlagCloseSend = wire.WebSocketFlagCloseSend
+)
+
+// WebSocketCloseCodeOffset gets added to the HTTP status of an error to build the close code,
+// the close reason is the error message.
+const WebSocketCloseCodeOffset = wire.WebSocketCloseCodeOffset
+
+// maxCloseReason is the maximum length of a close reason, RFC 6455 section 5.5.
+const maxCloseReason = 123
+
+// Errors.
+var (
+	ErrWebSocketDisabled    = orberrors.ErrNotImplemented.WrapNew("websocket is disabled")
+	ErrWebSocketRequired    = orberrors.New(http.StatusUpgradeRequired, "stream endpoints need a websocket upgrade")
+	ErrWebSocketOrigin      = orberrors.New(http.StatusForbidden, "origin not allowed")
+	ErrWebSocketFrame       = orberrors.ErrBadRequest.WrapNew("invalid websocket frame")
+	ErrWebSocketContentType = orberrors.New(http.StatusUnsupportedMediaType, ErrContentTypeNotSupported.Error())
+)
+
+// WebSocketStream is the stream of a client- or bidirectional-streaming RPC served
+// over a WebSocket, it implements the stream interfaces generated by protoc-gen-go-orb.
+type WebSocketStream[Tin any, Tout any] interface {
+	Send(msg *Tout) error
+	Recv() (*Tin, error)
+	Context() context.Context
+	Close() error
+	CloseSend(msg *Tout) error
+}
+
+// NewWebSocketHandler will wrap a client- or bidirectional-streaming gRPC function
+// with a HTTP handler, which upgrades the request to a WebSocket.
+//
+// Recv returns io.EOF after the client sent WebSocketFlagCloseSend. When fHandler
+// returns the connection gets closed, with an error close code if it failed.
+func NewWebSocketHandler[Tin any, Tout any](
+	srv *Server,
+	fHandler func(WebSocketStream[Tin, Tout]) error,
+	service string,
+	method string,
+) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		stream, ok := acceptWebSocket[Tin, Tout](srv, resp, req, service, method)
+		if !ok {
+			return
+		}
+
+		stream.finish(srv, fHandler(stream))
+	}
+}
+
+// serveWebSocketServerStream serves a server-streaming RPC over a WebSocket,
+// the request is the first message of the client.
+func serveWebSocketServerStream[Tin any, Tout any](
+	srv *Server,
+	resp http.ResponseWriter,
+	req *http.Request,
+	fHandler func(*Tin, ServerStream[Tout]) error,
+	service string,
+	method string,
+) {
+	stream, ok := acceptWebSocket[Tin, Tout](srv, resp, req, service, method)
+	if !ok {
+		return
+	}
+
+	in, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		err = ErrWebSocketFrame.WrapNew("no request message")
+	}
+
+	if err != nil {
+		stream.finish(srv, err)
+		return
+	}
+
+	stream.finish(srv, fHandler(in, stream))
+}
+
+// isWebSocketUpgrade reports whether the request asks for a WebSocket.
+func isWebSocketUpgrade(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		strings.EqualFold(req.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(req.Header.Get("Connection")), "upgrade")
+}
+
+// acceptWebSocket upgrades the request, it writes an error response if that fails.
+func acceptWebSocket[Tin any, Tout any](
+	srv *Server,
+	resp http.ResponseWriter,
+	req *http.Request,
+	service string,
+	method string,
+) (*webSocketStream[Tin, Tout], bool) {
+	if !srv.config.WebSocket.Enabled {
+		WriteError(resp, req, ErrWebSocketDisabled)
+		return nil, false
+	}
+
+	if !isWebSocketUpgrade(req) {
+		resp.Header().Set("Upgrade", "websocket")
+		WriteError(resp, req, ErrWebSocketRequired)
+
+		return nil, false
+	}
+
+	if !srv.webSocketOriginAllowed(req) {
+		WriteError(resp, req, ErrWebSocketOrigin)
+		return nil, false
+	}
+
+	contentType, err := header.GetContentType(req.Header.Get(headers.ContentType))
+	if err != nil || contentType == "" {
+		contentType = headers.JSONContentType
+	}
+
+	codec, err := codecs.GetMime(contentType)
+	if err != nil {
+		WriteError(resp, req, ErrWebSocketContentType)
+		return nil, false
+	}
+
+	// Connections outlive the read and write timeouts of the server.
+	rc := http.NewResponseController(resp)
+	_ = rc.SetReadDeadline(time.Time{})  //nolint:errcheck
+	_ = rc.SetWriteDeadline(time.Time{}) //nolint:errcheck
+
+	// Origins have been checked already.
+	conn, err := websocket.Accept(resp, req, &websocket.AcceptOptions{InsecureSkipVerify: true})
+	if err != nil {
+		srv.logger.Error("failed to accept the websocket", "error", err)
+		return nil, false
+	}
+
+	conn.SetReadLimit(maxMessageSize(req.Context(), srv.config.WebSocket.MaxMessageSize))
+
+	ctx, outMd := incomingContext(req, service, method)
+
+	return &webSocketStream[Tin, Tout]{
+		ctx:   ctx,
+		conn:  conn,
+		codec: codec,
+		outMd: outMd,
+	}, true
+}
+
+// webSocketOriginAllowed allows requests without origin, from the same host
+// and from the allowed CORS origins.
+func (s *Server) webSocketOriginAllowed(req *http.Request) bool {
+	origin := req.Header.Get(headerOrigin)
+	if origin == "" {
+		return true
+	}
+
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, req.Host) {
+		return true
+	}
+
+	return s.cors != nil && s.cors.originAllowed(origin)
+}
+
+// webSocketStream sends and receives flagged frames.
+type webSocketStream[Tin any, Tout any] struct {
+	ctx   context.Context
+	conn  *websocket.Conn
+	codec codecs.Marshaler
+	outMd map[string]string
+
+	mu     sync.Mutex
+	mdSent bool
+	closed bool
+
+	recvClosed bool
+}
+
+func (s *webSocketStream[Tin, Tout]) Context() context.Context {
+	return s.ctx
+}
+
+// Send writes a message, the outgoing metadata is sent before the first one.
+func (s *webSocketStream[Tin, Tout]) Send(msg *Tout) error {
+	data, err := s.codec.Marshal(msg)
+	if err != nil {
+		return orberrors.From(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+
+	if err := s.sendMetadata(); err != nil {
+		return err
+	}
+
+	return s.write(WebSocketFlagMessage, data)
+}
+
+// Recv reads the next message, it returns io.EOF after the last one.
+func (s *webSocketStream[Tin, Tout]) Recv() (*Tin, error) {
+	if s.recvClosed {
+		return nil, io.EOF
+	}
+
+	typ, data, err := s.conn.Read(s.ctx)
+	if err != nil {
+		if websocket.CloseStatus(err) == websocket.StatusNormalClosure {
+			s.recvClosed = true
+			return nil, io.EOF
+		}
+
+		return nil, orberrors.From(err)
+	}
+
+	if typ != websocket.MessageBinary || len(data) == 0 {
+		return nil, ErrWebSocketFrame
+	}
+
+	switch data[0] {
+	case WebSocketFlagCloseSend:
+		s.recvClosed = true
+		return nil, io.EOF
+	case WebSocketFlagMessage:
+		msg := new(Tin)
+		if err := s.codec.Unmarshal(data[1:], msg); err != nil {
+			return nil, orberrors.ErrBadRequest.Wrap(err)
+		}
+
+		return msg, nil
+	default:
+		return nil, ErrWebSocketFrame.WrapF("unknown flag %d", data[0])
+	}
+}
+
+// CloseSend sends a last message and closes the stream.
+func (s *webSocketStream[Tin, Tout]) CloseSend(msg *Tout) error {
+	if err := s.Send(msg); err != nil {
+		return err
+	}
+
+	return s.Close()
+}
+
+// Close closes the stream, further sends fail. The connection gets closed when the handler returns.
+func (s *webSocketStream[Tin, Tout]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	return nil
+}
+
+// finish sends the metadata if not done yet and closes the connection with the result of the handler.
+func (s *webSocketStream[Tin, Tout]) finish(srv *Server, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	err = timeoutError(s.ctx, err)
+
+	if mdErr := s.sendMetadata(); mdErr != nil && err == nil {
+		err = mdErr
+	}
+
+	if err == nil {
+		_ = s.conn.Close(websocket.StatusNormalClosure, "") //nolint:errcheck
+		return
+	}
+
+	if s.ctx.Err() == nil {
+		srv.logger.Error("RPC request failed", "error", err)
+	}
+
+	orbe := orberrors.From(err)
+
+	code := WebSocketCloseCodeOffset + orbe.Code
+	if orbe.Code < 100 || orbe.Code > 999 {
+		code = WebSocketCloseCodeOffset + http.StatusInternalServerError
+	}
+
+	_ = s.conn.Close(websocket.StatusCode(code), closeReason(orbe.Error())) //nolint:errcheck
+}
+
+// sendMetadata sends the outgoing metadata once, the caller has to hold the lock.
+func (s *webSocketStream[Tin, Tout]) sendMetadata() error {
+	if s.mdSent || len(s.outMd) == 0 {
+		return nil
+	}
+
+	s.mdSent = true
+
+	data, err := json.Marshal(s.outMd)
+	if err != nil {
+		return orberrors.From(err)
+	}
+
+	return s.write(WebSocketFlagMetadata, data)
+}
+
+func (s *webSocketStream[Tin, Tout]) write(flag byte, data []byte) error {
+	frame := make([]byte, 0, len(data)+1)
+	frame = append(frame, flag)
+	frame = append(frame, data...)
+
+	if err := s.conn.Write(s.ctx, websocket.MessageBinary, frame); err != nil {
+		return orberrors.From(err)
+	}
+
+	return nil
+}
+
+// closeReason truncates msg to the maximum length of a close reason without splitting a rune.
+func closeReason(msg string) string {
+	if len(msg) <= maxCloseReason {
+		return msg
+	}
+
+	msg = msg[:maxCloseReason]
+	for !utf8.ValidString(msg) {
+		msg = msg[:len(msg)-1]
+	}
+
+	return msg
+}
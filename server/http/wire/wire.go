@@ -0,0 +1,122 @@
+// Package wire contains the wire format of the http server which clients need
+// to decode, it has no dependencies on the server.
+package wire
+
+import (
+	"errors"
+
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// WebSocket frames are binary, their first byte is one of these flags.
+const (
+	// WebSocketFlagMessage is followed by a message encoded with the codec of the Content-Type header.
+	WebSocketFlagMessage byte = 0x00
+	// WebSocketFlagMetadata is followed by the outgoing metadata of the server as JSON object,
+	// it's sent before the first message or before closing the connection.
+	WebSocketFlagMetadata byte = 0x01
+	// WebSocketFlagCloseSend is sent by the client after its last message.
+	WebSocketFlagCloseSend byte = 0x02
+)
+
+// WebSocketCloseCodeOffset gets added to the HTTP status of an error to build the close code,
+// the close reason is the error message.
+const WebSocketCloseCodeOffset = 4000
+
+// ErrorBody is the payload of error responses.
+type ErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Wrapped is the message of the wrapped error.
+	Wrapped string `json:"wrapped,omitempty"`
+	// Details are the orberrors found in the chain of wrapped errors, outermost first.
+	Details []ErrorDetail `json:"details,omitempty"`
+	// RequestID is the X-Request-Id of the response or the request.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// ErrorDetail is a wrapped orberror of an ErrorBody.
+type ErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Wrapped string `json:"wrapped,omitempty"`
+}
+
+// NewErrorBody converts err into an ErrorBody.
+func NewErrorBody(err error, requestID string) *ErrorBody {
+	orbe := orberrors.From(err)
+
+	body := &ErrorBody{Code: orbe.Code, Message: orbe.Message, RequestID: requestID}
+	if orbe.Wrapped == nil {
+		return body
+	}
+
+	body.Wrapped = orbe.Wrapped.Error()
+
+	next := orbe.Wrapped
+	for next != nil {
+		var inner *orberrors.Error
+		if !errors.As(next, &inner) {
+			break
+		}
+
+		detail := ErrorDetail{Code: inner.Code, Message: inner.Message}
+		if inner.Wrapped != nil {
+			detail.Wrapped = inner.Wrapped.Error()
+		}
+
+		body.Details = append(body.Details, detail)
+		next = inner.Wrapped
+	}
+
+	return body
+}
+
+// Err converts the body back into an orberror, its message and the
+// orberrors it wraps are the same as on the server.
+func (b *ErrorBody) Err() *orberrors.Error {
+	var next error
+
+	for i := len(b.Details) - 1; i >= 0; i-- {
+		detail := b.Details[i]
+
+		inner := orberrors.New(detail.Code, detail.Message)
+		inner.Wrapped = remoteWrapped(detail.Wrapped, next)
+		next = inner
+	}
+
+	orbe := orberrors.New(b.Code, b.Message)
+	orbe.Wrapped = remoteWrapped(b.Wrapped, next)
+
+	return orbe
+}
+
+// remoteWrapped returns the wrapped error of a decoded orberror, next is the
+// orberror of the detail below, it gets wrapped if the message differs.
+func remoteWrapped(msg string, next error) error {
+	switch {
+	case next != nil && next.Error() == msg:
+		return next
+	case next != nil:
+		return &remoteError{msg: msg, next: next}
+	case msg != "":
+		return errors.New(msg)
+	default:
+		return nil
+	}
+}
+
+// remoteError keeps the message of a wrapped error from the server
+// and the orberror it wraps.
+type remoteError struct {
+	msg  string
+	next error
+}
+
+func (e *remoteError) Error() string {
+	return e.msg
+}
+
+func (e *remoteError) Unwrap() error {
+	return e.next
+}
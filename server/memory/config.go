@@ -1,6 +1,9 @@
 package memory
 
 import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
 	"github.com/go-orb/go-orb/log"
 	"github.com/go-orb/go-orb/server"
 )
@@ -17,6 +20,25 @@ type Config struct {
 	// MaxConcurrentStreams is the worker pool size.
 	MaxConcurrentStreams int `json:"maxConcurrentStreams" yaml:"maxConcurrentStreams"`
 
+	// Region of this entrypoint, clients use it for locality-aware routing.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Zone of this entrypoint, clients use it for locality-aware routing.
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+
+	// DrainDelay is the time between failing the health and unregistering the
+	// server from the client package on Stop. Defaults to 0.
+	DrainDelay config.Duration `json:"drainDelay,omitempty" yaml:"drainDelay,omitempty"`
+
+	// DrainTimeout is the maximum time Stop waits for in-flight requests after
+	// unregistering, the remaining requests get canceled afterwards.
+	// Defaults to 0, which waits until the stop context is done.
+	DrainTimeout config.Duration `json:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty"`
+
+	// Middlewares are the middlewares of this entrypoint, they get created from
+	// server.Middlewares and get appended to the global server middlewares.
+	Middlewares []server.MiddlewareConfig `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+
 	// Logger allows you to dynamically change the log level and plugin for a
 	// specific entrypoint.
 	Logger log.Config `json:"logger" yaml:"logger"`
@@ -78,3 +100,35 @@ func WithLogPlugin(plugin string) server.Option {
 		}
 	}
 }
+
+// WithRegion sets the region this entrypoint advertises to clients.
+func WithRegion(region string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Region = region
+		}
+	}
+}
+
+// WithZone sets the zone this entrypoint advertises to clients.
+func WithZone(zone string) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Zone = zone
+		}
+	}
+}
+
+// WithDrain sets the delay before the server gets unregistered on Stop, and the
+// maximum time to wait for in-flight requests afterwards.
+func WithDrain(delay, timeout time.Duration) server.Option {
+	return func(c server.EntrypointConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.DrainDelay = config.Duration(delay)
+			cfg.DrainTimeout = config.Duration(timeout)
+		}
+	}
+}
@@ -4,6 +4,8 @@ go 1.23.6
 
 require (
 	github.com/go-orb/go-orb v0.3.0
+	github.com/go-orb/plugins/server/middleware/recovery v0.1.0
+	github.com/go-orb/plugins/server/srvutil v0.1.0
 	github.com/zeebo/errs v1.4.0
 	storj.io/drpc v0.0.34
 )
@@ -10,6 +10,8 @@ import (
 
 	"github.com/zeebo/errs"
 
+	"github.com/go-orb/plugins/server/srvutil"
+
 	"storj.io/drpc"
 )
 
@@ -27,21 +29,21 @@ func (m *Mux) HandleRPC(stream drpc.Stream, rpc string) (err error) {
 		return drpc.ProtocolError.New("unknown rpc: %q", rpc)
 	}
 
-	req := any(stream)
-
-	if data.in1 != streamType {
-		msg, ok := reflect.New(data.in1.Elem()).Interface().(drpc.Message)
-		if !ok {
-			return drpc.InternalError.New("invalid rpc input type")
-		}
+	if data.in1 == streamType || data.in2 == streamType {
+		return m.handleStream(stream, data)
+	}
 
-		if err := stream.MsgRecv(msg, data.enc); err != nil {
-			return errs.Wrap(err)
-		}
+	msg, ok := reflect.New(data.in1.Elem()).Interface().(drpc.Message)
+	if !ok {
+		return drpc.InternalError.New("invalid rpc input type")
+	}
 
-		req = msg
+	if err := stream.MsgRecv(msg, data.enc); err != nil {
+		return errs.Wrap(err)
 	}
 
+	req := any(msg)
+
 	ctx := stream.Context()
 
 	stream = &streamWrapper{Stream: stream, ctx: ctx}
@@ -55,6 +57,8 @@ func (m *Mux) HandleRPC(stream drpc.Stream, rpc string) (err error) {
 		h = m.Call(h)
 	}
 
+	h = m.orbSrv.recovery.Call(h)
+
 	// Calls all middlewares until the actual call.
 	out, err := h(ctx, req)
 
@@ -69,3 +73,77 @@ func (m *Mux) HandleRPC(stream drpc.Stream, rpc string) (err error) {
 
 	return stream.CloseSend()
 }
+
+// orbStream is the innermost ServerStream of the stream middlewares.
+type orbStream struct {
+	*streamWrapper
+
+	enc drpc.Encoding
+}
+
+func (s *orbStream) SendMsg(msg any) error {
+	return s.MsgSend(msg, s.enc)
+}
+
+func (s *orbStream) RecvMsg(msg any) error {
+	return s.MsgRecv(msg, s.enc)
+}
+
+// middlewareStream is the drpc.Stream for the RPC, it passes the messages
+// through the stream middlewares.
+type middlewareStream struct {
+	drpc.Stream
+
+	stream ServerStream
+	ctx    context.Context
+}
+
+func (s *middlewareStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *middlewareStream) MsgSend(msg drpc.Message, _ drpc.Encoding) error {
+	return s.stream.SendMsg(msg)
+}
+
+func (s *middlewareStream) MsgRecv(msg drpc.Message, _ drpc.Encoding) error {
+	return s.stream.RecvMsg(msg)
+}
+
+// handleStream handles a streaming RPC, it runs the stream middlewares.
+func (m *Mux) handleStream(stream drpc.Stream, data rpcData) error {
+	wrapper := &streamWrapper{Stream: stream, ctx: stream.Context()}
+
+	h := func(ctx context.Context, ss ServerStream) error {
+		mStream := &middlewareStream{Stream: wrapper, stream: ss, ctx: ctx}
+
+		var in1 any = mStream
+
+		// Unitary input, stream output.
+		if data.in1 != streamType {
+			msg := reflect.New(data.in1.Elem()).Interface()
+			if err := ss.RecvMsg(msg); err != nil {
+				return errs.Wrap(err)
+			}
+
+			in1 = msg
+		}
+
+		out, err := data.receiver(data.srv, ctx, in1, mStream)
+		if err != nil {
+			return err
+		}
+
+		if out != nil {
+			return ss.SendMsg(out)
+		}
+
+		return nil
+	}
+
+	if err := m.orbSrv.recovery.Stream(srvutil.ChainStream(m.orbSrv.middlewares, h))(wrapper.ctx, &orbStream{streamWrapper: wrapper, enc: data.enc}); err != nil {
+		return err
+	}
+
+	return stream.CloseSend()
+}
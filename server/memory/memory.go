@@ -5,6 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-orb/go-orb/client"
 	"github.com/go-orb/go-orb/config"
@@ -12,6 +15,9 @@ import (
 	"github.com/go-orb/go-orb/registry"
 	orbserver "github.com/go-orb/go-orb/server"
 	"github.com/go-orb/go-orb/util/metadata"
+
+	"github.com/go-orb/plugins/server/middleware/recovery"
+	"github.com/go-orb/plugins/server/srvutil"
 )
 
 var _ orbserver.Entrypoint = (*Server)(nil)
@@ -39,12 +45,19 @@ type Server struct {
 
 	endpoints []string
 
-	started bool
+	// recovery recovers panics of the handlers and the middlewares.
+	recovery *recovery.Middleware
+
+	// activeRequests are the requests in-flight.
+	activeRequests atomic.Int64
+
+	started  atomic.Bool
+	draining atomic.Bool
 }
 
 // Start registers the memory server with the client package.
 func (s *Server) Start(ctx context.Context) error {
-	if s.started {
+	if s.started.Load() {
 		return nil
 	}
 
@@ -63,32 +76,45 @@ func (s *Server) Start(ctx context.Context) error {
 	// Register the memory server with the client package
 	client.RegisterMemoryServer(s.serviceName, s)
 
-	s.started = true
+	s.started.Store(true)
 
 	return nil
 }
 
-// Stop will unregister the memory server from the client package.
-func (s *Server) Stop(_ context.Context) error {
-	if !s.started {
+// Stop drains the memory server and unregisters it from the client package.
+//
+// It fails the health of the entrypoint, waits DrainDelay, unregisters the server
+// and waits for in-flight requests up to DrainTimeout before canceling them.
+func (s *Server) Stop(ctx context.Context) error {
+	if !s.started.Load() {
 		return nil
 	}
 
 	s.logger.Info("Stopping memory server")
 
+	s.draining.Store(true)
+	defer s.draining.Store(false)
+
+	srvutil.SleepContext(ctx, time.Duration(s.config.DrainDelay))
+
+	// Unregister from the client package, no new requests arrive afterwards.
+	client.UnregisterMemoryServer(s.serviceName)
+
+	drainCtx, cancel := srvutil.DrainContext(ctx, time.Duration(s.config.DrainTimeout))
+	defer cancel()
+
+	if !srvutil.WaitIdle(drainCtx, &s.activeRequests) {
+		s.logger.Warn("Stopped the memory server before all requests finished", "active", s.activeRequests.Load())
+	}
+
 	// Cancel any ongoing operations
 	if s.cancelFunc != nil {
 		s.cancelFunc()
 		s.cancelFunc = nil
 	}
 
-	// Unregister from the client package
-	client.UnregisterMemoryServer(s.serviceName)
-
-	// Clean up resources
-	s.started = false
+	s.started.Store(false)
 
-	// Deregister from registry
 	return nil
 }
 
@@ -123,6 +149,23 @@ func (s *Server) Address() string {
 	return ""
 }
 
+// Region returns the region clients see for this entrypoint.
+func (s *Server) Region() string {
+	return s.config.Region
+}
+
+// Metadata returns the node metadata clients see for this entrypoint, like
+// the registry node metadata of the network entrypoints.
+func (s *Server) Metadata() map[string]string {
+	md := make(map[string]string)
+
+	if s.config.Zone != "" {
+		md[srvutil.MetadataZone] = s.config.Zone
+	}
+
+	return md
+}
+
 // Transport returns the client transport to use: "memory".
 func (s *Server) Transport() string {
 	return "memory"
@@ -133,6 +176,16 @@ func (s *Server) String() string {
 	return s.Type()
 }
 
+// Health returns srvutil.ErrNotStarted while the entrypoint isn't serving and srvutil.ErrDraining while it stops.
+func (s *Server) Health(_ context.Context) error {
+	return srvutil.Health(s.started.Load(), s.draining.Load())
+}
+
+// Panics returns the number of panics recovered by the entrypoint, e.g. for alerting.
+func (s *Server) Panics() uint64 {
+	return s.recovery.Panics()
+}
+
 // Enabled returns if this entrypoint has been enabled in config.
 func (s *Server) Enabled() bool {
 	return true
@@ -155,8 +208,17 @@ func (s *Server) Router() *Mux {
 
 // Request implements the client.MemoryServer interface.
 func (s *Server) Request(ctx context.Context, infos client.RequestInfos, req any, result any, opts *client.CallOptions) error {
-	// Extract the service and method from the request
-	service := infos.Service
+	s.activeRequests.Add(1)
+	defer s.activeRequests.Add(-1)
+
+	// Stopping the server cancels the requests still running after the drain.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := context.AfterFunc(s.ctx, cancel)
+	defer stop()
+
+	// Extract the endpoint from the request
 	endpoint := infos.Endpoint
 
 	// Get a reference to the actual request data, making sure it's not nil
@@ -171,8 +233,12 @@ func (s *Server) Request(ctx context.Context, infos client.RequestInfos, req any
 	ctx, reqMd := metadata.WithIncoming(ctx)
 	ctx, _ = metadata.WithOutgoing(ctx)
 
-	reqMd[metadata.Service] = service
-	reqMd[metadata.Method] = endpoint
+	// Like the network entrypoints, the proto service and the bare method.
+	fmSplit := strings.Split(endpoint, "/")
+	if len(fmSplit) >= 3 {
+		reqMd[metadata.Service] = fmSplit[1]
+		reqMd[metadata.Method] = fmSplit[2]
+	}
 
 	// Add request infos to context
 	ctx = context.WithValue(ctx, client.RequestInfosKey{}, &infos)
@@ -225,6 +291,13 @@ func Provide(
 		return nil, err
 	}
 
+	mws, err := srvutil.Middlewares(cfg.Middlewares, configs, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.OptMiddlewares = append(cfg.OptMiddlewares, mws...)
+
 	return New(serviceName, serviceVersion, epName, cfg, logger, reg)
 }
 
@@ -251,6 +324,7 @@ func New(
 		config:         cfg,
 		logger:         logger,
 		registry:       reg,
+		recovery:       recovery.New(recovery.NewConfig(), logger),
 		handlers:       cfg.OptHandlers,
 		middlewares:    cfg.OptMiddlewares,
 		ctx:            ctx,
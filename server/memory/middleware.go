@@ -0,0 +1,12 @@
+package memory
+
+import "github.com/go-orb/plugins/server/srvutil"
+
+// ServerStream is the stream of a streaming RPC as seen by a StreamMiddleware.
+type ServerStream = srvutil.ServerStream
+
+// StreamHandler handles a streaming RPC and returns its final error.
+type StreamHandler = srvutil.StreamHandler
+
+// StreamMiddleware is a server middleware which also handles streaming RPCs.
+type StreamMiddleware = srvutil.StreamMiddleware
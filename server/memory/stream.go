@@ -5,6 +5,7 @@ import (
 	"io"
 	"maps"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/go-orb/go-orb/client"
@@ -80,16 +81,19 @@ func (s *Server) Stream(
 		return nil, orberrors.ErrBadRequest.WrapNew("server not configured with a mux")
 	}
 
-	// Extract the service and method from the request
-	service := infos.Service
+	// Extract the endpoint from the request
 	endpoint := infos.Endpoint
 
 	// Add metadata to context
 	ctx, reqMd := metadata.WithIncoming(ctx)
 	ctx, _ = metadata.WithOutgoing(ctx)
 
-	reqMd[metadata.Service] = service
-	reqMd[metadata.Method] = endpoint
+	// Like the network entrypoints, the proto service and the bare method.
+	fmSplit := strings.Split(endpoint, "/")
+	if len(fmSplit) >= 3 {
+		reqMd[metadata.Service] = fmSplit[1]
+		reqMd[metadata.Method] = fmSplit[2]
+	}
 
 	// Add request infos to context
 	ctx = context.WithValue(ctx, client.RequestInfosKey{}, &infos)
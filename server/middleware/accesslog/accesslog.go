@@ -0,0 +1,190 @@
+// Package accesslog provides an access log middleware for server.
+//
+// It logs every request with its service, method, duration and status code,
+// successful requests at info level, client errors at warn level and server
+// errors at error level. Streaming RPCs also log the number of messages
+// sent and received.
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+
+	"github.com/go-orb/plugins/server/srvutil"
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Name is the middlewares name.
+const Name = "accesslog"
+
+var _ server.Middleware = (*Middleware)(nil)
+
+// serverStream is the ServerStream of the stream-aware entrypoints.
+type serverStream = interface {
+	Context() context.Context
+	SendMsg(m any) error
+	RecvMsg(m any) error
+}
+
+// streamHandler is the StreamHandler of the stream-aware entrypoints.
+type streamHandler = func(ctx context.Context, stream serverStream) error
+
+// Middleware is the accesslog Middleware for server.
+type Middleware struct {
+	config Config
+	logger log.Logger
+}
+
+// Start the component. E.g. connect to the broker.
+func (m *Middleware) Start(_ context.Context) error { return nil }
+
+// Stop the component. E.g. disconnect from the broker.
+// The context will contain a timeout, and cancelation should be respected.
+func (m *Middleware) Stop(_ context.Context) error { return nil }
+
+// Type returns the component type, e.g. broker.
+func (m *Middleware) Type() string {
+	return "middleware"
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call wraps the original handler or other middlewares.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		md, _ := metadata.Incoming(ctx)
+		if m.skip(srvutil.FullMethod(md)) {
+			return next(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := next(ctx, req)
+
+		m.log(ctx, md, start, err)
+
+		return resp, err
+	}
+}
+
+// Stream wraps the original stream handler or other middlewares.
+func (m *Middleware) Stream(next streamHandler) streamHandler {
+	return func(ctx context.Context, stream serverStream) error {
+		md, _ := metadata.Incoming(ctx)
+		if m.skip(srvutil.FullMethod(md)) {
+			return next(ctx, stream)
+		}
+
+		counted := &countingStream{serverStream: stream}
+
+		start := time.Now()
+		err := next(ctx, counted)
+
+		m.log(ctx, md, start, err, "sent", counted.sent.Load(), "received", counted.received.Load())
+
+		return err
+	}
+}
+
+// log logs the request at a level depending on its error.
+func (m *Middleware) log(ctx context.Context, md map[string]string, start time.Time, err error, args ...any) {
+	code := http.StatusOK
+	level := slog.LevelInfo
+
+	args = append([]any{
+		"service", md[metadata.Service],
+		"method", md[metadata.Method],
+		"duration", time.Since(start),
+	}, args...)
+
+	if err != nil {
+		code = orberrors.From(err).Code
+
+		level = slog.LevelError
+		if code < http.StatusInternalServerError {
+			level = slog.LevelWarn
+		}
+
+		args = append(args, "error", err)
+	}
+
+	m.logger.Log(ctx, level, "Request", append(args, "code", code)...)
+}
+
+// countingStream counts the messages of a stream.
+type countingStream struct {
+	serverStream
+
+	sent     atomic.Uint64
+	received atomic.Uint64
+}
+
+func (s *countingStream) SendMsg(msg any) error {
+	err := s.serverStream.SendMsg(msg)
+	if err == nil {
+		s.sent.Add(1)
+	}
+
+	return err
+}
+
+func (s *countingStream) RecvMsg(msg any) error {
+	err := s.serverStream.RecvMsg(msg)
+	if err == nil {
+		s.received.Add(1)
+	}
+
+	return err
+}
+
+// skip reports whether the method shouldn't be logged.
+func (m *Middleware) skip(method string) bool {
+	return slices.ContainsFunc(m.config.Skip, func(pattern string) bool {
+		ok, err := path.Match(pattern, method)
+		return err == nil && ok
+	})
+}
+
+// Provide will be registered to server.Middlewares, it's a factory for this.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	logger log.Logger,
+) (server.Middleware, error) {
+	sections := append(slices.Clone(configSection), configKey)
+
+	// Configure the logger.
+	logger, err := logger.WithConfig(sections, configData)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := NewConfig()
+
+	if err := config.Parse(sections, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return &Middleware{
+		config: cfg,
+		logger: logger.With("middleware", Name),
+	}, nil
+}
@@ -0,0 +1,116 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/stretchr/testify/require"
+)
+
+func newMiddleware(cfg Config) (*Middleware, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+
+	return &Middleware{
+		config: cfg,
+		logger: log.Logger{Logger: slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))},
+	}, buf
+}
+
+// withMethod sets the service and method of the full method like the entrypoints do.
+func withMethod(fullMethod string) context.Context {
+	ctx, md := metadata.WithIncoming(context.Background())
+
+	parts := strings.Split(fullMethod, "/")
+	md[metadata.Service] = parts[1]
+	md[metadata.Method] = parts[2]
+
+	return ctx
+}
+
+// entry decodes the single log entry in buf.
+func entry(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	e := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &e))
+
+	return e
+}
+
+func TestAccessLogLevels(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		level string
+		code  float64
+	}{
+		{name: "success", level: "INFO", code: 200},
+		{name: "client error", err: orberrors.ErrNotFound, level: "WARN", code: 404},
+		{name: "server error", err: errors.New("boom"), level: "ERROR", code: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, buf := newMiddleware(NewConfig())
+
+			_, err := m.Call(func(_ context.Context, _ any) (any, error) {
+				return nil, tt.err
+			})(withMethod("/echo.Streams/Call"), nil)
+			require.ErrorIs(t, err, tt.err)
+
+			e := entry(t, buf)
+			require.Equal(t, tt.level, e["level"])
+			require.Equal(t, tt.code, e["code"])
+			require.Equal(t, "echo.Streams", e["service"])
+			require.Equal(t, "Call", e["method"])
+		})
+	}
+}
+
+func TestAccessLogSkip(t *testing.T) {
+	m, buf := newMiddleware(Config{Skip: []string{"/grpc.health.v1.Health/*"}})
+
+	h := m.Call(func(_ context.Context, _ any) (any, error) { return nil, nil }) //nolint:nilnil
+
+	_, err := h(withMethod("/grpc.health.v1.Health/Check"), nil)
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+
+	_, err = h(withMethod("/echo.Streams/Call"), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, buf.String())
+}
+
+// fakeStream is a stream which accepts all messages.
+type fakeStream struct{}
+
+func (fakeStream) Context() context.Context { return context.Background() }
+func (fakeStream) SendMsg(_ any) error      { return nil }
+func (fakeStream) RecvMsg(_ any) error      { return nil }
+
+func TestAccessLogStream(t *testing.T) {
+	m, buf := newMiddleware(NewConfig())
+
+	err := m.Stream(func(_ context.Context, stream serverStream) error {
+		for range 3 {
+			if err := stream.RecvMsg(nil); err != nil {
+				return err
+			}
+		}
+
+		return stream.SendMsg(nil)
+	})(withMethod("/echo.Streams/Stream"), fakeStream{})
+	require.NoError(t, err)
+
+	e := entry(t, buf)
+	require.InDelta(t, 1, e["sent"], 0)
+	require.InDelta(t, 3, e["received"], 0)
+}
@@ -0,0 +1,13 @@
+package accesslog
+
+// Config is the accesslog middleware config.
+type Config struct {
+	// Skip contains full methods which don't get logged, it may contain path.Match
+	// patterns, e.g. "/grpc.health.v1.Health/*".
+	Skip []string `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+// NewConfig returns a new config object.
+func NewConfig() Config {
+	return Config{}
+}
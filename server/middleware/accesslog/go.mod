@@ -0,0 +1,19 @@
+module github.com/go-orb/plugins/server/middleware/accesslog
+
+go 1.23.6
+
+require (
+	github.com/go-orb/go-orb v0.4.1
+	github.com/go-orb/plugins/server/srvutil v0.1.0
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	dario.cat/mergo v1.0.1 // indirect
+	github.com/cornelk/hashmap v1.0.8 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/hashicorp/errwrap v1.1.0 // indirect
+	github.com/hashicorp/go-multierror v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,20 @@
+package recovery
+
+//nolint:gochecknoglobals
+var (
+	// DefaultStack logs the stack trace of a panic by default.
+	DefaultStack = true
+)
+
+// Config is the recovery middleware config.
+type Config struct {
+	// Stack logs the stack trace of the recovered panic.
+	Stack bool `json:"stack" yaml:"stack"`
+}
+
+// NewConfig returns a new config object.
+func NewConfig() Config {
+	return Config{
+		Stack: DefaultStack,
+	}
+}
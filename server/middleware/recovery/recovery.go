@@ -0,0 +1,151 @@
+// Package recovery provides a panic recovery middleware for server.
+//
+// It recovers panics of the handlers, logs them and returns
+// orberrors.ErrInternalServerError to the caller.
+// Middlewares wrap the ones configured before them, list recovery last
+// to recover panics of the other middlewares as well.
+//
+// The entrypoints also use it as their outermost handler, see New.
+package recovery
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"slices"
+	"sync/atomic"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Name is the middlewares name.
+const Name = "recovery"
+
+var _ server.Middleware = (*Middleware)(nil)
+
+// serverStream is the ServerStream of the stream-aware entrypoints.
+type serverStream = interface {
+	Context() context.Context
+	SendMsg(m any) error
+	RecvMsg(m any) error
+}
+
+// streamHandler is the StreamHandler of the stream-aware entrypoints.
+type streamHandler = func(ctx context.Context, stream serverStream) error
+
+// Middleware is the recovery Middleware for server.
+type Middleware struct {
+	config Config
+	logger log.Logger
+
+	// panics is the number of recovered panics.
+	panics atomic.Uint64
+}
+
+// New creates a recovery middleware which logs to logger.
+func New(cfg Config, logger log.Logger) *Middleware {
+	return &Middleware{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Start the component. E.g. connect to the broker.
+func (m *Middleware) Start(_ context.Context) error { return nil }
+
+// Stop the component. E.g. disconnect from the broker.
+// The context will contain a timeout, and cancelation should be respected.
+func (m *Middleware) Stop(_ context.Context) error { return nil }
+
+// Type returns the component type, e.g. broker.
+func (m *Middleware) Type() string {
+	return "middleware"
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call wraps the original handler or other middlewares.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				resp, err = nil, m.recovered(ctx, r)
+			}
+		}()
+
+		return next(ctx, req)
+	}
+}
+
+// Stream wraps the original stream handler or other middlewares.
+func (m *Middleware) Stream(next streamHandler) streamHandler {
+	return func(ctx context.Context, stream serverStream) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = m.recovered(ctx, r)
+			}
+		}()
+
+		return next(ctx, stream)
+	}
+}
+
+// Panics returns the number of recovered panics, e.g. for alerting.
+func (m *Middleware) Panics() uint64 {
+	return m.panics.Load()
+}
+
+// recovered logs the recovered panic and returns the error for the caller.
+func (m *Middleware) recovered(ctx context.Context, r any) error {
+	m.panics.Add(1)
+
+	md, _ := metadata.Incoming(ctx)
+
+	args := []any{
+		"panic", r,
+		"service", md[metadata.Service],
+		"method", md[metadata.Method],
+	}
+	if m.config.Stack {
+		args = append(args, "stack", string(debug.Stack()))
+	}
+
+	m.logger.ErrorContext(ctx, "Recovered from a panic", args...)
+
+	return orberrors.ErrInternalServerError
+}
+
+// Provide will be registered to server.Middlewares, it's a factory for this.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	logger log.Logger,
+) (server.Middleware, error) {
+	sections := append(slices.Clone(configSection), configKey)
+
+	// Configure the logger.
+	logger, err := logger.WithConfig(sections, configData)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := NewConfig()
+
+	if err := config.Parse(sections, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg, logger.With("middleware", Name)), nil
+}
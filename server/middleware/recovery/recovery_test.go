@@ -0,0 +1,67 @@
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/stretchr/testify/require"
+)
+
+func newMiddleware(stack bool) (*Middleware, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+
+	return New(Config{Stack: stack}, log.Logger{Logger: slog.New(slog.NewJSONHandler(buf, nil))}), buf
+}
+
+func withMethod(method string) context.Context {
+	ctx, md := metadata.WithIncoming(context.Background())
+	md[metadata.Service] = "echo"
+	md[metadata.Method] = method
+
+	return ctx
+}
+
+func TestRecoveryCall(t *testing.T) {
+	m, buf := newMiddleware(true)
+
+	resp, err := m.Call(func(_ context.Context, _ any) (any, error) {
+		panic("boom")
+	})(withMethod("/echo.Streams/Call"), nil)
+
+	require.Nil(t, resp)
+	require.ErrorIs(t, err, orberrors.ErrInternalServerError)
+	require.Contains(t, buf.String(), `"panic":"boom"`)
+	require.Contains(t, buf.String(), `"method":"/echo.Streams/Call"`)
+	require.Contains(t, buf.String(), `"stack":`)
+	require.Equal(t, uint64(1), m.Panics())
+}
+
+func TestRecoveryStream(t *testing.T) {
+	m, buf := newMiddleware(false)
+
+	err := m.Stream(func(_ context.Context, _ serverStream) error {
+		panic("boom")
+	})(withMethod("/echo.Streams/Stream"), nil)
+
+	require.ErrorIs(t, err, orberrors.ErrInternalServerError)
+	require.Contains(t, buf.String(), `"panic":"boom"`)
+	require.NotContains(t, buf.String(), `"stack":`)
+}
+
+func TestRecoveryPassThrough(t *testing.T) {
+	m, buf := newMiddleware(true)
+
+	resp, err := m.Call(func(_ context.Context, _ any) (any, error) {
+		return "ok", orberrors.ErrNotFound
+	})(withMethod("/echo.Streams/Call"), nil)
+
+	require.Equal(t, "ok", resp)
+	require.ErrorIs(t, err, orberrors.ErrNotFound)
+	require.Empty(t, buf.String())
+	require.Zero(t, m.Panics())
+}
@@ -0,0 +1,20 @@
+package requestid
+
+//nolint:gochecknoglobals
+var (
+	// DefaultHeader is the default metadata key of the request id.
+	DefaultHeader = "x-request-id"
+)
+
+// Config is the requestid middleware config.
+type Config struct {
+	// Header is the metadata key of the request id, it has to be lowercase.
+	Header string `json:"header" yaml:"header"`
+}
+
+// NewConfig returns a new config object.
+func NewConfig() Config {
+	return Config{
+		Header: DefaultHeader,
+	}
+}
@@ -0,0 +1,130 @@
+// Package requestid provides a request id middleware for server.
+//
+// It takes the request id from the incoming metadata or generates a new one,
+// stores it in the incoming metadata for the handler and sends it back
+// with the outgoing metadata.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Name is the middlewares name.
+const Name = "requestid"
+
+var _ server.Middleware = (*Middleware)(nil)
+
+// requestIDKey contains the request id in the context.
+type requestIDKey struct{}
+
+// FromContext returns the request id of the request.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// serverStream is the ServerStream of the stream-aware entrypoints.
+type serverStream = interface {
+	Context() context.Context
+	SendMsg(m any) error
+	RecvMsg(m any) error
+}
+
+// streamHandler is the StreamHandler of the stream-aware entrypoints.
+type streamHandler = func(ctx context.Context, stream serverStream) error
+
+// Middleware is the requestid Middleware for server.
+type Middleware struct {
+	config Config
+}
+
+// Start the component. E.g. connect to the broker.
+func (m *Middleware) Start(_ context.Context) error { return nil }
+
+// Stop the component. E.g. disconnect from the broker.
+// The context will contain a timeout, and cancelation should be respected.
+func (m *Middleware) Stop(_ context.Context) error { return nil }
+
+// Type returns the component type, e.g. broker.
+func (m *Middleware) Type() string {
+	return "middleware"
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call wraps the original handler or other middlewares.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		return next(m.withID(ctx), req)
+	}
+}
+
+// Stream wraps the original stream handler or other middlewares.
+func (m *Middleware) Stream(next streamHandler) streamHandler {
+	return func(ctx context.Context, stream serverStream) error {
+		return next(m.withID(ctx), stream)
+	}
+}
+
+// withID takes the request id from the incoming metadata or generates a new one
+// and sets it in the incoming and outgoing metadata.
+func (m *Middleware) withID(ctx context.Context) context.Context {
+	ctx, reqMd := metadata.WithIncoming(ctx)
+
+	id := reqMd[m.config.Header]
+	if id == "" {
+		id = newID()
+		reqMd[m.config.Header] = id
+	}
+
+	ctx, outMd := metadata.WithOutgoing(ctx)
+	outMd[m.config.Header] = id
+
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// newID returns a random 128 bit id.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) //nolint:errcheck
+
+	return hex.EncodeToString(b)
+}
+
+// Provide will be registered to server.Middlewares, it's a factory for this.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	_ log.Logger,
+) (server.Middleware, error) {
+	cfg := NewConfig()
+
+	sections := append(slices.Clone(configSection), configKey)
+	if err := config.Parse(sections, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	cfg.Header = strings.ToLower(cfg.Header)
+
+	return &Middleware{
+		config: cfg,
+	}, nil
+}
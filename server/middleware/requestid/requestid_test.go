@@ -0,0 +1,69 @@
+package requestid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/stretchr/testify/require"
+)
+
+// ids returns the request ids the handler sees.
+func ids(t *testing.T, m *Middleware, ctx context.Context) (string, string, string) {
+	t.Helper()
+
+	var fromCtx, incoming, outgoing string
+
+	_, err := m.Call(func(ctx context.Context, _ any) (any, error) {
+		fromCtx, _ = FromContext(ctx)
+
+		inMd, _ := metadata.Incoming(ctx)
+		incoming = inMd[m.config.Header]
+
+		outMd, _ := metadata.Outgoing(ctx)
+		outgoing = outMd[m.config.Header]
+
+		return nil, nil //nolint:nilnil
+	})(ctx, nil)
+	require.NoError(t, err)
+
+	return fromCtx, incoming, outgoing
+}
+
+func TestRequestIDPropagate(t *testing.T) {
+	m := &Middleware{config: NewConfig()}
+
+	ctx, md := metadata.WithIncoming(context.Background())
+	md[DefaultHeader] = "abc"
+
+	fromCtx, incoming, outgoing := ids(t, m, ctx)
+	require.Equal(t, "abc", fromCtx)
+	require.Equal(t, "abc", incoming)
+	require.Equal(t, "abc", outgoing)
+}
+
+func TestRequestIDGenerate(t *testing.T) {
+	m := &Middleware{config: Config{Header: "x-trace"}}
+
+	fromCtx, incoming, outgoing := ids(t, m, context.Background())
+	require.Len(t, fromCtx, 32)
+	require.Equal(t, fromCtx, incoming)
+	require.Equal(t, fromCtx, outgoing)
+
+	other, _, _ := ids(t, m, context.Background())
+	require.NotEqual(t, fromCtx, other)
+}
+
+func TestRequestIDStream(t *testing.T) {
+	m := &Middleware{config: NewConfig()}
+
+	var id string
+
+	err := m.Stream(func(ctx context.Context, _ serverStream) error {
+		id, _ = FromContext(ctx)
+		return nil
+	})(context.Background(), nil)
+
+	require.NoError(t, err)
+	require.Len(t, id, 32)
+}
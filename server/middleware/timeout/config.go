@@ -0,0 +1,30 @@
+package timeout
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultTimeout is the default timeout of a request.
+	DefaultTimeout = 30 * time.Second
+)
+
+// Config is the timeout middleware config.
+type Config struct {
+	// Timeout of a request, 0 disables the timeout.
+	Timeout config.Duration `json:"timeout" yaml:"timeout"`
+
+	// Methods overwrites the timeout per full method, e.g. "/echo.Streams/Call",
+	// the key may contain path.Match patterns, e.g. "/echo.Streams/*".
+	Methods map[string]config.Duration `json:"methods,omitempty" yaml:"methods,omitempty"`
+}
+
+// NewConfig returns a new config object.
+func NewConfig() Config {
+	return Config{
+		Timeout: config.Duration(DefaultTimeout),
+	}
+}
@@ -0,0 +1,186 @@
+// Package timeout provides a request timeout middleware for server.
+//
+// It sets a deadline on the context of unary requests and returns
+// orberrors.ErrRequestTimeout once it's exceeded, even if the handler
+// doesn't respect the context. Streaming RPCs are long-lived and don't get a timeout.
+package timeout
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+
+	"github.com/go-orb/plugins/server/srvutil"
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Name is the middlewares name.
+const Name = "timeout"
+
+var _ server.Middleware = (*Middleware)(nil)
+
+// Middleware is the timeout Middleware for server.
+type Middleware struct {
+	config Config
+
+	// patterns are the method patterns, the most specific first.
+	patterns []string
+}
+
+// Start the component. E.g. connect to the broker.
+func (m *Middleware) Start(_ context.Context) error { return nil }
+
+// Stop the component. E.g. disconnect from the broker.
+// The context will contain a timeout, and cancelation should be respected.
+func (m *Middleware) Stop(_ context.Context) error { return nil }
+
+// Type returns the component type, e.g. broker.
+func (m *Middleware) Type() string {
+	return "middleware"
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// result is the outcome of the handler.
+type result struct {
+	resp   any
+	err    error
+	panicV any
+}
+
+// Call wraps the original handler or other middlewares.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		md, _ := metadata.Incoming(ctx)
+
+		timeout := m.timeout(srvutil.FullMethod(md))
+		if timeout <= 0 {
+			return next(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		done := make(chan result, 1)
+
+		go func() {
+			var r result
+
+			defer func() {
+				// Hand panics over to the caller, so recovery middlewares get them.
+				r.panicV = recover()
+				done <- r
+			}()
+
+			r.resp, r.err = next(ctx, req)
+		}()
+
+		select {
+		case r := <-done:
+			if r.panicV != nil {
+				panic(r.panicV)
+			}
+
+			if r.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, orberrors.ErrRequestTimeout.Wrap(r.err)
+			}
+
+			return r.resp, r.err
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil, orberrors.ErrCanceled.Wrap(ctx.Err())
+			}
+
+			return nil, orberrors.ErrRequestTimeout.Wrap(ctx.Err())
+		}
+	}
+}
+
+// timeout returns the timeout for the method, exact matches win over patterns
+// and the most specific pattern wins over the others.
+func (m *Middleware) timeout(method string) time.Duration {
+	if d, ok := m.config.Methods[method]; ok {
+		return time.Duration(d)
+	}
+
+	for _, pattern := range m.patterns {
+		if ok, err := path.Match(pattern, method); err == nil && ok {
+			return time.Duration(m.config.Methods[pattern])
+		}
+	}
+
+	return time.Duration(m.config.Timeout)
+}
+
+// sortPatterns returns the method patterns ordered by the number of literal
+// characters, the most specific first. Ties are ordered by the pattern.
+func sortPatterns(methods map[string]config.Duration) []string {
+	patterns := slices.Collect(maps.Keys(methods))
+
+	slices.SortFunc(patterns, func(a, b string) int {
+		if c := cmp.Compare(literals(b), literals(a)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return patterns
+}
+
+// literals counts the characters of pattern which aren't wildcards.
+func literals(pattern string) int {
+	n := 0
+
+	for _, c := range pattern {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+		default:
+			n++
+		}
+	}
+
+	return n
+}
+
+// Provide will be registered to server.Middlewares, it's a factory for this.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	_ log.Logger,
+) (server.Middleware, error) {
+	cfg := NewConfig()
+
+	sections := append(slices.Clone(configSection), configKey)
+	if err := config.Parse(sections, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return newMiddleware(cfg), nil
+}
+
+// newMiddleware creates the middleware from its config.
+func newMiddleware(cfg Config) *Middleware {
+	return &Middleware{
+		config:   cfg,
+		patterns: sortPatterns(cfg.Methods),
+	}
+}
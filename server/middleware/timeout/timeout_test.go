@@ -0,0 +1,124 @@
+package timeout
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+	"github.com/stretchr/testify/require"
+)
+
+// withMethod sets the service and method of the full method like the entrypoints do.
+func withMethod(fullMethod string) context.Context {
+	ctx, md := metadata.WithIncoming(context.Background())
+
+	parts := strings.Split(fullMethod, "/")
+	md[metadata.Service] = parts[1]
+	md[metadata.Method] = parts[2]
+
+	return ctx
+}
+
+func TestTimeoutMethods(t *testing.T) {
+	m := newMiddleware(Config{
+		Timeout: config.Duration(time.Second),
+		Methods: map[string]config.Duration{
+			"/echo.Streams/*":    config.Duration(2 * time.Second),
+			"/echo.Streams/Ca*":  config.Duration(3 * time.Second),
+			"/echo.Streams/Call": config.Duration(4 * time.Second),
+			"/*/Call":            config.Duration(5 * time.Second),
+		},
+	})
+
+	tests := map[string]time.Duration{
+		"/echo.Streams/Call":  4 * time.Second,
+		"/echo.Streams/Calls": 3 * time.Second,
+		"/echo.Streams/Other": 2 * time.Second,
+		"/file.Files/Call":    5 * time.Second,
+		"/file.Files/Get":     time.Second,
+	}
+
+	for method, want := range tests {
+		require.Equal(t, want, m.timeout(method), method)
+	}
+}
+
+func TestTimeoutPatternOrder(t *testing.T) {
+	methods := map[string]config.Duration{
+		"/a/*":  config.Duration(time.Second),
+		"/*/b":  config.Duration(time.Second),
+		"/a/b*": config.Duration(time.Second),
+		"/*":    config.Duration(time.Second),
+	}
+
+	// Map iteration order is random, the pattern order must not be.
+	for range 20 {
+		require.Equal(t, []string{"/a/b*", "/*/b", "/a/*", "/*"}, sortPatterns(methods))
+	}
+}
+
+func TestTimeoutExceeded(t *testing.T) {
+	m := newMiddleware(Config{Timeout: config.Duration(20 * time.Millisecond)})
+
+	// The handler ignores its context.
+	h := m.Call(func(_ context.Context, _ any) (any, error) {
+		time.Sleep(200 * time.Millisecond)
+		return "late", nil
+	})
+
+	start := time.Now()
+	resp, err := h(withMethod("/echo.Streams/Call"), nil)
+
+	require.Nil(t, resp)
+	require.ErrorIs(t, err, orberrors.ErrRequestTimeout)
+	require.Less(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestTimeoutWithinDeadline(t *testing.T) {
+	m := newMiddleware(Config{Timeout: config.Duration(time.Second)})
+
+	h := m.Call(func(ctx context.Context, _ any) (any, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, orberrors.ErrInternalServerError
+		}
+
+		return "ok", nil
+	})
+
+	resp, err := h(withMethod("/echo.Streams/Call"), nil)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+}
+
+func TestTimeoutDisabled(t *testing.T) {
+	m := newMiddleware(Config{})
+
+	h := m.Call(func(ctx context.Context, _ any) (any, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return nil, orberrors.ErrInternalServerError
+		}
+
+		return "ok", nil
+	})
+
+	resp, err := h(withMethod("/echo.Streams/Call"), nil)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	m := newMiddleware(Config{Timeout: config.Duration(time.Second)})
+
+	h := m.Call(func(_ context.Context, _ any) (any, error) {
+		panic("boom")
+	})
+
+	// Panics reach the caller, so recovery middlewares get them.
+	require.PanicsWithValue(t, "boom", func() {
+		_, _ = h(withMethod("/echo.Streams/Call"), nil) //nolint:errcheck
+	})
+}
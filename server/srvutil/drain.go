@@ -0,0 +1,74 @@
+package srvutil
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Errors returned by Health.
+var (
+	// ErrNotStarted is the health of an entrypoint that is not (or no longer) serving.
+	ErrNotStarted = orberrors.ErrUnavailable.WrapNew("entrypoint not started")
+	// ErrDraining is the health of an entrypoint that is shutting down.
+	ErrDraining = orberrors.ErrUnavailable.WrapNew("entrypoint is draining")
+)
+
+// DrainPollInterval is the interval WaitIdle checks the in-flight requests in.
+const DrainPollInterval = 10 * time.Millisecond
+
+// Health returns the health of an entrypoint, ErrNotStarted while it isn't
+// serving and ErrDraining while it stops.
+func Health(started, draining bool) error {
+	switch {
+	case !started:
+		return ErrNotStarted
+	case draining:
+		return ErrDraining
+	}
+
+	return nil
+}
+
+// DrainContext returns the deadline of the in-flight requests, the drain timeout
+// if set and the stop context otherwise.
+func DrainContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// SleepContext waits for d or until the context is done.
+func SleepContext(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// WaitIdle waits until no requests are in-flight, it returns false if the context is done before.
+func WaitIdle(ctx context.Context, active *atomic.Int64) bool {
+	ticker := time.NewTicker(DrainPollInterval)
+	defer ticker.Stop()
+
+	for active.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,31 @@
+package srvutil
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	require.ErrorIs(t, Health(false, false), ErrNotStarted)
+	require.ErrorIs(t, Health(true, true), ErrDraining)
+	require.NoError(t, Health(true, false))
+}
+
+func TestWaitIdle(t *testing.T) {
+	var active atomic.Int64
+
+	active.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*DrainPollInterval)
+	defer cancel()
+
+	require.False(t, WaitIdle(ctx, &active), "the request is still in-flight")
+
+	time.AfterFunc(2*DrainPollInterval, func() { active.Add(-1) })
+
+	require.True(t, WaitIdle(context.Background(), &active))
+}
@@ -0,0 +1,84 @@
+package srvutil
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-orb/go-orb/event"
+	"github.com/go-orb/go-orb/kvstore"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/types"
+)
+
+// HealthCheck is a user-registered check, a nil error means healthy.
+type HealthCheck func(ctx context.Context) error
+
+// HealthChecker is implemented by components that report their state,
+// a nil error means the component is started and healthy.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// ComponentName returns the name of a component in health reports, e.g. "registry/mdns".
+func ComponentName(c types.Component) string {
+	if c.String() == "" {
+		return c.Type()
+	}
+
+	return c.Type() + "/" + c.String()
+}
+
+// ComponentHealth returns the check of a component, false if there is no way to check it.
+//
+// Components implementing HealthChecker check themselves. The registry, kvstore and event
+// components check their plugin if it implements HealthChecker, without one the registry
+// lists the services and the kvstore lists a key.
+func ComponentHealth(c any) (HealthCheck, bool) {
+	switch comp := c.(type) {
+	case HealthChecker:
+		return comp.Health, true
+	case *registry.Type:
+		return ComponentHealth(*comp)
+	case registry.Type:
+		if comp.Registry == nil {
+			return nil, false
+		}
+
+		if checker, ok := comp.Registry.(HealthChecker); ok {
+			return checker.Health, true
+		}
+
+		return func(ctx context.Context) error {
+			_, err := comp.ListServices(ctx, "", "", nil)
+			if errors.Is(err, registry.ErrNotFound) {
+				return nil
+			}
+
+			return err
+		}, true
+	case *kvstore.Type:
+		return ComponentHealth(*comp)
+	case kvstore.Type:
+		if comp.KVStore == nil {
+			return nil, false
+		}
+
+		if checker, ok := comp.KVStore.(HealthChecker); ok {
+			return checker.Health, true
+		}
+
+		return func(ctx context.Context) error {
+			_, err := comp.Keys(ctx, "", "", kvstore.KeysLimit(1))
+			return err
+		}, true
+	case *event.Type:
+		return ComponentHealth(*comp)
+	case event.Type:
+		// Events have no call without side effects, only plugins implementing HealthChecker get checked.
+		if checker, ok := comp.Client.(HealthChecker); ok {
+			return checker.Health, true
+		}
+	}
+
+	return nil, false
+}
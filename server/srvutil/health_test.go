@@ -0,0 +1,89 @@
+package srvutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-orb/go-orb/event"
+	"github.com/go-orb/go-orb/kvstore"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("connection refused")
+
+type fakeRegistry struct {
+	registry.Registry
+
+	err error
+}
+
+func (r *fakeRegistry) ListServices(_ context.Context, _, _ string, _ []string) ([]registry.ServiceNode, error) {
+	return nil, r.err
+}
+
+type fakeKVStore struct {
+	kvstore.KVStore
+
+	err error
+}
+
+func (k *fakeKVStore) Keys(_ context.Context, _, _ string, _ ...kvstore.KeysOption) ([]string, error) {
+	return nil, k.err
+}
+
+type fakeEvent struct {
+	event.Client
+
+	err error
+}
+
+func (e *fakeEvent) Health(_ context.Context) error {
+	return e.err
+}
+
+func TestComponentHealth(t *testing.T) {
+	reg := &fakeRegistry{}
+	kv := &fakeKVStore{}
+	ev := &fakeEvent{}
+
+	for name, c := range map[string]struct {
+		component any
+		set       func(err error)
+	}{
+		"registry":         {registry.Type{Registry: reg}, func(err error) { reg.err = err }},
+		"registry pointer": {&registry.Type{Registry: reg}, func(err error) { reg.err = err }},
+		"kvstore":          {kvstore.Type{KVStore: kv}, func(err error) { kv.err = err }},
+		"event":            {&event.Type{Client: ev}, func(err error) { ev.err = err }},
+		"checker":          {ev, func(err error) { ev.err = err }},
+	} {
+		check, ok := ComponentHealth(c.component)
+		require.True(t, ok, name)
+
+		c.set(nil)
+		require.NoError(t, check(context.Background()), name)
+
+		c.set(errTest)
+		require.ErrorIs(t, check(context.Background()), errTest, name)
+	}
+
+	// An empty registry is healthy.
+	reg.err = registry.ErrNotFound
+	check, _ := ComponentHealth(registry.Type{Registry: reg})
+	require.NoError(t, check(context.Background()))
+}
+
+func TestComponentHealthUnknown(t *testing.T) {
+	for _, c := range []any{
+		registry.Type{},
+		&kvstore.Type{},
+		event.Type{},
+		// Events without a HealthChecker have no call without side effects.
+		event.Type{Client: struct{ event.Client }{}},
+		struct{}{},
+	} {
+		_, ok := ComponentHealth(c)
+		require.False(t, ok, "%T", c)
+	}
+}
@@ -0,0 +1,14 @@
+package srvutil
+
+import "github.com/go-orb/go-orb/util/metadata"
+
+// MetadataZone is the registry node metadata key the entrypoints publish their zone under.
+//
+// The orb client reads the same key for locality-aware routing.
+const MetadataZone = "zone"
+
+// FullMethod returns the full method of a request, e.g. "/echo.Streams/Call",
+// from the service and method all entrypoints set in the incoming metadata.
+func FullMethod(md map[string]string) string {
+	return "/" + md[metadata.Service] + "/" + md[metadata.Method]
+}
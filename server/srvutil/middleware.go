@@ -0,0 +1,37 @@
+package srvutil
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+)
+
+// Middlewares creates the middlewares configured for an entrypoint under its
+// "middlewares" key from the plugins registered in server.Middlewares.
+//
+// The entrypoints append them to the server middlewares.
+func Middlewares(
+	cfgs []server.MiddlewareConfig,
+	configData map[string]any,
+	logger log.Logger,
+) ([]server.Middleware, error) {
+	mws := make([]server.Middleware, 0, len(cfgs))
+
+	for idx, cfgMw := range cfgs {
+		pFunc, ok := server.Middlewares.Get(cfgMw.Plugin)
+		if !ok {
+			return nil, fmt.Errorf("%w: '%s', did you register it?", server.ErrUnknownMiddleware, cfgMw.Plugin)
+		}
+
+		mw, err := pFunc([]string{"middlewares"}, strconv.Itoa(idx), configData, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		mws = append(mws, mw)
+	}
+
+	return mws, nil
+}
@@ -0,0 +1,43 @@
+// Package srvutil provides utility functions for the server entrypoints.
+package srvutil
+
+import (
+	"context"
+
+	"github.com/go-orb/go-orb/server"
+)
+
+// ServerStream is the stream of a streaming RPC as seen by a StreamMiddleware.
+//
+// It's an alias of an interface literal, so middlewares can implement
+// StreamMiddleware for all entrypoints without importing this package.
+type ServerStream = interface {
+	Context() context.Context
+	SendMsg(m any) error
+	RecvMsg(m any) error
+}
+
+// StreamHandler handles a streaming RPC and returns its final error.
+type StreamHandler = func(ctx context.Context, stream ServerStream) error
+
+// StreamMiddleware is a server middleware which also handles streaming RPCs.
+//
+// Streaming RPCs only run the middlewares which implement it, Call
+// is used for unary RPCs only.
+type StreamMiddleware interface {
+	server.Middleware
+
+	Stream(next StreamHandler) StreamHandler
+}
+
+// ChainStream wraps the handler with all middlewares implementing StreamMiddleware,
+// in the same order as Call.
+func ChainStream(mws []server.Middleware, h StreamHandler) StreamHandler {
+	for _, m := range mws {
+		if sm, ok := m.(StreamMiddleware); ok {
+			h = sm.Stream(h)
+		}
+	}
+
+	return h
+}
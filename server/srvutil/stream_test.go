@@ -0,0 +1,56 @@
+package srvutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-orb/go-orb/server"
+	"github.com/stretchr/testify/require"
+)
+
+// callMiddleware only handles unary RPCs.
+type callMiddleware struct {
+	name  string
+	calls *[]string
+}
+
+func (m *callMiddleware) Start(_ context.Context) error { return nil }
+func (m *callMiddleware) Stop(_ context.Context) error  { return nil }
+func (m *callMiddleware) Type() string                  { return "middleware" }
+func (m *callMiddleware) String() string                { return m.name }
+
+func (m *callMiddleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return next
+}
+
+// streamMiddleware records the order it runs in.
+type streamMiddleware struct {
+	callMiddleware
+}
+
+func (m *streamMiddleware) Stream(next StreamHandler) StreamHandler {
+	return func(ctx context.Context, stream ServerStream) error {
+		*m.calls = append(*m.calls, m.name)
+		return next(ctx, stream)
+	}
+}
+
+func TestChainStream(t *testing.T) {
+	calls := []string{}
+
+	mws := []server.Middleware{
+		&streamMiddleware{callMiddleware{name: "first", calls: &calls}},
+		&callMiddleware{name: "unary", calls: &calls},
+		&streamMiddleware{callMiddleware{name: "last", calls: &calls}},
+	}
+
+	h := ChainStream(mws, func(_ context.Context, _ ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	require.NoError(t, h(context.Background(), nil))
+
+	// Like Call, the last middleware is the outermost one.
+	require.Equal(t, []string{"last", "first", "handler"}, calls)
+}